@@ -0,0 +1,84 @@
+package mage
+
+import (
+	"fmt"
+	"github.com/magefile/mage/mg"
+	_ "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DgraphSchemaDir         = "schema"
+	DgraphSchemaFile        = DgraphSchemaDir + "/dgraph.schema"
+	DgraphGraphQLSchemaFile = DgraphSchemaDir + "/dgraph.graphql"
+)
+
+const dgraphSchemaHeader = "# this file is generated by 'mage dgraph:schema'\n# DO NOT EDIT\n\n"
+
+type Dgraph mg.Namespace
+
+// Schema generates the DQL and GraphQL schemas from the compiled protobuf files
+func (Dgraph) Schema() error {
+	s, err := dgraphSchema()
+
+	if err != nil {
+		return err
+	}
+
+	if mErr := os.MkdirAll(DgraphSchemaDir, 0755); mErr != nil {
+		return mErr
+	}
+
+	fmt.Printf("writing generated dgraph schema to: %s\n", DgraphSchemaFile)
+
+	if wErr := os.WriteFile(DgraphSchemaFile, []byte(dgraphSchemaHeader+s.DQL()), 0644); wErr != nil {
+		return wErr
+	}
+
+	fmt.Printf("writing generated graphql schema to: %s\n", DgraphGraphQLSchemaFile)
+
+	return os.WriteFile(DgraphGraphQLSchemaFile, []byte(dgraphSchemaHeader+s.GraphQL()), 0644)
+}
+
+// SchemaDiff shows the drift between the saved DQL schema and the compiled protobuf files
+func (Dgraph) SchemaDiff() error {
+	saved, rErr := os.ReadFile(DgraphSchemaFile)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	from, pErr := schema.ParseDQL(string(saved))
+
+	if pErr != nil {
+		return fmt.Errorf("%s: %w", DgraphSchemaFile, pErr)
+	}
+
+	to, err := dgraphSchema()
+
+	if err != nil {
+		return err
+	}
+
+	changes := schema.Diff(from, to)
+
+	if len(changes) == 0 {
+		fmt.Printf("%s is up to date\n", DgraphSchemaFile)
+		return nil
+	}
+
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+
+	return fmt.Errorf("%s is out of date, %d changes. run 'mage dgraph:schema' to update it", DgraphSchemaFile, len(changes))
+}
+
+func dgraphSchema() (*schema.Schema, error) {
+	fmt.Printf("generating dgraph schema from '%s'\n", filepath.Join(ProtoDir, "..."))
+
+	return schema.FromRegistry(protoregistry.GlobalFiles, ProtoDir+"/")
+}
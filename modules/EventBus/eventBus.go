@@ -1,9 +1,15 @@
 package EventBus
 
 import (
+	"errors"
+
 	"github.com/smoxy-io/goSDK/util/events"
 )
 
+var (
+	ErrNotStarted = errors.New("event bus not started")
+)
+
 // event router for the event bus
 var eventRouter *events.EventRouter
 
@@ -20,15 +26,32 @@ func New() {
 	eventRouter.Start()
 }
 
+// IsStarted returns true when the event bus is created and running
+func IsStarted() bool {
+	return eventRouter != nil && eventRouter.IsRunning()
+}
+
 func Publish(routingKey string, event any) error {
+	if eventRouter == nil {
+		return ErrNotStarted
+	}
+
 	return eventRouter.Publish(events.RoutingKey(routingKey), event)
 }
 
 func Subscribe(topic string) (events.Subscriber, error) {
+	if eventRouter == nil {
+		return nil, ErrNotStarted
+	}
+
 	return eventRouter.Subscribe(events.Topic(topic))
 }
 
 func Unsubscribe(topic string, subscription events.Subscriber) error {
+	if eventRouter == nil {
+		return ErrNotStarted
+	}
+
 	return eventRouter.Unsubscribe(events.Topic(topic), subscription)
 }
 
@@ -37,5 +60,9 @@ func UnwrapEvent[T any](event events.Event) T {
 }
 
 func Stop() {
+	if eventRouter == nil {
+		return
+	}
+
 	eventRouter.Stop()
 }
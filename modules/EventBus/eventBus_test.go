@@ -27,6 +27,44 @@ func TestStop(t *testing.T) {
 	eventRouter = nil
 }
 
+func TestNotStarted(t *testing.T) {
+	eventRouter = nil
+
+	if IsStarted() {
+		t.Errorf("IsStarted() = true, wanted: false")
+	}
+
+	if err := Publish("foo.bar", "test"); err != ErrNotStarted {
+		t.Errorf("Publish('foo.bar', 'test') = '%v', wanted: '%v'", err, ErrNotStarted)
+	}
+
+	if _, err := Subscribe("foo.*"); err != ErrNotStarted {
+		t.Errorf("Subscribe('foo.*') = '%v', wanted: '%v'", err, ErrNotStarted)
+	}
+
+	if err := Unsubscribe("foo.*", nil); err != ErrNotStarted {
+		t.Errorf("Unsubscribe('foo.*') = '%v', wanted: '%v'", err, ErrNotStarted)
+	}
+
+	// stopping a bus that was never started does nothing
+	Stop()
+
+	New()
+
+	if !IsStarted() {
+		t.Errorf("IsStarted() = false, wanted: true")
+	}
+
+	Stop()
+
+	if IsStarted() {
+		t.Errorf("IsStarted() after Stop() = true, wanted: false")
+	}
+
+	// reset
+	eventRouter = nil
+}
+
 func TestPublish(t *testing.T) {
 	// start event bus
 	New()
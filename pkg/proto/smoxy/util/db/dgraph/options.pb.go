@@ -0,0 +1,504 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.28.3
+// source: proto/smoxy/util/db/dgraph/options.proto
+
+package dgraph
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Timestamp the timestamps maintained by the models package
+type DgraphField_Timestamp int32
+
+const (
+	DgraphField_NO_TIMESTAMP DgraphField_Timestamp = 0
+	// CREATED_AT set when the model is created
+	DgraphField_CREATED_AT DgraphField_Timestamp = 1
+	// UPDATED_AT set every time the model is written
+	DgraphField_UPDATED_AT DgraphField_Timestamp = 2
+	// DELETED_AT set when the model is soft deleted
+	DgraphField_DELETED_AT DgraphField_Timestamp = 3
+)
+
+// Enum value maps for DgraphField_Timestamp.
+var (
+	DgraphField_Timestamp_name = map[int32]string{
+		0: "NO_TIMESTAMP",
+		1: "CREATED_AT",
+		2: "UPDATED_AT",
+		3: "DELETED_AT",
+	}
+	DgraphField_Timestamp_value = map[string]int32{
+		"NO_TIMESTAMP": 0,
+		"CREATED_AT":   1,
+		"UPDATED_AT":   2,
+		"DELETED_AT":   3,
+	}
+)
+
+func (x DgraphField_Timestamp) Enum() *DgraphField_Timestamp {
+	p := new(DgraphField_Timestamp)
+	*p = x
+	return p
+}
+
+func (x DgraphField_Timestamp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DgraphField_Timestamp) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_smoxy_util_db_dgraph_options_proto_enumTypes[0].Descriptor()
+}
+
+func (DgraphField_Timestamp) Type() protoreflect.EnumType {
+	return &file_proto_smoxy_util_db_dgraph_options_proto_enumTypes[0]
+}
+
+func (x DgraphField_Timestamp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DgraphField_Timestamp.Descriptor instead.
+func (DgraphField_Timestamp) EnumDescriptor() ([]byte, []int) {
+	return file_proto_smoxy_util_db_dgraph_options_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// OnDelete what happens to the models an edge points to when the model is deleted
+type DgraphField_OnDelete int32
+
+const (
+	// KEEP the edge is removed, the related models are kept
+	DgraphField_KEEP DgraphField_OnDelete = 0
+	// CASCADE the related models are deleted as well
+	DgraphField_CASCADE DgraphField_OnDelete = 1
+	// RESTRICT the model cannot be deleted while the edge points to a model
+	DgraphField_RESTRICT DgraphField_OnDelete = 2
+)
+
+// Enum value maps for DgraphField_OnDelete.
+var (
+	DgraphField_OnDelete_name = map[int32]string{
+		0: "KEEP",
+		1: "CASCADE",
+		2: "RESTRICT",
+	}
+	DgraphField_OnDelete_value = map[string]int32{
+		"KEEP":     0,
+		"CASCADE":  1,
+		"RESTRICT": 2,
+	}
+)
+
+func (x DgraphField_OnDelete) Enum() *DgraphField_OnDelete {
+	p := new(DgraphField_OnDelete)
+	*p = x
+	return p
+}
+
+func (x DgraphField_OnDelete) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DgraphField_OnDelete) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_smoxy_util_db_dgraph_options_proto_enumTypes[1].Descriptor()
+}
+
+func (DgraphField_OnDelete) Type() protoreflect.EnumType {
+	return &file_proto_smoxy_util_db_dgraph_options_proto_enumTypes[1]
+}
+
+func (x DgraphField_OnDelete) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DgraphField_OnDelete.Descriptor instead.
+func (DgraphField_OnDelete) EnumDescriptor() ([]byte, []int) {
+	return file_proto_smoxy_util_db_dgraph_options_proto_rawDescGZIP(), []int{0, 1}
+}
+
+// DgraphField configures the Dgraph predicate of a field
+//
+//	string email = 2 [(smoxy.util.db.dgraph.field) = {index: ["exact"], upsert: true, unique: true}];
+type DgraphField struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// index the tokenizers of the predicate's index, e.g. exact, hash, term, fulltext, trigram, int, day
+	Index []string `protobuf:"bytes,1,rep,name=index,proto3" json:"index,omitempty"`
+	// reverse adds a reverse edge (edges only)
+	Reverse bool `protobuf:"varint,2,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	// upsert checks the predicate for conflicts in transactions. requires an index
+	Upsert bool `protobuf:"varint,3,opt,name=upsert,proto3" json:"upsert,omitempty"`
+	// unique the value of the predicate must be unique. requires an index and upsert
+	Unique bool `protobuf:"varint,4,opt,name=unique,proto3" json:"unique,omitempty"`
+	// count indexes the number of values (or edges) of the predicate
+	Count bool `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	// lang stores values in multiple languages (strings only)
+	Lang bool `protobuf:"varint,6,opt,name=lang,proto3" json:"lang,omitempty"`
+	// type overrides the predicate's type, e.g. datetime or geo
+	Type string `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	// skip the field is not stored in Dgraph
+	Skip bool `protobuf:"varint,8,opt,name=skip,proto3" json:"skip,omitempty"`
+	// timestamp the field is a timestamp maintained by the models package (strings or integers). the value is in unix
+	// seconds, or RFC 3339 when the type is datetime
+	Timestamp DgraphField_Timestamp `protobuf:"varint,9,opt,name=timestamp,proto3,enum=smoxy.util.db.dgraph.DgraphField_Timestamp" json:"timestamp,omitempty"`
+	// version the field is the version of the model (integers only). writes fail with a conflict when the model was
+	// written since it was read
+	Version bool `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+	// onDelete what happens to the related models when the model is deleted (edges only)
+	OnDelete DgraphField_OnDelete `protobuf:"varint,11,opt,name=onDelete,proto3,enum=smoxy.util.db.dgraph.DgraphField_OnDelete" json:"onDelete,omitempty"`
+}
+
+func (x *DgraphField) Reset() {
+	*x = DgraphField{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DgraphField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DgraphField) ProtoMessage() {}
+
+func (x *DgraphField) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DgraphField.ProtoReflect.Descriptor instead.
+func (*DgraphField) Descriptor() ([]byte, []int) {
+	return file_proto_smoxy_util_db_dgraph_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DgraphField) GetIndex() []string {
+	if x != nil {
+		return x.Index
+	}
+	return nil
+}
+
+func (x *DgraphField) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *DgraphField) GetUpsert() bool {
+	if x != nil {
+		return x.Upsert
+	}
+	return false
+}
+
+func (x *DgraphField) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *DgraphField) GetCount() bool {
+	if x != nil {
+		return x.Count
+	}
+	return false
+}
+
+func (x *DgraphField) GetLang() bool {
+	if x != nil {
+		return x.Lang
+	}
+	return false
+}
+
+func (x *DgraphField) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DgraphField) GetSkip() bool {
+	if x != nil {
+		return x.Skip
+	}
+	return false
+}
+
+func (x *DgraphField) GetTimestamp() DgraphField_Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return DgraphField_NO_TIMESTAMP
+}
+
+func (x *DgraphField) GetVersion() bool {
+	if x != nil {
+		return x.Version
+	}
+	return false
+}
+
+func (x *DgraphField) GetOnDelete() DgraphField_OnDelete {
+	if x != nil {
+		return x.OnDelete
+	}
+	return DgraphField_KEEP
+}
+
+// DgraphType configures the Dgraph type of a message
+type DgraphType struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// skip the message is not stored in Dgraph
+	Skip bool `protobuf:"varint,1,opt,name=skip,proto3" json:"skip,omitempty"`
+}
+
+func (x *DgraphType) Reset() {
+	*x = DgraphType{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DgraphType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DgraphType) ProtoMessage() {}
+
+func (x *DgraphType) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DgraphType.ProtoReflect.Descriptor instead.
+func (*DgraphType) Descriptor() ([]byte, []int) {
+	return file_proto_smoxy_util_db_dgraph_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DgraphType) GetSkip() bool {
+	if x != nil {
+		return x.Skip
+	}
+	return false
+}
+
+var file_proto_smoxy_util_db_dgraph_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*DgraphField)(nil),
+		Field:         51000,
+		Name:          "smoxy.util.db.dgraph.field",
+		Tag:           "bytes,51000,opt,name=field",
+		Filename:      "proto/smoxy/util/db/dgraph/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*DgraphType)(nil),
+		Field:         51000,
+		Name:          "smoxy.util.db.dgraph.type",
+		Tag:           "bytes,51000,opt,name=type",
+		Filename:      "proto/smoxy/util/db/dgraph/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional smoxy.util.db.dgraph.DgraphField field = 51000;
+	E_Field = &file_proto_smoxy_util_db_dgraph_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.MessageOptions.
+var (
+	// optional smoxy.util.db.dgraph.DgraphType type = 51000;
+	E_Type = &file_proto_smoxy_util_db_dgraph_options_proto_extTypes[1]
+)
+
+var File_proto_smoxy_util_db_dgraph_options_proto protoreflect.FileDescriptor
+
+var file_proto_smoxy_util_db_dgraph_options_proto_rawDesc = []byte{
+	0x0a, 0x28, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2f, 0x75, 0x74,
+	0x69, 0x6c, 0x2f, 0x64, 0x62, 0x2f, 0x64, 0x67, 0x72, 0x61, 0x70, 0x68, 0x2f, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x73, 0x6d, 0x6f, 0x78,
+	0x79, 0x2e, 0x75, 0x74, 0x69, 0x6c, 0x2e, 0x64, 0x62, 0x2e, 0x64, 0x67, 0x72, 0x61, 0x70, 0x68,
+	0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0xf4, 0x03, 0x0a, 0x0b, 0x44, 0x67, 0x72, 0x61, 0x70, 0x68, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x76, 0x65,
+	0x72, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72,
+	0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x73, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x75, 0x70, 0x73, 0x65, 0x72, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e,
+	0x69, 0x71, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x71,
+	0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x61, 0x6e, 0x67,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x61, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x73, 0x6b, 0x69, 0x70, 0x12, 0x49, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2e,
+	0x75, 0x74, 0x69, 0x6c, 0x2e, 0x64, 0x62, 0x2e, 0x64, 0x67, 0x72, 0x61, 0x70, 0x68, 0x2e, 0x44,
+	0x67, 0x72, 0x61, 0x70, 0x68, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x46, 0x0a, 0x08, 0x6f, 0x6e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x73, 0x6d,
+	0x6f, 0x78, 0x79, 0x2e, 0x75, 0x74, 0x69, 0x6c, 0x2e, 0x64, 0x62, 0x2e, 0x64, 0x67, 0x72, 0x61,
+	0x70, 0x68, 0x2e, 0x44, 0x67, 0x72, 0x61, 0x70, 0x68, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x2e, 0x4f,
+	0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x08, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x22, 0x4d, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x10,
+	0x0a, 0x0c, 0x4e, 0x4f, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x10, 0x00,
+	0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x54, 0x10, 0x01,
+	0x12, 0x0e, 0x0a, 0x0a, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x54, 0x10, 0x02,
+	0x12, 0x0e, 0x0a, 0x0a, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x54, 0x10, 0x03,
+	0x22, 0x2f, 0x0a, 0x08, 0x4f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x08, 0x0a, 0x04,
+	0x4b, 0x45, 0x45, 0x50, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x41, 0x53, 0x43, 0x41, 0x44,
+	0x45, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x45, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54, 0x10,
+	0x02, 0x3a, 0x06, 0xc2, 0xf3, 0x18, 0x02, 0x08, 0x01, 0x22, 0x28, 0x0a, 0x0a, 0x44, 0x67, 0x72,
+	0x61, 0x70, 0x68, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x6b, 0x69, 0x70, 0x3a, 0x06, 0xc2, 0xf3, 0x18,
+	0x02, 0x08, 0x01, 0x3a, 0x58, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1d, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xb8, 0x8e, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2e, 0x75, 0x74, 0x69, 0x6c,
+	0x2e, 0x64, 0x62, 0x2e, 0x64, 0x67, 0x72, 0x61, 0x70, 0x68, 0x2e, 0x44, 0x67, 0x72, 0x61, 0x70,
+	0x68, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x3a, 0x57, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xb8, 0x8e, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2e, 0x75, 0x74, 0x69, 0x6c, 0x2e, 0x64, 0x62, 0x2e, 0x64,
+	0x67, 0x72, 0x61, 0x70, 0x68, 0x2e, 0x44, 0x67, 0x72, 0x61, 0x70, 0x68, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2d, 0x69, 0x6f, 0x2f, 0x67, 0x6f,
+	0x53, 0x44, 0x4b, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x6d, 0x6f, 0x78, 0x79, 0x2f,
+	0x75, 0x74, 0x69, 0x6c, 0x2f, 0x64, 0x62, 0x2f, 0x64, 0x67, 0x72, 0x61, 0x70, 0x68, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_smoxy_util_db_dgraph_options_proto_rawDescOnce sync.Once
+	file_proto_smoxy_util_db_dgraph_options_proto_rawDescData = file_proto_smoxy_util_db_dgraph_options_proto_rawDesc
+)
+
+func file_proto_smoxy_util_db_dgraph_options_proto_rawDescGZIP() []byte {
+	file_proto_smoxy_util_db_dgraph_options_proto_rawDescOnce.Do(func() {
+		file_proto_smoxy_util_db_dgraph_options_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_smoxy_util_db_dgraph_options_proto_rawDescData)
+	})
+	return file_proto_smoxy_util_db_dgraph_options_proto_rawDescData
+}
+
+var file_proto_smoxy_util_db_dgraph_options_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_smoxy_util_db_dgraph_options_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_smoxy_util_db_dgraph_options_proto_goTypes = []any{
+	(DgraphField_Timestamp)(0),          // 0: smoxy.util.db.dgraph.DgraphField.Timestamp
+	(DgraphField_OnDelete)(0),           // 1: smoxy.util.db.dgraph.DgraphField.OnDelete
+	(*DgraphField)(nil),                 // 2: smoxy.util.db.dgraph.DgraphField
+	(*DgraphType)(nil),                  // 3: smoxy.util.db.dgraph.DgraphType
+	(*descriptorpb.FieldOptions)(nil),   // 4: google.protobuf.FieldOptions
+	(*descriptorpb.MessageOptions)(nil), // 5: google.protobuf.MessageOptions
+}
+var file_proto_smoxy_util_db_dgraph_options_proto_depIdxs = []int32{
+	0, // 0: smoxy.util.db.dgraph.DgraphField.timestamp:type_name -> smoxy.util.db.dgraph.DgraphField.Timestamp
+	1, // 1: smoxy.util.db.dgraph.DgraphField.onDelete:type_name -> smoxy.util.db.dgraph.DgraphField.OnDelete
+	4, // 2: smoxy.util.db.dgraph.field:extendee -> google.protobuf.FieldOptions
+	5, // 3: smoxy.util.db.dgraph.type:extendee -> google.protobuf.MessageOptions
+	2, // 4: smoxy.util.db.dgraph.field:type_name -> smoxy.util.db.dgraph.DgraphField
+	3, // 5: smoxy.util.db.dgraph.type:type_name -> smoxy.util.db.dgraph.DgraphType
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	4, // [4:6] is the sub-list for extension type_name
+	2, // [2:4] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_smoxy_util_db_dgraph_options_proto_init() }
+func file_proto_smoxy_util_db_dgraph_options_proto_init() {
+	if File_proto_smoxy_util_db_dgraph_options_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*DgraphField); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_smoxy_util_db_dgraph_options_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*DgraphType); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_smoxy_util_db_dgraph_options_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   2,
+			NumExtensions: 2,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_smoxy_util_db_dgraph_options_proto_goTypes,
+		DependencyIndexes: file_proto_smoxy_util_db_dgraph_options_proto_depIdxs,
+		EnumInfos:         file_proto_smoxy_util_db_dgraph_options_proto_enumTypes,
+		MessageInfos:      file_proto_smoxy_util_db_dgraph_options_proto_msgTypes,
+		ExtensionInfos:    file_proto_smoxy_util_db_dgraph_options_proto_extTypes,
+	}.Build()
+	File_proto_smoxy_util_db_dgraph_options_proto = out.File
+	file_proto_smoxy_util_db_dgraph_options_proto_rawDesc = nil
+	file_proto_smoxy_util_db_dgraph_options_proto_goTypes = nil
+	file_proto_smoxy_util_db_dgraph_options_proto_depIdxs = nil
+}
@@ -0,0 +1,21 @@
+package arrays
+
+// Chunk splits the slice a into chunks of at most size elements, preserving the order of the elements
+// Useful for batching work over a large slice
+// The last chunk will be shorter if len(a) is not a multiple of size
+// If size < 1, a single chunk is returned
+func Chunk[A ~[]V, V any](a A, size int) []A {
+	if size < 1 || len(a) <= size {
+		return []A{a}
+	}
+
+	chunks := make([]A, 0, (len(a)+size-1)/size)
+
+	for start := 0; start < len(a); start += size {
+		end := min(start+size, len(a))
+
+		chunks = append(chunks, a[start:end:end])
+	}
+
+	return chunks
+}
@@ -0,0 +1,37 @@
+package arrays
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		a      []int
+		size   int
+		expect [][]int
+	}{
+		{a: []int{}, size: 2, expect: [][]int{{}}},
+		{a: []int{1, 2, 3}, size: 0, expect: [][]int{{1, 2, 3}}},
+		{a: []int{1, 2, 3}, size: 3, expect: [][]int{{1, 2, 3}}},
+		{a: []int{1, 2, 3, 4}, size: 2, expect: [][]int{{1, 2}, {3, 4}}},
+		{a: []int{1, 2, 3, 4, 5}, size: 2, expect: [][]int{{1, 2}, {3, 4}, {5}}},
+	}
+
+	for i, test := range tests {
+		chunks := Chunk(test.a, test.size)
+
+		if !slices.EqualFunc(test.expect, chunks, slices.Equal[[]int]) {
+			t.Errorf("Chunk[%d]: expected %v, got %v", i, test.expect, chunks)
+		}
+	}
+
+	// appending to a chunk must not overwrite the next chunk
+	a := []int{1, 2, 3, 4}
+	chunks := Chunk(a, 2)
+	_ = append(chunks[0], 9)
+
+	if a[2] != 3 {
+		t.Errorf("Chunk: appending to a chunk modified the source slice")
+	}
+}
@@ -29,10 +29,10 @@ func NewJWTManager(secretKey *PrivateKey, tokenDuration time.Duration) *JWTManag
 	}
 }
 
-func (m *JWTManager) Generate(user User) (string, error) {
+func (m *JWTManager) Generate(user User, opts ...ClaimsOption) (string, error) {
 	now := time.Now()
 
-	token := jwt.NewWithClaims(m.secretKey.GetSigningMethod(), &UserClaims{
+	claims := &UserClaims{
 		Expires:   now.Add(m.tokenDuration).UTC().Unix(),
 		NotBefore: now.Add(-1 * time.Second * 5).UTC().Unix(), // support remote clocks running a few seconds behind
 		UserId:    user.GetId(),
@@ -40,7 +40,13 @@ func (m *JWTManager) Generate(user User) (string, error) {
 		Issued:    now.UTC().Unix(),
 		Type:      JwtTypePAuth,
 		Issuer:    GetJwtIssuer(),
-	})
+	}
+
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	token := jwt.NewWithClaims(m.secretKey.GetSigningMethod(), claims)
 
 	return token.SignedString(m.secretKey.key)
 }
@@ -79,8 +85,8 @@ func VerifyJwt(token string) (*UserClaims, error) {
 	return DefaultJwtManager().Verify(token)
 }
 
-func GenerateJwt(user User) (string, error) {
-	return DefaultJwtManager().Generate(user)
+func GenerateJwt(user User, opts ...ClaimsOption) (string, error) {
+	return DefaultJwtManager().Generate(user, opts...)
 }
 
 // mustGetPrivateKey returns the PrivateKey to use for JWT tokens. panics if an error occurs
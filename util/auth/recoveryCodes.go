@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	RecoveryCodeCount  = 10
+	RecoveryCodeLength = 10
+
+	// unambiguous characters used in recovery codes (no 0/o, 1/l/i)
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// GenerateRecoveryCodes creates n (default: RecoveryCodeCount) single use MFA recovery codes
+//
+// the plain text codes are returned to be shown to the user once. the hashes (created with HashPassword) are
+// returned in the same order and are what should be stored
+func GenerateRecoveryCodes(n ...int) ([]string, []string, error) {
+	count := RecoveryCodeCount
+
+	if len(n) > 0 && n[0] > 0 {
+		count = n[0]
+	}
+
+	codes := make([]string, 0, count)
+	hashes := make([]string, 0, count)
+
+	for i := 0; i < count; i++ {
+		code, cErr := newRecoveryCode()
+
+		if cErr != nil {
+			return nil, nil, cErr
+		}
+
+		hash, hErr := HashPassword(normalizeRecoveryCode(code))
+
+		if hErr != nil {
+			return nil, nil, hErr
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+// RecoveryCodeMatches returns the index of the hash in hashes that matches code, or -1 if there is no match.
+// the matching hash MUST be removed from storage by the caller so the code cannot be reused
+func RecoveryCodeMatches(code string, hashes []string) int {
+	code = normalizeRecoveryCode(code)
+
+	if len(code) != RecoveryCodeLength {
+		return -1
+	}
+
+	for i, hash := range hashes {
+		if PasswordMatches(code, hash) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func newRecoveryCode() (string, error) {
+	b := strings.Builder{}
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := 0; i < RecoveryCodeLength; i++ {
+		if i > 0 && i == RecoveryCodeLength/2 {
+			// format as xxxxx-xxxxx for readability
+			b.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
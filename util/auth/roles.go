@@ -85,6 +85,23 @@ func (r Role) Remove(role Role) Role {
 	return r &^ role
 }
 
+// Names returns the names of the individual roles in r
+func (r Role) Names() []string {
+	names := make([]string, 0)
+
+	for bit := Role(1); bit > 0 && bit <= r; bit <<= 1 {
+		if !r.Has(bit) {
+			continue
+		}
+
+		if name, ok := roleNameMap[bit]; ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
 func (r Role) ContextWithRoles(ctx context.Context) context.Context {
 	return context.WithValue(ctx, RoleContextKey, r)
 }
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TotpDigits     = 6
+	TotpPeriod     = 30 * time.Second
+	TotpSkew       = 1
+	TotpSecretSize = 20
+	TotpAlgorithm  = "SHA1"
+)
+
+var (
+	ErrTotpInvalidSecret = errors.New("invalid totp secret")
+	ErrTotpInvalidCode   = errors.New("invalid totp code")
+	ErrTotpCodeReused    = errors.New("totp code has already been used")
+)
+
+// TotpOptions configures how TOTP (RFC 6238) codes are generated and verified
+//
+// Skew is the number of time steps before and after the current time step that are accepted to allow for
+// clock drift between the server and the authenticator app
+type TotpOptions struct {
+	Digits int
+	Period time.Duration
+	Skew   int
+}
+
+var (
+	totpOptions = TotpOptions{
+		Digits: TotpDigits,
+		Period: TotpPeriod,
+		Skew:   TotpSkew,
+	}
+
+	totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// GenerateTotpSecret creates a new random base32 encoded TOTP secret
+func GenerateTotpSecret() (string, error) {
+	secret := make([]byte, TotpSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TotpProvisioningUri creates an otpauth:// uri for secret that can be rendered as a QR code and scanned by
+// authenticator apps. issuer defaults to the JWT issuer
+func TotpProvisioningUri(secret string, account string, issuer ...string) string {
+	iss := GetJwtIssuer()
+
+	if len(issuer) > 0 && issuer[0] != "" {
+		iss = issuer[0]
+	}
+
+	label := url.PathEscape(account)
+
+	if iss != "" {
+		label = url.PathEscape(iss) + ":" + label
+	}
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("algorithm", TotpAlgorithm)
+	params.Set("digits", strconv.Itoa(totpOptions.Digits))
+	params.Set("period", strconv.Itoa(int(totpOptions.Period/time.Second)))
+
+	if iss != "" {
+		params.Set("issuer", iss)
+	}
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GenerateTotpCode generates the TOTP code for secret at time t
+func GenerateTotpCode(secret string, t time.Time) (string, error) {
+	key, kErr := decodeTotpSecret(secret)
+
+	if kErr != nil {
+		return "", kErr
+	}
+
+	return totpCode(key, totpStep(t), totpOptions.Digits), nil
+}
+
+// ValidateTotp verifies code against secret using the current time
+//
+// lastStep is the time step of the last code that was successfully used with secret (0 if no code has been used).
+// codes from lastStep or earlier are rejected to prevent replay attacks. the time step of the matching code is
+// returned and MUST be stored by the caller and passed as lastStep for the next validation
+func ValidateTotp(secret string, code string, lastStep int64) (int64, error) {
+	return validateTotp(secret, code, lastStep, time.Now())
+}
+
+func validateTotp(secret string, code string, lastStep int64, t time.Time) (int64, error) {
+	key, kErr := decodeTotpSecret(secret)
+
+	if kErr != nil {
+		return 0, kErr
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) != totpOptions.Digits {
+		return 0, ErrTotpInvalidCode
+	}
+
+	step := totpStep(t)
+	reused := false
+
+	for i := -totpOptions.Skew; i <= totpOptions.Skew; i++ {
+		s := step + int64(i)
+
+		if s < 0 {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, s, totpOptions.Digits)), []byte(code)) != 1 {
+			continue
+		}
+
+		if s <= lastStep {
+			// the code is valid, but it (or a later code) has already been used
+			reused = true
+			continue
+		}
+
+		return s, nil
+	}
+
+	if reused {
+		return 0, ErrTotpCodeReused
+	}
+
+	return 0, ErrTotpInvalidCode
+}
+
+func decodeTotpSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := totpEncoding.DecodeString(secret)
+
+	if err != nil || len(key) == 0 {
+		return nil, ErrTotpInvalidSecret
+	}
+
+	return key, nil
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpOptions.Period/time.Second)
+}
+
+// totpCode implements the HOTP (RFC 4226) algorithm for the time step counter
+func totpCode(key []byte, step int64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	code := strconv.FormatUint(uint64(bin%mod), 10)
+
+	return strings.Repeat("0", digits-len(code)) + code
+}
+
+func SetTotpOptions(options TotpOptions) {
+	totpOptions = options
+}
+
+func GetTotpOptions() TotpOptions {
+	return totpOptions
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B test vectors (SHA1, 8 digits)
+var totpTestVectors = map[int64]string{
+	59:          "94287082",
+	1111111109:  "07081804",
+	1111111111:  "14050471",
+	1234567890:  "89005924",
+	2000000000:  "69279037",
+	20000000000: "65353130",
+}
+
+func Test_totpCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	for ts, expected := range totpTestVectors {
+		if code := totpCode(key, totpStep(time.Unix(ts, 0)), 8); code != expected {
+			t.Errorf("totpCode() at %d = %s, expected %s", ts, code, expected)
+		}
+	}
+}
+
+func Test_ValidateTotp(t *testing.T) {
+	secret, sErr := GenerateTotpSecret()
+
+	if sErr != nil {
+		t.Errorf("error generating totp secret: %v", sErr)
+		return
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	code, cErr := GenerateTotpCode(secret, now)
+
+	if cErr != nil {
+		t.Errorf("error generating totp code: %v", cErr)
+		return
+	}
+
+	step, vErr := validateTotp(secret, code, 0, now)
+
+	if vErr != nil {
+		t.Errorf("valid code rejected: %v", vErr)
+		return
+	}
+
+	if step != totpStep(now) {
+		t.Errorf("expected step %d, got %d", totpStep(now), step)
+	}
+
+	// clock drift within the skew window is accepted
+	if _, err := validateTotp(secret, code, 0, now.Add(TotpPeriod)); err != nil {
+		t.Errorf("code within drift window rejected: %v", err)
+	}
+
+	// clock drift outside the skew window is rejected
+	if _, err := validateTotp(secret, code, 0, now.Add(3*TotpPeriod)); err != ErrTotpInvalidCode {
+		t.Errorf("expected %v for code outside drift window, got %v", ErrTotpInvalidCode, err)
+	}
+
+	// replaying the same code is rejected
+	if _, err := validateTotp(secret, code, step, now); err != ErrTotpCodeReused {
+		t.Errorf("expected %v for replayed code, got %v", ErrTotpCodeReused, err)
+	}
+
+	if _, err := validateTotp(secret, "12345", 0, now); err != ErrTotpInvalidCode {
+		t.Errorf("expected %v for short code, got %v", ErrTotpInvalidCode, err)
+	}
+
+	if _, err := validateTotp("not base32!", code, 0, now); err != ErrTotpInvalidSecret {
+		t.Errorf("expected %v for invalid secret, got %v", ErrTotpInvalidSecret, err)
+	}
+}
+
+func Test_TotpProvisioningUri(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	uri := TotpProvisioningUri(secret, "alice@example.com", "Smoxy")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Smoxy:alice@example.com?") {
+		t.Errorf("unexpected provisioning uri label: %s", uri)
+		return
+	}
+
+	u, uErr := url.Parse(uri)
+
+	if uErr != nil {
+		t.Errorf("error parsing provisioning uri: %v", uErr)
+		return
+	}
+
+	q := u.Query()
+
+	if q.Get("secret") != secret || q.Get("issuer") != "Smoxy" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("unexpected provisioning uri parameters: %v", q)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,6 +20,27 @@ type UserClaims struct {
 	Issued    int64    `json:"iat,omitempty"`
 	Type      int      `json:"typ,omitempty"`
 	Issuer    string   `json:"iss,omitempty"`
+	Amr       []string `json:"amr,omitempty"`
+}
+
+// authentication method references (RFC 8176) used in the UserClaims.Amr claim
+const (
+	AmrMfa          = "mfa"
+	AmrPassword     = "pwd"
+	AmrTotp         = "otp"
+	AmrRecoveryCode = "rcc"
+	AmrWebAuthn     = "hwk"
+)
+
+// ClaimsOption modifies the claims of a token before it is signed
+type ClaimsOption func(claims *UserClaims)
+
+// WithMfa marks the claims as having completed multi-factor authentication using the given methods
+// (ex: AmrPassword, AmrTotp)
+func WithMfa(methods ...string) ClaimsOption {
+	return func(claims *UserClaims) {
+		claims.Amr = append(append(claims.Amr, AmrMfa), methods...)
+	}
 }
 
 func (u *UserClaims) GetExpirationTime() (*jwt.NumericDate, error) {
@@ -59,6 +81,11 @@ func (u *UserClaims) TimeTravel() bool {
 	return time.Now().Before(u.NotBeforeTime())
 }
 
+// HasMfa returns true if multi-factor authentication was completed when the claims were issued
+func (u *UserClaims) HasMfa() bool {
+	return slices.Contains(u.Amr, AmrMfa)
+}
+
 func (u *UserClaims) ContextWithClaims(ctx context.Context) context.Context {
 	return context.WithValue(ctx, JwtContextKey, u)
 }
@@ -91,6 +118,14 @@ func GetUserClaimsFromCtx(ctx context.Context) *UserClaims {
 	return nil
 }
 
+func GetMfaFromCtx(ctx context.Context) bool {
+	if claims := GetUserClaimsFromCtx(ctx); claims != nil {
+		return claims.HasMfa()
+	}
+
+	return false
+}
+
 func SetJwtIssuer(issuer string) {
 	jwtIssuer = issuer
 }
@@ -3,6 +3,7 @@ package gcm256
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"io"
 
 	"github.com/smoxy-io/goSDK/util/errors"
 )
@@ -43,6 +44,18 @@ func (k *Key) DecryptString(data string) (string, error) {
 	return DecryptString(base64.StdEncoding.EncodeToString(k.key), data)
 }
 
+func (k *Key) EncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	return NewEncryptWriter(k.key, w)
+}
+
+func (k *Key) DecryptReader(r io.Reader) (io.Reader, error) {
+	return NewDecryptReader(k.key, r)
+}
+
+func (k *Key) DecryptReaderAt(r io.ReaderAt, size int64) (io.ReaderAt, int64, error) {
+	return NewDecryptReaderAt(k.key, r, size)
+}
+
 func NewKey(key []byte) *Key {
 	if len(key) == AES256KeySize {
 		return &Key{key: key}
@@ -0,0 +1,391 @@
+package gcm256
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+// streams use the STREAM construction: the plaintext is split into StreamChunkSize chunks and each chunk is
+// encrypted with its own nonce made of a random prefix, the chunk counter and a flag marking the final chunk.
+// reordering, dropping or truncating chunks makes decryption fail
+//
+// stream layout: nonce prefix (7) | chunk 0 | chunk 1 | ... | final chunk
+const (
+	StreamChunkSize   = 64 * 1024
+	StreamNoncePrefix = 7
+)
+
+var (
+	ErrStreamTruncated = errors.New("encrypted stream is truncated")
+	ErrStreamClosed    = errors.New("encrypted stream is closed")
+)
+
+type streamCipher struct {
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint64
+}
+
+func (s *streamCipher) nonce(last bool) ([]byte, error) {
+	nonce, err := s.nonceAt(s.counter, last)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.counter++
+
+	return nonce, nil
+}
+
+// nonceAt returns the nonce of the chunk counter
+func (s *streamCipher) nonceAt(counter uint64, last bool) ([]byte, error) {
+	if counter > math.MaxUint32 {
+		return nil, errors.New("encrypted stream is too large")
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	copy(nonce, s.prefix)
+	binary.BigEndian.PutUint32(nonce[StreamNoncePrefix:], uint32(counter))
+
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce, nil
+}
+
+func newStreamCipher(key []byte, prefix []byte) (*streamCipher, error) {
+	if len(key) == 0 {
+		return nil, errors.New("no key provided")
+	}
+
+	block, bErr := aes.NewCipher(key)
+
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	aead, gErr := cipher.NewGCM(block)
+
+	if gErr != nil {
+		return nil, gErr
+	}
+
+	return &streamCipher{aead: aead, prefix: prefix}, nil
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	sc     *streamCipher
+	buf    []byte
+	closed bool
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrStreamClosed
+	}
+
+	n := 0
+
+	for len(p) > 0 {
+		// a full chunk is only written once more data arrives so that the final chunk is never empty unless the
+		// stream is empty
+		if len(e.buf) == StreamChunkSize {
+			if err := e.writeChunk(false); err != nil {
+				return n, err
+			}
+		}
+
+		c := copy(e.buf[len(e.buf):StreamChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+		n += c
+	}
+
+	return n, nil
+}
+
+// Close writes the final chunk. it does not close the underlying writer
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	return e.writeChunk(true)
+}
+
+func (e *encryptWriter) writeChunk(last bool) error {
+	nonce, nErr := e.sc.nonce(last)
+
+	if nErr != nil {
+		return nErr
+	}
+
+	if _, err := e.w.Write(e.sc.aead.Seal(nil, nonce, e.buf, nil)); err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+
+	return nil
+}
+
+// NewEncryptWriter returns a writer that encrypts everything written to it with key and writes the ciphertext to
+// w. Close MUST be called to write the final chunk, otherwise the stream cannot be decrypted
+func NewEncryptWriter(key []byte, w io.Writer) (io.WriteCloser, error) {
+	prefix := make([]byte, StreamNoncePrefix)
+
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	sc, sErr := newStreamCipher(key, prefix)
+
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:   w,
+		sc:  sc,
+		buf: make([]byte, 0, StreamChunkSize),
+	}, nil
+}
+
+type decryptReader struct {
+	r     *bufio.Reader
+	sc    *streamCipher
+	chunk []byte
+	plain []byte
+	done  bool
+	err   error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		if d.done {
+			return 0, io.EOF
+		}
+
+		d.err = d.readChunk()
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+
+	return n, nil
+}
+
+func (d *decryptReader) readChunk() error {
+	n, rErr := io.ReadFull(d.r, d.chunk)
+
+	switch {
+	case rErr == io.EOF:
+		// the stream ended without a final chunk
+		return ErrStreamTruncated
+	case rErr == io.ErrUnexpectedEOF:
+		d.done = true
+	case rErr != nil:
+		return rErr
+	default:
+		// a full chunk is the final chunk when nothing follows it
+		if _, err := d.r.Peek(1); err == io.EOF {
+			d.done = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if n < d.sc.aead.Overhead() {
+		return ErrStreamTruncated
+	}
+
+	nonce, nErr := d.sc.nonce(d.done)
+
+	if nErr != nil {
+		return nErr
+	}
+
+	plain, oErr := d.sc.aead.Open(d.chunk[:0], nonce, d.chunk[:n], nil)
+
+	if oErr != nil {
+		return oErr
+	}
+
+	d.plain = plain
+
+	return nil
+}
+
+// NewDecryptReader returns a reader that decrypts a stream written by NewEncryptWriter. Read returns an error if
+// the stream was modified or truncated. data is returned one chunk at a time as it is authenticated, so callers
+// MUST discard everything read when an error is returned
+func NewDecryptReader(key []byte, r io.Reader) (io.Reader, error) {
+	prefix := make([]byte, StreamNoncePrefix)
+
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrStreamTruncated
+		}
+
+		return nil, err
+	}
+
+	sc, sErr := newStreamCipher(key, prefix)
+
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return &decryptReader{
+		r:     bufio.NewReaderSize(r, StreamChunkSize+sc.aead.Overhead()),
+		sc:    sc,
+		chunk: make([]byte, StreamChunkSize+sc.aead.Overhead()),
+	}, nil
+}
+
+// decryptReaderAt decrypts the chunk of a stream that holds the requested data. the last decrypted chunk is kept, so
+// sequential reads decrypt every chunk once
+type decryptReaderAt struct {
+	r      io.ReaderAt
+	sc     *streamCipher
+	chunks int64
+	// size the size of the ciphertext after the nonce prefix
+	size int64
+
+	lock  sync.Mutex
+	index int64
+	chunk []byte
+	plain []byte
+}
+
+func (d *decryptReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	n := 0
+
+	for len(p) > 0 {
+		index := off / StreamChunkSize
+
+		if index >= d.chunks {
+			return n, io.EOF
+		}
+
+		if err := d.readChunk(index); err != nil {
+			return n, err
+		}
+
+		start := int(off % StreamChunkSize)
+
+		if start >= len(d.plain) {
+			return n, io.EOF
+		}
+
+		c := copy(p, d.plain[start:])
+		p = p[c:]
+		off += int64(c)
+		n += c
+	}
+
+	return n, nil
+}
+
+func (d *decryptReaderAt) readChunk(index int64) error {
+	if d.plain != nil && d.index == index {
+		return nil
+	}
+
+	d.plain = nil
+
+	chunkSize := int64(len(d.chunk))
+	n := min(chunkSize, d.size-index*chunkSize)
+
+	if m, err := d.r.ReadAt(d.chunk[:n], StreamNoncePrefix+index*chunkSize); int64(m) < n {
+		if err == io.EOF {
+			return ErrStreamTruncated
+		}
+
+		return err
+	}
+
+	nonce, nErr := d.sc.nonceAt(uint64(index), index == d.chunks-1)
+
+	if nErr != nil {
+		return nErr
+	}
+
+	plain, oErr := d.sc.aead.Open(d.chunk[:0], nonce, d.chunk[:n], nil)
+
+	if oErr != nil {
+		return oErr
+	}
+
+	d.index = index
+	d.plain = plain
+
+	return nil
+}
+
+// NewDecryptReaderAt returns a reader with random access to the plaintext of a stream of size bytes written by
+// NewEncryptWriter, and the size of the plaintext. only the chunks that are read are decrypted and authenticated,
+// ReadAt returns an error if one of them was modified. a truncated stream is detected when its last chunk is read
+func NewDecryptReaderAt(key []byte, r io.ReaderAt, size int64) (io.ReaderAt, int64, error) {
+	prefix := make([]byte, StreamNoncePrefix)
+
+	if _, err := r.ReadAt(prefix, 0); err != nil {
+		if err == io.EOF {
+			return nil, 0, ErrStreamTruncated
+		}
+
+		return nil, 0, err
+	}
+
+	sc, sErr := newStreamCipher(key, prefix)
+
+	if sErr != nil {
+		return nil, 0, sErr
+	}
+
+	overhead := int64(sc.aead.Overhead())
+	chunkSize := StreamChunkSize + overhead
+	body := size - StreamNoncePrefix
+	chunks := (body + chunkSize - 1) / chunkSize
+
+	// every stream ends with a final chunk, which is only empty when the stream is
+	if chunks == 0 || body-(chunks-1)*chunkSize < overhead {
+		return nil, 0, ErrStreamTruncated
+	}
+
+	return &decryptReaderAt{
+		r:      r,
+		sc:     sc,
+		chunks: chunks,
+		size:   body,
+		chunk:  make([]byte, chunkSize),
+	}, body - chunks*overhead, nil
+}
@@ -0,0 +1,149 @@
+package gcm256
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func encryptStream(t *testing.T, key *Key, data []byte) []byte {
+	buf := bytes.Buffer{}
+
+	w, wErr := key.EncryptWriter(&buf)
+
+	if wErr != nil {
+		t.Fatalf("error creating encrypt writer: %v", wErr)
+	}
+
+	// write in uneven pieces to exercise chunk buffering
+	for len(data) > 0 {
+		n := min(len(data), 10000)
+
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatalf("error writing: %v", err)
+		}
+
+		data = data[n:]
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func decryptStream(key *Key, enc []byte) ([]byte, error) {
+	r, rErr := key.DecryptReader(bytes.NewReader(enc))
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return io.ReadAll(r)
+}
+
+func decryptStreamAt(key *Key, enc []byte) ([]byte, error) {
+	r, size, rErr := key.DecryptReaderAt(bytes.NewReader(enc), int64(len(enc)))
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return io.ReadAll(io.NewSectionReader(r, 0, size))
+}
+
+func Test_Stream_EncryptDecrypt(t *testing.T) {
+	key := NewKey(nil)
+
+	for _, size := range []int{0, 1, StreamChunkSize - 1, StreamChunkSize, StreamChunkSize + 1, 3*StreamChunkSize + 123} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+
+		enc := encryptStream(t, key, data)
+
+		dec, err := decryptStream(key, enc)
+
+		if err != nil {
+			t.Errorf("size %d: error decrypting: %v", size, err)
+			continue
+		}
+
+		if !bytes.Equal(dec, data) {
+			t.Errorf("size %d: decrypted data does not match", size)
+		}
+	}
+}
+
+func Test_Stream_ReaderAt(t *testing.T) {
+	key := NewKey(nil)
+
+	for _, size := range []int{0, 1, StreamChunkSize, StreamChunkSize + 1, 3*StreamChunkSize + 123} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+
+		enc := encryptStream(t, key, data)
+
+		dec, err := decryptStreamAt(key, enc)
+
+		if err != nil || !bytes.Equal(dec, data) {
+			t.Errorf("size %d: decrypted data does not match: %v", size, err)
+			continue
+		}
+
+		r, _, _ := key.DecryptReaderAt(bytes.NewReader(enc), int64(len(enc)))
+
+		// reads across chunk boundaries, backwards
+		for off := size - 100; off > 0; off -= StreamChunkSize / 3 {
+			p := make([]byte, min(200, size-off))
+
+			if n, rErr := r.ReadAt(p, int64(off)); n != len(p) || (rErr != nil && rErr != io.EOF) {
+				t.Errorf("size %d: ReadAt(%d) = %d, %v", size, off, n, rErr)
+			} else if !bytes.Equal(p, data[off:off+len(p)]) {
+				t.Errorf("size %d: ReadAt(%d) data does not match", size, off)
+			}
+		}
+
+		if n, rErr := r.ReadAt(make([]byte, 1), int64(size)); n != 0 || rErr != io.EOF {
+			t.Errorf("size %d: ReadAt() past the end = %d, %v, expected io.EOF", size, n, rErr)
+		}
+	}
+}
+
+func Test_Stream_Tampering(t *testing.T) {
+	key := NewKey(nil)
+	data := make([]byte, 2*StreamChunkSize+100)
+	_, _ = rand.Read(data)
+
+	enc := encryptStream(t, key, data)
+	chunk := StreamChunkSize + 16
+
+	tests := []struct {
+		name string
+		enc  []byte
+	}{
+		{name: "truncated at chunk boundary", enc: enc[:StreamNoncePrefix+2*chunk]},
+		{name: "truncated mid chunk", enc: enc[:StreamNoncePrefix+chunk+100]},
+		{name: "final chunk dropped", enc: enc[:StreamNoncePrefix+chunk]},
+		{name: "prefix only", enc: enc[:StreamNoncePrefix]},
+		{name: "chunks reordered", enc: append(append(bytes.Clone(enc[:StreamNoncePrefix]), enc[StreamNoncePrefix+chunk:StreamNoncePrefix+2*chunk]...), enc[StreamNoncePrefix:StreamNoncePrefix+chunk]...)},
+		{name: "bit flipped", enc: func() []byte { b := bytes.Clone(enc); b[len(b)-1] ^= 0x01; return b }()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decryptStream(key, tt.enc); err == nil {
+				t.Errorf("expected an error decrypting a modified stream")
+			}
+
+			if _, err := decryptStreamAt(key, tt.enc); err == nil {
+				t.Errorf("expected an error decrypting a modified stream at random")
+			}
+		})
+	}
+
+	if _, err := decryptStream(NewKey(nil), enc); err == nil {
+		t.Errorf("expected an error decrypting with the wrong key")
+	}
+}
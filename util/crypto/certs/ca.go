@@ -0,0 +1,192 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+const (
+	DefaultValidity = 24 * time.Hour
+)
+
+// CA a local certificate authority for tests and development. it is NOT intended for production use
+type CA struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	key     crypto.Signer
+}
+
+// Issued a certificate issued by a CA
+type Issued struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// NewCA creates a self-signed CA that is valid for DefaultValidity
+func NewCA(commonName string) (*CA, error) {
+	key, kErr := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if kErr != nil {
+		return nil, kErr
+	}
+
+	tmpl, tErr := template(commonName)
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, cErr := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	cert, pErr := x509.ParseCertificate(der)
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	return &CA{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}, nil
+}
+
+// Pool returns a cert pool that contains the CA
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	return pool
+}
+
+// IssueServer issues a server certificate for hosts. hosts may be dns names or ip addresses
+func (ca *CA) IssueServer(hosts ...string) (*Issued, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("at least one host is required")
+	}
+
+	tmpl, tErr := template(hosts[0])
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	return ca.issue(tmpl)
+}
+
+// IssueClient issues a client certificate with the subject common name and organizational units. the default
+// client cert mapper uses the common name as the user id and the organizational units as roles
+func (ca *CA) IssueClient(commonName string, organizationalUnits ...string) (*Issued, error) {
+	tmpl, tErr := template(commonName)
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	tmpl.Subject.OrganizationalUnit = organizationalUnits
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	return ca.issue(tmpl)
+}
+
+// WriteFile writes the CA certificate to a PEM encoded file
+func (ca *CA) WriteFile(certFile string) error {
+	return os.WriteFile(certFile, ca.CertPEM, 0644)
+}
+
+// TLSCertificate returns the certificate and key for use in a tls.Config
+func (i *Issued) TLSCertificate() (tls.Certificate, error) {
+	return tls.X509KeyPair(i.CertPEM, i.KeyPEM)
+}
+
+// WriteFiles writes the certificate and key to PEM encoded files
+func (i *Issued) WriteFiles(certFile string, keyFile string) error {
+	if err := os.WriteFile(certFile, i.CertPEM, 0644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(keyFile, i.KeyPEM, 0600)
+}
+
+func (ca *CA) issue(tmpl *x509.Certificate) (*Issued, error) {
+	key, kErr := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if kErr != nil {
+		return nil, kErr
+	}
+
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, cErr := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.key)
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	cert, pErr := x509.ParseCertificate(der)
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	keyDer, mErr := x509.MarshalPKCS8PrivateKey(key)
+
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return &Issued{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}),
+	}, nil
+}
+
+func template(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		// support remote clocks running a few minutes behind
+		NotBefore: now.Add(-5 * time.Minute),
+		NotAfter:  now.Add(DefaultValidity),
+	}, nil
+}
@@ -0,0 +1,219 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+const (
+	DefaultReloadInterval = time.Minute
+)
+
+// Reloader serves a certificate loaded from files and reloads it when the files change, so certificates can be
+// rotated without restarting the server. when a client CA file is set, client certificates are verified against
+// the CAs in the file, which is reloaded with the certificate
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	cert         *tls.Certificate
+	clientCAs    *x509.CertPool
+	modTimes     map[string]time.Time
+	lock         *sync.RWMutex
+}
+
+// NewReloader loads the certificate and key from PEM encoded files. clientCAFile is optional
+func NewReloader(certFile string, keyFile string, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     make(map[string]time.Time),
+		lock:         &sync.RWMutex{},
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the files again. the current certificate is kept when the files cannot be loaded
+func (r *Reloader) Reload() error {
+	modTimes := make(map[string]time.Time)
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			return errors.New("error reading %s: %v", file, err)
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, cErr := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if cErr != nil {
+		return errors.New("error loading certificate: %v", cErr)
+	}
+
+	var pool *x509.CertPool
+
+	if r.clientCAFile != "" {
+		caPEM, err := os.ReadFile(r.clientCAFile)
+
+		if err != nil {
+			return errors.New("error reading client CA file: %v", err)
+		}
+
+		pool = x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return errors.New("no certificates found in client CA file %s", r.clientCAFile)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = pool
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// Changed returns true if any of the files have been modified since they were loaded
+func (r *Reloader) Changed() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			// a missing file is likely being replaced. wait for it to be written
+			continue
+		}
+
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Watch reloads the files when they change or the process receives a SIGHUP until ctx is done. files are
+// checked every interval (DefaultReloadInterval when <= 0)
+//
+// applications that use util/os.StartSignalHandler should register HandleSignal for SIGHUP instead of relying on
+// Watch, because the signal handler resets signal notifications when it receives a SIGHUP
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer signal.Stop(sigs)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !r.Changed() {
+					continue
+				}
+			case <-sigs:
+				// intentionally blank
+			}
+
+			if err := r.Reload(); err != nil {
+				fmt.Printf("error reloading certificate: %v\n", err)
+			}
+		}
+	}()
+}
+
+// HandleSignal reloads the files. matches util/os.SignalHandler so it can be registered for SIGHUP
+func (r *Reloader) HandleSignal(_ os.Signal) bool {
+	if err := r.Reload(); err != nil {
+		fmt.Printf("error reloading certificate: %v\n", err)
+	}
+
+	return false
+}
+
+// Certificate returns the currently loaded certificate
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.cert
+}
+
+// ClientCAs returns the currently loaded client CAs. nil when no client CA file is set
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.clientCAs
+}
+
+func (r *Reloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+func (r *Reloader) GetClientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// TLSConfig returns a server config that always uses the currently loaded files. clientAuth is only used when
+// a client CA file is set
+func (r *Reloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: r.GetCertificate,
+				NextProtos:     []string{"h2", "http/1.1"},
+			}
+
+			if pool := r.ClientCAs(); pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = clientAuth
+			}
+
+			return cfg, nil
+		},
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+
+	return files
+}
@@ -0,0 +1,151 @@
+package certs
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeServerCert(t *testing.T, ca *CA, certFile string, keyFile string) *Issued {
+	issued, err := ca.IssueServer("127.0.0.1", "localhost")
+
+	if err != nil {
+		t.Fatalf("error issuing server cert: %v", err)
+	}
+
+	if err := issued.WriteFiles(certFile, keyFile); err != nil {
+		t.Fatalf("error writing server cert: %v", err)
+	}
+
+	return issued
+}
+
+func Test_Reloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca, cErr := NewCA("test ca")
+
+	if cErr != nil {
+		t.Fatalf("error creating CA: %v", cErr)
+	}
+
+	if err := ca.WriteFile(caFile); err != nil {
+		t.Fatalf("error writing CA: %v", err)
+	}
+
+	first := writeServerCert(t, ca, certFile, keyFile)
+
+	r, rErr := NewReloader(certFile, keyFile, caFile)
+
+	if rErr != nil {
+		t.Fatalf("error creating reloader: %v", rErr)
+	}
+
+	if r.Changed() {
+		t.Errorf("files should not have changed")
+	}
+
+	if r.Certificate().Leaf.SerialNumber.Cmp(first.Cert.SerialNumber) != 0 {
+		t.Errorf("unexpected certificate loaded")
+	}
+
+	second := writeServerCert(t, ca, certFile, keyFile)
+
+	// make sure the modification time changes on file systems with a coarse resolution
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(certFile, future, future)
+
+	if !r.Changed() {
+		t.Errorf("files should have changed")
+	}
+
+	if err := r.Reload(); err != nil {
+		t.Fatalf("error reloading: %v", err)
+	}
+
+	if r.Certificate().Leaf.SerialNumber.Cmp(second.Cert.SerialNumber) != 0 {
+		t.Errorf("certificate was not reloaded")
+	}
+
+	// a broken key keeps the current certificate
+	_ = os.WriteFile(keyFile, []byte("invalid"), 0600)
+
+	if err := r.Reload(); err == nil {
+		t.Errorf("expected an error reloading an invalid key")
+	}
+
+	if r.Certificate().Leaf.SerialNumber.Cmp(second.Cert.SerialNumber) != 0 {
+		t.Errorf("certificate should not change when reloading fails")
+	}
+}
+
+func Test_Reloader_TLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca, _ := NewCA("test ca")
+	_ = ca.WriteFile(caFile)
+
+	writeServerCert(t, ca, certFile, keyFile)
+
+	r, rErr := NewReloader(certFile, keyFile, caFile)
+
+	if rErr != nil {
+		t.Fatalf("error creating reloader: %v", rErr)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = r.TLSConfig(tls.RequireAndVerifyClientCert)
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, clErr := ca.IssueClient("user-1", "admin")
+
+	if clErr != nil {
+		t.Fatalf("error issuing client cert: %v", clErr)
+	}
+
+	clientCert, _ := client.TLSCertificate()
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      ca.Pool(),
+			Certificates: certs,
+		}}}
+	}
+
+	resp, err := newClient(clientCert).Get(srv.URL)
+
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+
+	if _, err := newClient().Get(srv.URL); err == nil {
+		t.Errorf("request without a client certificate should fail")
+	}
+
+	other, _ := NewCA("other ca")
+	otherClient, _ := other.IssueClient("user-2")
+	otherCert, _ := otherClient.TLSCertificate()
+
+	if _, err := newClient(otherCert).Get(srv.URL); err == nil {
+		t.Errorf("request with an untrusted client certificate should fail")
+	}
+}
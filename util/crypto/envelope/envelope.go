@@ -0,0 +1,243 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+// ciphertext layout:
+//
+//	magic (3) | version (1) | key id length (1) | key id | wrapped data key length (2) | wrapped data key |
+//	nonce (12) | encrypted data + tag
+//
+// everything before the nonce is the header and is authenticated as additional data when the data is encrypted
+const (
+	Magic   = "SXE"
+	Version = byte(1)
+
+	MaxKeyIdLength = 255
+)
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid envelope ciphertext")
+	ErrUnknownKey        = errors.New("unknown key encryption key: %s")
+)
+
+type header struct {
+	keyId      string
+	wrappedKey []byte
+	raw        []byte
+}
+
+// Encrypt encrypts data with a new random data key and wraps (encrypts) the data key with the key encryption key
+// kek. keyId identifies kek and is stored in the ciphertext header so the right key can be found for decryption
+func Encrypt(keyId string, kek *gcm256.Key, data []byte) ([]byte, error) {
+	if len(keyId) == 0 || len(keyId) > MaxKeyIdLength {
+		return nil, errors.New("key id must be between 1 and %d bytes", MaxKeyIdLength)
+	}
+
+	if kek == nil || !kek.IsValid() {
+		return nil, errors.New("invalid key encryption key")
+	}
+
+	dek := gcm256.NewKey(nil)
+
+	if !dek.IsValid() {
+		return nil, errors.New("failed to generate data key")
+	}
+
+	wrapped, wErr := kek.Encrypt(dek.Key())
+
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	hdr := encodeHeader(keyId, wrapped)
+
+	aead, aErr := newAead(dek.Key())
+
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(hdr)+len(nonce)+len(data)+aead.Overhead())
+	out = append(out, hdr...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, data, hdr), nil
+}
+
+// Decrypt decrypts data that was encrypted with Encrypt. getKek is called with the key id from the ciphertext
+// header to find the key encryption key
+func Decrypt(data []byte, getKek func(keyId string) (*gcm256.Key, error)) ([]byte, error) {
+	hdr, hErr := decodeHeader(data)
+
+	if hErr != nil {
+		return nil, hErr
+	}
+
+	kek, kErr := getKek(hdr.keyId)
+
+	if kErr != nil {
+		return nil, kErr
+	}
+
+	dek, dErr := kek.Decrypt(hdr.wrappedKey)
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	aead, aErr := newAead(dek)
+
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	body := data[len(hdr.raw):]
+
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, hdr.raw)
+}
+
+// KeyId returns the id of the key encryption key that was used to encrypt data
+func KeyId(data []byte) (string, error) {
+	hdr, err := decodeHeader(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hdr.keyId, nil
+}
+
+// rewrap replaces the wrapped data key in data with the data key wrapped by newKek. the header is authenticated data,
+// so the data is opened with the old header and sealed again with the new one under a fresh random nonce. reusing
+// the nonce would seal different additional data under the same key and nonce, which breaks AES-GCM
+func rewrap(data []byte, oldKek *gcm256.Key, newKeyId string, newKek *gcm256.Key) ([]byte, error) {
+	hdr, hErr := decodeHeader(data)
+
+	if hErr != nil {
+		return nil, hErr
+	}
+
+	dek, dErr := oldKek.Decrypt(hdr.wrappedKey)
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	aead, aErr := newAead(dek)
+
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	body := data[len(hdr.raw):]
+
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, pErr := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], hdr.raw)
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	wrapped, wErr := newKek.Encrypt(dek)
+
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	newHdr := encodeHeader(newKeyId, wrapped)
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(newHdr)+len(body))
+	out = append(out, newHdr...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plaintext, newHdr), nil
+}
+
+func encodeHeader(keyId string, wrappedKey []byte) []byte {
+	buf := bytes.Buffer{}
+
+	buf.WriteString(Magic)
+	buf.WriteByte(Version)
+	buf.WriteByte(byte(len(keyId)))
+	buf.WriteString(keyId)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(wrappedKey)))
+	buf.Write(wrappedKey)
+
+	return buf.Bytes()
+}
+
+func decodeHeader(data []byte) (*header, error) {
+	// magic + version + key id length
+	pos := len(Magic) + 2
+
+	if len(data) < pos || string(data[:len(Magic)]) != Magic {
+		return nil, ErrInvalidCiphertext
+	}
+
+	if data[len(Magic)] != Version {
+		return nil, errors.New("unsupported envelope version: %d", data[len(Magic)])
+	}
+
+	idLen := int(data[pos-1])
+
+	if idLen == 0 || len(data) < pos+idLen+2 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	keyId := string(data[pos : pos+idLen])
+	pos += idLen
+
+	wrappedLen := int(binary.BigEndian.Uint16(data[pos : pos+2]))
+	pos += 2
+
+	if wrappedLen == 0 || len(data) < pos+wrappedLen {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return &header{
+		keyId:      keyId,
+		wrappedKey: data[pos : pos+wrappedLen],
+		raw:        data[:pos+wrappedLen],
+	}, nil
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, bErr := aes.NewCipher(key)
+
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,74 @@
+package envelope
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+)
+
+func Test_Rewrap_FreshNonce(t *testing.T) {
+	keks := map[string]*gcm256.Key{
+		"k1": gcm256.NewKey(nil),
+		"k2": gcm256.NewKey(nil),
+	}
+
+	data := []byte("re-wrap me")
+
+	enc, eErr := Encrypt("k1", keks["k1"], data)
+
+	if eErr != nil {
+		t.Fatalf("error encrypting: %v", eErr)
+	}
+
+	// (data key, nonce) pairs of every ciphertext
+	seen := map[string]bool{}
+
+	keyNonce := func(blob []byte) string {
+		hdr, hErr := decodeHeader(blob)
+
+		if hErr != nil {
+			t.Fatalf("error decoding header: %v", hErr)
+		}
+
+		dek, dErr := keks[hdr.keyId].Decrypt(hdr.wrappedKey)
+
+		if dErr != nil {
+			t.Fatalf("error unwrapping data key: %v", dErr)
+		}
+
+		return string(dek) + string(blob[len(hdr.raw):len(hdr.raw)+12])
+	}
+
+	seen[keyNonce(enc)] = true
+
+	from := "k1"
+
+	for i := 0; i < 10; i++ {
+		to := map[string]string{"k1": "k2", "k2": "k1"}[from]
+
+		re, rErr := rewrap(enc, keks[from], to, keks[to])
+
+		if rErr != nil {
+			t.Fatalf("error re-wrapping: %v", rErr)
+		}
+
+		kn := keyNonce(re)
+
+		if seen[kn] {
+			t.Fatalf("re-wrap %d reused a (data key, nonce) pair", i)
+		}
+
+		seen[kn] = true
+
+		dec, dErr := Decrypt(re, func(keyId string) (*gcm256.Key, error) {
+			return keks[keyId], nil
+		})
+
+		if dErr != nil || !bytes.Equal(dec, data) {
+			t.Fatalf("error decrypting re-wrapped data: %v", dErr)
+		}
+
+		enc, from = re, to
+	}
+}
@@ -0,0 +1,253 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+// Keyring a local set of key encryption keys. new data is always encrypted with the primary key, any key in the
+// keyring can be used to decrypt
+//
+// Keyring implements crypto.Encrypter and crypto.Decrypter
+type Keyring struct {
+	keys    map[string]*gcm256.Key
+	primary string
+	lock    *sync.RWMutex
+}
+
+// AddKey adds a key encryption key to the keyring. the first key added becomes the primary key
+func (k *Keyring) AddKey(id string, key *gcm256.Key) error {
+	if len(id) == 0 || len(id) > MaxKeyIdLength {
+		return errors.New("key id must be between 1 and %d bytes", MaxKeyIdLength)
+	}
+
+	if key == nil || !key.IsValid() {
+		return errors.New("invalid key encryption key")
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return errors.New("key already exists: %s", id)
+	}
+
+	k.keys[id] = key
+
+	if k.primary == "" {
+		k.primary = id
+	}
+
+	return nil
+}
+
+// RemoveKey removes a key from the keyring. data encrypted with the key can no longer be decrypted, so all data
+// should be re-encrypted before a key is removed. the primary key cannot be removed
+func (k *Keyring) RemoveKey(id string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if id == k.primary {
+		return errors.New("cannot remove the primary key")
+	}
+
+	if _, ok := k.keys[id]; !ok {
+		return ErrUnknownKey.WithVars(id)
+	}
+
+	delete(k.keys, id)
+
+	return nil
+}
+
+// SetPrimary makes the key with id the key used to encrypt new data
+func (k *Keyring) SetPrimary(id string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return ErrUnknownKey.WithVars(id)
+	}
+
+	k.primary = id
+
+	return nil
+}
+
+// Primary returns the id of the primary key
+func (k *Keyring) Primary() string {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.primary
+}
+
+// KeyIds returns the ids of all keys in the keyring, sorted
+func (k *Keyring) KeyIds() []string {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	ids := make([]string, 0, len(k.keys))
+
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+// Key returns the key encryption key with id
+func (k *Keyring) Key(id string) (*gcm256.Key, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	key, ok := k.keys[id]
+
+	if !ok {
+		return nil, ErrUnknownKey.WithVars(id)
+	}
+
+	return key, nil
+}
+
+// Rotate generates a new random key and makes it the primary key. existing keys are kept so existing data can
+// still be decrypted. returns the id of the new key
+func (k *Keyring) Rotate() (string, error) {
+	key := gcm256.NewKey(nil)
+
+	if !key.IsValid() {
+		return "", errors.New("failed to generate key encryption key")
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	id := newKeyId()
+
+	for _, ok := k.keys[id]; ok; _, ok = k.keys[id] {
+		id = newKeyId()
+	}
+
+	k.keys[id] = key
+	k.primary = id
+
+	return id, nil
+}
+
+func (k *Keyring) primaryKey() (string, *gcm256.Key, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	if k.primary == "" {
+		return "", nil, errors.New("keyring has no primary key")
+	}
+
+	return k.primary, k.keys[k.primary], nil
+}
+
+func (k *Keyring) Encrypt(data []byte) ([]byte, error) {
+	id, key, err := k.primaryKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Encrypt(id, key, data)
+}
+
+// EncryptString returns the encrypted data as a base64 encoded string
+func (k *Keyring) EncryptString(data string) (string, error) {
+	enc, err := k.Encrypt([]byte(data))
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(enc), nil
+}
+
+func (k *Keyring) Decrypt(data []byte) ([]byte, error) {
+	return Decrypt(data, k.Key)
+}
+
+// DecryptString decrypts base64 encoded data returned by EncryptString
+func (k *Keyring) DecryptString(data string) (string, error) {
+	enc, eErr := base64.StdEncoding.DecodeString(data)
+
+	if eErr != nil {
+		return "", eErr
+	}
+
+	dec, err := k.Decrypt(enc)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(dec), nil
+}
+
+// ReEncrypt re-wraps the data key of data with the primary key. data that is already wrapped with the primary key
+// is returned unchanged. the data is decrypted and sealed again with the same data key under a fresh nonce, because
+// the new header is authenticated with the data
+func (k *Keyring) ReEncrypt(data []byte) ([]byte, error) {
+	keyId, idErr := KeyId(data)
+
+	if idErr != nil {
+		return nil, idErr
+	}
+
+	primaryId, primary, pErr := k.primaryKey()
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	if keyId == primaryId {
+		return data, nil
+	}
+
+	oldKey, kErr := k.Key(keyId)
+
+	if kErr != nil {
+		return nil, kErr
+	}
+
+	return rewrap(data, oldKey, primaryId, primary)
+}
+
+// ReEncryptString ReEncrypt for base64 encoded data returned by EncryptString
+func (k *Keyring) ReEncryptString(data string) (string, error) {
+	enc, eErr := base64.StdEncoding.DecodeString(data)
+
+	if eErr != nil {
+		return "", eErr
+	}
+
+	re, err := k.ReEncrypt(enc)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(re), nil
+}
+
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: map[string]*gcm256.Key{},
+		lock: &sync.RWMutex{},
+	}
+}
+
+func newKeyId() string {
+	return "k" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
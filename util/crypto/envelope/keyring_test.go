@@ -0,0 +1,145 @@
+package envelope
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+)
+
+func Test_Keyring_EncryptDecrypt(t *testing.T) {
+	k := NewKeyring()
+
+	if err := k.AddKey("k1", gcm256.NewKey(nil)); err != nil {
+		t.Fatalf("error adding key: %v", err)
+	}
+
+	data := []byte("some secret data")
+
+	enc, eErr := k.Encrypt(data)
+
+	if eErr != nil {
+		t.Fatalf("error encrypting: %v", eErr)
+	}
+
+	if id, _ := KeyId(enc); id != "k1" {
+		t.Errorf("KeyId() = %s, expected k1", id)
+	}
+
+	dec, dErr := k.Decrypt(enc)
+
+	if dErr != nil {
+		t.Fatalf("error decrypting: %v", dErr)
+	}
+
+	if !bytes.Equal(dec, data) {
+		t.Errorf("Decrypt() = %s, expected %s", dec, data)
+	}
+
+	// tampering with the header must be detected
+	tampered := bytes.Clone(enc)
+	tampered[len(Magic)+3] ^= 0x01
+
+	if _, err := k.Decrypt(tampered); err == nil {
+		t.Errorf("expected an error decrypting data with a tampered header")
+	}
+
+	s, sErr := k.EncryptString("hello")
+
+	if sErr != nil {
+		t.Fatalf("error encrypting string: %v", sErr)
+	}
+
+	if ds, err := k.DecryptString(s); err != nil || ds != "hello" {
+		t.Errorf("DecryptString() = %s, %v, expected hello", ds, err)
+	}
+}
+
+func Test_Keyring_RotateReEncrypt(t *testing.T) {
+	k := NewKeyring()
+	_ = k.AddKey("k1", gcm256.NewKey(nil))
+
+	data := []byte("rotate me")
+	enc, _ := k.Encrypt(data)
+
+	newId, rErr := k.Rotate()
+
+	if rErr != nil {
+		t.Fatalf("error rotating: %v", rErr)
+	}
+
+	if k.Primary() != newId {
+		t.Errorf("Primary() = %s, expected %s", k.Primary(), newId)
+	}
+
+	// data encrypted with the old key can still be decrypted
+	if dec, err := k.Decrypt(enc); err != nil || !bytes.Equal(dec, data) {
+		t.Errorf("error decrypting data encrypted with the previous key: %v", err)
+	}
+
+	re, reErr := k.ReEncrypt(enc)
+
+	if reErr != nil {
+		t.Fatalf("error re-encrypting: %v", reErr)
+	}
+
+	if id, _ := KeyId(re); id != newId {
+		t.Errorf("re-encrypted KeyId() = %s, expected %s", id, newId)
+	}
+
+	if err := k.RemoveKey("k1"); err != nil {
+		t.Fatalf("error removing key: %v", err)
+	}
+
+	if dec, err := k.Decrypt(re); err != nil || !bytes.Equal(dec, data) {
+		t.Errorf("error decrypting re-encrypted data: %v", err)
+	}
+
+	if _, err := k.Decrypt(enc); err == nil {
+		t.Errorf("expected an error decrypting data encrypted with a removed key")
+	}
+
+	if err := k.RemoveKey(newId); err == nil {
+		t.Errorf("expected an error removing the primary key")
+	}
+}
+
+func Test_Keyring_SealUnsealWithShares(t *testing.T) {
+	k := NewKeyring()
+	_ = k.AddKey("k1", gcm256.NewKey(nil))
+	_, _ = k.Rotate()
+
+	enc, _ := k.Encrypt([]byte("sealed"))
+
+	master := gcm256.NewKey(nil)
+
+	sealed, sErr := k.Seal(master)
+
+	if sErr != nil {
+		t.Fatalf("error sealing keyring: %v", sErr)
+	}
+
+	shares, shErr := SplitMasterKey(master, 5, 3)
+
+	if shErr != nil {
+		t.Fatalf("error splitting master key: %v", shErr)
+	}
+
+	unsealed, uErr := UnsealWithShares(sealed, shares[1:4])
+
+	if uErr != nil {
+		t.Fatalf("error unsealing keyring: %v", uErr)
+	}
+
+	if unsealed.Primary() != k.Primary() {
+		t.Errorf("unsealed Primary() = %s, expected %s", unsealed.Primary(), k.Primary())
+	}
+
+	if dec, err := unsealed.Decrypt(enc); err != nil || string(dec) != "sealed" {
+		t.Errorf("error decrypting with unsealed keyring: %v", err)
+	}
+
+	if _, err := Unseal(sealed, gcm256.NewKey(nil)); err == nil {
+		t.Errorf("expected an error unsealing with the wrong master key")
+	}
+}
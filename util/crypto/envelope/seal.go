@@ -0,0 +1,120 @@
+package envelope
+
+import (
+	"encoding/json"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+	"github.com/smoxy-io/goSDK/util/crypto/shamir"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+type sealedKeyring struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// Seal encrypts the keyring with master so it can be stored. use Unseal or UnsealWithShares to load it again
+func (k *Keyring) Seal(master *gcm256.Key) ([]byte, error) {
+	if master == nil || !master.IsValid() {
+		return nil, errors.New("invalid master key")
+	}
+
+	k.lock.RLock()
+
+	sk := sealedKeyring{
+		Primary: k.primary,
+		Keys:    make(map[string]string, len(k.keys)),
+	}
+
+	for id, key := range k.keys {
+		sk.Keys[id] = key.KeyString()
+	}
+
+	k.lock.RUnlock()
+
+	b, err := json.Marshal(sk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return master.Encrypt(b)
+}
+
+// Unseal decrypts a keyring that was sealed with master
+func Unseal(sealed []byte, master *gcm256.Key) (*Keyring, error) {
+	if master == nil || !master.IsValid() {
+		return nil, errors.New("invalid master key")
+	}
+
+	b, dErr := master.Decrypt(sealed)
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	var sk sealedKeyring
+
+	if err := json.Unmarshal(b, &sk); err != nil {
+		return nil, err
+	}
+
+	k := NewKeyring()
+
+	for id, ks := range sk.Keys {
+		key, kErr := gcm256.ParseKey(ks)
+
+		if kErr != nil {
+			return nil, errors.New("invalid key %s: %v", id, kErr)
+		}
+
+		if err := k.AddKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if sk.Primary != "" {
+		if err := k.SetPrimary(sk.Primary); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// UnsealWithShares recovers the master key from shamir shares and unseals the keyring
+func UnsealWithShares(sealed []byte, shares []string) (*Keyring, error) {
+	master, mErr := MasterKeyFromShares(shares)
+
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return Unseal(sealed, master)
+}
+
+// SplitMasterKey splits master into parts shamir shares, threshold of which are needed to recover it
+func SplitMasterKey(master *gcm256.Key, parts int, threshold int) ([]string, error) {
+	if master == nil || !master.IsValid() {
+		return nil, errors.New("invalid master key")
+	}
+
+	return shamir.CreateShamirSecret(master.KeyString(), parts, threshold)
+}
+
+// MasterKeyFromShares recovers a master key split with SplitMasterKey
+func MasterKeyFromShares(shares []string) (*gcm256.Key, error) {
+	secret, sErr := shamir.GetShamirSecret(shares)
+
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	master, mErr := gcm256.ParseKey(secret)
+
+	if mErr != nil {
+		return nil, errors.New("shares do not recover a valid master key")
+	}
+
+	return master, nil
+}
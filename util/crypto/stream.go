@@ -0,0 +1,29 @@
+package crypto
+
+import "io"
+
+// StreamEncrypter encrypts everything written to the returned writer and writes the ciphertext to w. the writer
+// MUST be closed to write the final chunk
+type StreamEncrypter interface {
+	EncryptWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// StreamDecrypter decrypts everything read from r. the returned reader returns an error if the ciphertext was
+// tampered with or truncated. DecryptReaderAt gives random access to the plaintext of size bytes of ciphertext, and
+// returns the size of the plaintext
+type StreamDecrypter interface {
+	DecryptReader(r io.Reader) (io.Reader, error)
+	DecryptReaderAt(r io.ReaderAt, size int64) (io.ReaderAt, int64, error)
+}
+
+func EncryptWriter(key StreamEncrypter, w io.Writer) (io.WriteCloser, error) {
+	return key.EncryptWriter(w)
+}
+
+func DecryptReader(key StreamDecrypter, r io.Reader) (io.Reader, error) {
+	return key.DecryptReader(r)
+}
+
+func DecryptReaderAt(key StreamDecrypter, r io.ReaderAt, size int64) (io.ReaderAt, int64, error) {
+	return key.DecryptReaderAt(r, size)
+}
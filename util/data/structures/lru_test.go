@@ -0,0 +1,81 @@
+package structures
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_LRU(t *testing.T) {
+	cache := NewLRU[string, int](3)
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Set("c", 3, 0)
+
+	// a becomes the most recently used entry, so b is evicted next
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v. expected 1, true", v, ok)
+	}
+
+	cache.Set("d", 4, 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("b should have been evicted")
+	}
+
+	if cache.Len() != 3 {
+		t.Errorf("Len() = %d, expected 3", cache.Len())
+	}
+
+	cache.Set("a", 10, 0)
+
+	if v, _ := cache.Get("a"); v != 10 {
+		t.Errorf("Get(a) = %d, expected 10", v)
+	}
+
+	cache.Delete("a")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("a should have been deleted")
+	}
+
+	cache.Purge()
+
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after Purge, expected 0", cache.Len())
+	}
+}
+
+func Test_LRU_Expiry(t *testing.T) {
+	cache := NewLRU[string, string](0)
+
+	cache.Set("short", "x", time.Millisecond)
+	cache.Set("long", "y", time.Hour)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("short should have expired")
+	}
+
+	if _, ok := cache.Get("long"); !ok {
+		t.Errorf("long should not have expired")
+	}
+}
+
+func Test_LRU_DeleteFunc(t *testing.T) {
+	cache := NewLRU[string, int](10)
+
+	cache.Set("user:1", 1, 0)
+	cache.Set("user:2", 2, 0)
+	cache.Set("post:1", 3, 0)
+
+	removed := cache.DeleteFunc(func(key string, _ int) bool {
+		return strings.HasPrefix(key, "user:")
+	})
+
+	if removed != 2 || cache.Len() != 1 {
+		t.Errorf("removed %d entries, %d left. expected 2 and 1", removed, cache.Len())
+	}
+}
@@ -3,14 +3,10 @@ package dgraph
 import (
 	"context"
 	"github.com/dgraph-io/dgo/v230"
-	"github.com/dgraph-io/dgo/v230/protos/api"
 	"github.com/gin-gonic/gin"
 	"github.com/shurcooL/graphql"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"math"
 	"os"
+	"sync"
 )
 
 const (
@@ -24,6 +20,7 @@ const (
 	gqlClientCtxKey = clientCtxKey + "-graphql"
 	txnCtxKey       = "db-txn"
 	txnDepthKey     = "db-txn-depth"
+	txnHooksCtxKey  = "db-txn-hooks"
 
 	DefaultHost        = "localhost"
 	DefaultGrpcPort    = "9080"
@@ -34,23 +31,22 @@ const (
 	SchemaVersion = "v0.0.1"
 )
 
+// NewClient creates a client with its own connections, configured from the environment (see ConfigFromEnv). prefer
+// GetClient, which shares the connections of the DefaultPool
 func NewClient() (*dgo.Dgraph, error) {
-	//TODO: setup TLS
-	connOpts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithInitialWindowSize(math.MaxInt32),
-		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
-	}
-
-	conn, err := grpc.Dial(GetEndpoint(), connOpts...)
+	cfg, cErr := ConfigFromEnv()
 
-	if err != nil {
-		return nil, err
+	if cErr != nil {
+		return nil, cErr
 	}
 
-	//TODO: login to namespace
+	return NewPool(cfg).Client(context.Background())
+}
 
-	return dgo.NewDgraphClient(api.NewDgraphClient(conn)), nil
+// Ping checks that the database is reachable using the DefaultPool, so it can be called periodically by health checks
+// without opening new connections
+func Ping(ctx context.Context) error {
+	return DefaultPool().Ping(ctx)
 }
 
 func GetClient(ctx context.Context) (*dgo.Dgraph, error, context.Context) {
@@ -59,8 +55,8 @@ func GetClient(ctx context.Context) (*dgo.Dgraph, error, context.Context) {
 
 	if !ok {
 		// ctx[clientCtxKey] is unset or not a Dgraph client
-		// create it
-		dbc, err := NewClient()
+		// get the shared client
+		dbc, err := DefaultPool().Client(ctx)
 
 		if err != nil {
 			return nil, err, ctx
@@ -179,7 +175,9 @@ func StartTxn(ctx context.Context, readOnly ...bool) (context.Context, error) {
 		txn = dgraph.NewTxn()
 	}
 
-	return context.WithValue(nCtx, txnCtxKey, txn), nil
+	nCtx = context.WithValue(nCtx, txnCtxKey, txn)
+
+	return context.WithValue(nCtx, txnHooksCtxKey, &txnHooks{}), nil
 }
 
 func Commit(ctx context.Context) error {
@@ -195,7 +193,15 @@ func Commit(ctx context.Context) error {
 		return nil
 	}
 
-	return txn.Commit(ctx)
+	if err := txn.Commit(ctx); err != nil {
+		return err
+	}
+
+	if hooks, ok := ctx.Value(txnHooksCtxKey).(*txnHooks); ok {
+		hooks.run()
+	}
+
+	return nil
 }
 
 func Rollback(ctx context.Context) error {
@@ -208,9 +214,58 @@ func Rollback(ctx context.Context) error {
 		return nil
 	}
 
+	if hooks, ok := ctx.Value(txnHooksCtxKey).(*txnHooks); ok {
+		// the changes of the transaction are discarded
+		hooks.clear()
+	}
+
 	return txn.Discard(ctx)
 }
 
+// txnHooks the functions to run after a transaction is committed
+type txnHooks struct {
+	lock sync.Mutex
+	fns  []func()
+}
+
+func (h *txnHooks) add(fn func()) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.fns = append(h.fns, fn)
+}
+
+func (h *txnHooks) run() {
+	h.lock.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.lock.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (h *txnHooks) clear() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.fns = nil
+}
+
+// AfterCommit runs fn after the transaction of ctx (see StartTxn) is committed. fn does not run when the transaction
+// is rolled back. without a transaction the changes are committed immediately, so fn runs immediately
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(txnHooksCtxKey).(*txnHooks)
+
+	if !ok || GetTxn(ctx) == nil {
+		fn()
+		return
+	}
+
+	hooks.add(fn)
+}
+
 func GetHost() string {
 	host := os.Getenv(ENV_HOST)
 
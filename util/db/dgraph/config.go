@@ -0,0 +1,207 @@
+package dgraph
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ENV_ENDPOINTS       = "DGRAPH_DB_ENDPOINTS"
+	ENV_TLS             = "DGRAPH_DB_TLS"
+	ENV_TLS_CA_FILE     = "DGRAPH_DB_TLS_CA_FILE"
+	ENV_TLS_CERT_FILE   = "DGRAPH_DB_TLS_CERT_FILE"
+	ENV_TLS_KEY_FILE    = "DGRAPH_DB_TLS_KEY_FILE"
+	ENV_TLS_SERVER_NAME = "DGRAPH_DB_TLS_SERVER_NAME"
+	ENV_USER            = "DGRAPH_DB_USER"
+	ENV_PASSWORD        = "DGRAPH_DB_PASSWORD"
+	ENV_NAMESPACE       = "DGRAPH_DB_NAMESPACE"
+	ENV_DIAL_TIMEOUT    = "DGRAPH_DB_DIAL_TIMEOUT"
+	ENV_RETRIES         = "DGRAPH_DB_RETRIES"
+)
+
+const (
+	DefaultDialTimeout = 10 * time.Second
+	DefaultRetries     = 2
+
+	// MaxRetries grpc does not make more than 5 attempts
+	MaxRetries = 4
+)
+
+// ClientConfig configures the connections to the Dgraph alphas
+type ClientConfig struct {
+	// Endpoints the grpc endpoints (host:port) of the alphas. requests are spread over the endpoints round-robin
+	Endpoints []string
+	// ConnsPerEndpoint the number of connections to each endpoint. defaults to 1
+	ConnsPerEndpoint int
+
+	// TLS enables TLS. when TLSConfig is nil, the TLS config is created from the TLS files
+	TLS       bool
+	TLSConfig *tls.Config
+	// TLSCAFile the CA that signed the alphas' certificates. defaults to the system roots
+	TLSCAFile string
+	// TLSCertFile and TLSKeyFile the client certificate for mutual TLS
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+
+	// User and Password log into Namespace with ACL. the access token is refreshed automatically when it expires
+	User      string
+	Password  string
+	Namespace uint64
+
+	// DialTimeout the time allowed to establish a connection. defaults to DefaultDialTimeout
+	DialTimeout time.Duration
+	// Retries the number of times version checks and logins that fail because an alpha is unavailable are retried.
+	// defaults to DefaultRetries, < 0 disables retries. transactions are retried by WithTxn
+	Retries int
+}
+
+// tlsConfig returns the TLS config for the connections. returns nil when TLS is disabled
+func (c *ClientConfig) tlsConfig() (*tls.Config, error) {
+	if c.TLSConfig != nil {
+		return c.TLSConfig, nil
+	}
+
+	if !c.TLS {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.TLSServerName,
+	}
+
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.TLSCAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func (c *ClientConfig) endpoints() []string {
+	if len(c.Endpoints) == 0 {
+		return []string{GetEndpoint()}
+	}
+
+	return c.Endpoints
+}
+
+func (c *ClientConfig) connsPerEndpoint() int {
+	if c.ConnsPerEndpoint < 1 {
+		return 1
+	}
+
+	return c.ConnsPerEndpoint
+}
+
+func (c *ClientConfig) dialTimeout() time.Duration {
+	if c.DialTimeout <= 0 {
+		return DefaultDialTimeout
+	}
+
+	return c.DialTimeout
+}
+
+func (c *ClientConfig) retries() int {
+	switch {
+	case c.Retries < 0:
+		return 0
+	case c.Retries == 0:
+		return DefaultRetries
+	case c.Retries > MaxRetries:
+		return MaxRetries
+	}
+
+	return c.Retries
+}
+
+// ConfigFromEnv creates a ClientConfig from the DGRAPH_DB_* environment variables. DGRAPH_DB_ENDPOINTS is a comma
+// separated list of endpoints. without it, the endpoint is built from DGRAPH_DB_HOST and DGRAPH_DB_GRPC_PORT
+func ConfigFromEnv() (*ClientConfig, error) {
+	cfg := &ClientConfig{
+		TLSCAFile:     os.Getenv(ENV_TLS_CA_FILE),
+		TLSCertFile:   os.Getenv(ENV_TLS_CERT_FILE),
+		TLSKeyFile:    os.Getenv(ENV_TLS_KEY_FILE),
+		TLSServerName: os.Getenv(ENV_TLS_SERVER_NAME),
+		User:          os.Getenv(ENV_USER),
+		Password:      os.Getenv(ENV_PASSWORD),
+	}
+
+	for _, e := range strings.Split(os.Getenv(ENV_ENDPOINTS), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			cfg.Endpoints = append(cfg.Endpoints, e)
+		}
+	}
+
+	if v := os.Getenv(ENV_TLS); v != "" {
+		enabled, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ENV_TLS, err)
+		}
+
+		cfg.TLS = enabled
+	}
+
+	// setting any of the TLS files enables TLS
+	cfg.TLS = cfg.TLS || cfg.TLSCAFile != "" || cfg.TLSCertFile != ""
+
+	if v := os.Getenv(ENV_NAMESPACE); v != "" {
+		ns, err := strconv.ParseUint(v, 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ENV_NAMESPACE, err)
+		}
+
+		cfg.Namespace = ns
+	}
+
+	if v := os.Getenv(ENV_DIAL_TIMEOUT); v != "" {
+		d, err := time.ParseDuration(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ENV_DIAL_TIMEOUT, err)
+		}
+
+		cfg.DialTimeout = d
+	}
+
+	if v := os.Getenv(ENV_RETRIES); v != "" {
+		r, err := strconv.Atoi(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ENV_RETRIES, err)
+		}
+
+		cfg.Retries = r
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,150 @@
+package dgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	"google.golang.org/grpc"
+)
+
+func Test_ConfigFromEnv(t *testing.T) {
+	t.Setenv(ENV_ENDPOINTS, "alpha1:9080, alpha2:9080,")
+	t.Setenv(ENV_NAMESPACE, "3")
+	t.Setenv(ENV_DIAL_TIMEOUT, "2s")
+	t.Setenv(ENV_RETRIES, "10")
+	t.Setenv(ENV_TLS_SERVER_NAME, "dgraph")
+	t.Setenv(ENV_TLS, "true")
+
+	cfg, err := ConfigFromEnv()
+
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() returned an error: %v", err)
+	}
+
+	if !slices.Equal(cfg.endpoints(), []string{"alpha1:9080", "alpha2:9080"}) {
+		t.Errorf("endpoints = %v", cfg.endpoints())
+	}
+
+	if cfg.Namespace != 3 || cfg.dialTimeout() != 2*time.Second || cfg.retries() != MaxRetries {
+		t.Errorf("namespace = %d, dial timeout = %s, retries = %d", cfg.Namespace, cfg.dialTimeout(), cfg.retries())
+	}
+
+	tlsCfg, tErr := cfg.tlsConfig()
+
+	if tErr != nil || tlsCfg == nil || tlsCfg.ServerName != "dgraph" {
+		t.Errorf("tlsConfig() = %v, %v", tlsCfg, tErr)
+	}
+
+	t.Setenv(ENV_NAMESPACE, "not a number")
+
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Errorf("ConfigFromEnv() with an invalid namespace should return an error")
+	}
+
+	t.Setenv(ENV_NAMESPACE, "")
+	t.Setenv(ENV_TLS_CA_FILE, "/does/not/exist")
+
+	cfg, _ = ConfigFromEnv()
+
+	if _, err := cfg.tlsConfig(); err == nil {
+		t.Errorf("tlsConfig() with a missing CA file should return an error")
+	}
+}
+
+func Test_Pool(t *testing.T) {
+	p := NewPool(&ClientConfig{Endpoints: []string{"alpha1:9080", "alpha2:9080"}, ConnsPerEndpoint: 2})
+
+	// connections are established lazily, so no server is needed
+	c1, err := p.Client(context.Background())
+
+	if err != nil {
+		t.Fatalf("Client() returned an error: %v", err)
+	}
+
+	c2, _ := p.Client(context.Background())
+
+	if c1 != c2 {
+		t.Errorf("Client() should return the shared client")
+	}
+
+	if len(p.conns) != 4 {
+		t.Errorf("pool has %d connections, expected 4", len(p.conns))
+	}
+
+	_ = p.Close()
+
+	if c3, _ := p.Client(context.Background()); c3 == c1 {
+		t.Errorf("Client() after Close() should reconnect")
+	}
+
+	_ = p.Close()
+
+	if _, err := NewPool(&ClientConfig{Namespace: 1}).Client(context.Background()); err == nil {
+		t.Errorf("Client() for a namespace without a user should return an error")
+	}
+}
+
+func Test_retryServiceConfig(t *testing.T) {
+	var cfg struct {
+		MethodConfig []struct {
+			Name []struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+			} `json:"name"`
+		} `json:"methodConfig"`
+	}
+
+	if err := json.Unmarshal([]byte(fmt.Sprintf(retryServiceConfig, 3)), &cfg); err != nil {
+		t.Fatalf("invalid service config: %v", err)
+	}
+
+	methods := []string{}
+
+	for _, mc := range cfg.MethodConfig {
+		for _, n := range mc.Name {
+			methods = append(methods, n.Service+"/"+n.Method)
+		}
+	}
+
+	// queries, mutations and commits are not idempotent and must not be retried by grpc
+	if !slices.Equal(methods, []string{"api.Dgraph/CheckVersion", "api.Dgraph/Login"}) {
+		t.Errorf("retried methods = %v, expected only CheckVersion and Login", methods)
+	}
+}
+
+type countingClient struct {
+	api.DgraphClient
+	calls int
+}
+
+func (c *countingClient) CheckVersion(ctx context.Context, in *api.Check, opts ...grpc.CallOption) (*api.Version, error) {
+	c.calls++
+
+	return &api.Version{}, nil
+}
+
+func Test_roundRobinClient(t *testing.T) {
+	clients := []*countingClient{{}, {}, {}}
+
+	rr := &roundRobinClient{next: &atomic.Uint64{}}
+
+	for _, c := range clients {
+		rr.clients = append(rr.clients, c)
+	}
+
+	for i := 0; i < 9; i++ {
+		_, _ = rr.CheckVersion(context.Background(), &api.Check{})
+	}
+
+	for i, c := range clients {
+		if c.calls != 3 {
+			t.Errorf("client %d received %d calls, expected 3", i, c.calls)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"github.com/smoxy-io/goSDK/util/cli"
+	cliCobra "github.com/smoxy-io/goSDK/util/cli/cobra"
+	"github.com/spf13/cobra"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// NewMigrateCmd creates the migrate command with the status, plan, up, down and to sub commands. the migrations must be
+// registered with RegisterMigrator before the command is executed
+func NewMigrateCmd() *cobra.Command {
+	dryRun := false
+	lease := DefaultLockLease
+
+	migrateCtx := func(cmd *cobra.Command) context.Context {
+		ctx := WithOutput(WithLockLease(cmd.Context(), lease), cmd.OutOrStdout())
+
+		if dryRun {
+			ctx = WithDryRun(ctx)
+		}
+
+		return ctx
+	}
+
+	cmd := cliCobra.NewCmd("migrate", "migrate the dgraph schema", "up --dry-run")
+
+	cli.WithFlagVar(&dryRun, "dry-run", false, "run the migrations without committing them")(cmd.PersistentFlags())
+	cli.WithFlagVar(&lease, "lock-lease", DefaultLockLease, "how long the migration lock is held before it expires")(cmd.PersistentFlags())
+
+	cmd.AddCommand(
+		cliCobra.NewCmd("status", "show the schema version and the state of the migrations", "",
+			cliCobra.WithArgs(cobra.NoArgs),
+			cliCobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+				s, err := Status(cmd.Context())
+
+				if err != nil {
+					return err
+				}
+
+				return printStatus(cmd, s)
+			}),
+		),
+		cliCobra.NewCmd("plan", "show the migrations that take the schema to version (default latest)", "v1.2.0",
+			cliCobra.WithArgs(cobra.MaximumNArgs(1)),
+			cliCobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+				version := LatestVersion
+
+				if len(args) > 0 {
+					version = args[0]
+				}
+
+				p, err := PlanTo(cmd.Context(), version)
+
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprint(cmd.OutOrStdout(), p.String())
+
+				return err
+			}),
+		),
+		cliCobra.NewCmd("up", "apply all migrations starting from the current schema version", "--dry-run",
+			cliCobra.WithArgs(cobra.NoArgs),
+			cliCobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+				return Up(migrateCtx(cmd))
+			}),
+		),
+		cliCobra.NewCmd("down", "revert the last applied migrations (default 1)", "2",
+			cliCobra.WithArgs(cobra.MaximumNArgs(1)),
+			cliCobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+				steps := 1
+
+				if len(args) > 0 {
+					s, err := strconv.Atoi(args[0])
+
+					if err != nil {
+						return fmt.Errorf("invalid number of steps: %s", args[0])
+					}
+
+					steps = s
+				}
+
+				return Down(migrateCtx(cmd), steps)
+			}),
+		),
+		cliCobra.NewCmd("to", "apply or revert migrations until the schema is at version (or latest)", "v1.2.0",
+			cliCobra.WithArgs(cobra.ExactArgs(1)),
+			cliCobra.WithRunE(func(cmd *cobra.Command, args []string) error {
+				return MigrateTo(migrateCtx(cmd), args[0])
+			}),
+		),
+	)
+
+	return cmd
+}
+
+func printStatus(cmd *cobra.Command, s *SchemaStatus) error {
+	out := cmd.OutOrStdout()
+
+	_, _ = fmt.Fprintf(out, "schema version: %s\n", s.Version)
+
+	if s.Locked {
+		_, _ = fmt.Fprintf(out, "locked by: %s (expires %s)\n", s.LockOwner, s.LockExpiry.Format(time.RFC3339))
+	}
+
+	_, _ = fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "MIGRATION\tSTATUS\tLAST RUN\tNOTE")
+
+	for _, m := range s.Migrations {
+		lastRun := ""
+
+		if t, err := strconv.ParseInt(m.LastRun, 10, 64); err == nil {
+			lastRun = time.Unix(t, 0).UTC().Format(time.RFC3339)
+		}
+
+		note := ""
+
+		switch {
+		case !m.Registered:
+			note = "not registered"
+		case m.Changed:
+			note = "changed after it was applied"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", genMigratorKey(m.FromVersion, m.ToVersion), m.Status, lastRun, note)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,376 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/smoxy-io/goSDK/util/semver"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidVersion     = errors.New("invalid migration version")
+	ErrDuplicateMigration = errors.New("duplicate migration")
+	ErrMigrationCycle     = errors.New("migration cycle")
+	ErrMigrationGap       = errors.New("migration gap")
+	ErrNoMigrationPath    = errors.New("no migration path")
+)
+
+// edge a registered migration
+type edge struct {
+	from semver.Version
+	to   semver.Version
+	// fromVersion and toVersion the versions as they were registered
+	fromVersion string
+	toVersion   string
+	newMigrator NewMigrator
+}
+
+func (e *edge) key() string {
+	return genMigratorKey(versionKey(e.from), versionKey(e.to))
+}
+
+// Graph the registered migrations as a directed graph over semantic versions. migrations are planned as the shortest
+// path between two versions
+type Graph struct {
+	// edges the migrations by key
+	edges map[string]*edge
+	// out the migrations from a version, sorted by to version
+	out map[string][]*edge
+	// in the migrations to a version, sorted by from version
+	in       map[string][]*edge
+	versions map[string]semver.Version
+}
+
+// Register adds the migration fromVersion -> toVersion. the versions must be semantic versions, a migration can only
+// be registered once and migrations cannot form a cycle
+func (g *Graph) Register(fromVersion string, toVersion string, newMigrator NewMigrator) error {
+	from, fErr := semver.Parse(fromVersion)
+
+	if fErr != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVersion, fErr)
+	}
+
+	to, tErr := semver.Parse(toVersion)
+
+	if tErr != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVersion, tErr)
+	}
+
+	e := &edge{
+		from:        from,
+		to:          to,
+		fromVersion: fromVersion,
+		toVersion:   toVersion,
+		newMigrator: newMigrator,
+	}
+
+	if _, ok := g.edges[e.key()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateMigration, e.key())
+	}
+
+	if from.Equal(to) || g.reachable(versionKey(to), versionKey(from)) {
+		return fmt.Errorf("%w: %s", ErrMigrationCycle, e.key())
+	}
+
+	fk, tk := versionKey(from), versionKey(to)
+
+	g.edges[e.key()] = e
+	g.versions[fk] = from
+	g.versions[tk] = to
+
+	g.out[fk] = append(g.out[fk], e)
+	g.in[tk] = append(g.in[tk], e)
+
+	sort.Slice(g.out[fk], func(i, j int) bool {
+		return g.out[fk][i].to.Less(g.out[fk][j].to)
+	})
+
+	sort.Slice(g.in[tk], func(i, j int) bool {
+		return g.in[tk][i].from.Less(g.in[tk][j].from)
+	})
+
+	return nil
+}
+
+// Validate checks that every version can be reached from the lowest version. a version that cannot be reached is a
+// gap in the migrations, e.g. v1.0.0 -> v1.1.0 and v1.2.0 -> v1.3.0
+func (g *Graph) Validate() error {
+	versions := g.sortedVersions()
+
+	if len(versions) == 0 {
+		return nil
+	}
+
+	root := versionKey(versions[0])
+	seen := map[string]bool{root: true}
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		for _, e := range g.out[v] {
+			if tk := versionKey(e.to); !seen[tk] {
+				seen[tk] = true
+				queue = append(queue, tk)
+			}
+		}
+	}
+
+	unreachable := []string{}
+
+	for _, v := range versions {
+		if !seen[versionKey(v)] {
+			unreachable = append(unreachable, versionKey(v))
+		}
+	}
+
+	if len(unreachable) > 0 {
+		return fmt.Errorf("%w: %s cannot be reached from %s", ErrMigrationGap, strings.Join(unreachable, ", "), root)
+	}
+
+	return nil
+}
+
+// Latest returns the highest version. empty when no migrations are registered
+func (g *Graph) Latest() string {
+	versions := g.sortedVersions()
+
+	if len(versions) == 0 {
+		return ""
+	}
+
+	return versionKey(versions[len(versions)-1])
+}
+
+// Plan plans the migrations from fromVersion to toVersion: the shortest path of migrations when toVersion is higher
+// than fromVersion and the shortest path of reverted migrations when it is lower
+func (g *Graph) Plan(fromVersion string, toVersion string) (*Plan, error) {
+	return g.plan(fromVersion, toVersion, nil)
+}
+
+// plan is like Plan, only the migrations accepted by canRevert are reverted. nil accepts all migrations
+func (g *Graph) plan(fromVersion string, toVersion string, canRevert func(e *edge) bool) (*Plan, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	from, fErr := semver.Parse(fromVersion)
+
+	if fErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVersion, fErr)
+	}
+
+	to, tErr := semver.Parse(toVersion)
+
+	if tErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVersion, tErr)
+	}
+
+	p := &Plan{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Steps:       []*Step{},
+	}
+
+	if from.Equal(to) {
+		return p, nil
+	}
+
+	down := to.Less(from)
+
+	var path []*edge
+
+	if down {
+		path = g.path(versionKey(from), versionKey(to), func(v string) []*edge {
+			edges := []*edge{}
+
+			// prefer reverting to the highest version
+			for i := len(g.in[v]) - 1; i >= 0; i-- {
+				if canRevert == nil || canRevert(g.in[v][i]) {
+					edges = append(edges, g.in[v][i])
+				}
+			}
+
+			return edges
+		}, func(e *edge) string {
+			return versionKey(e.from)
+		})
+	} else {
+		path = g.path(versionKey(from), versionKey(to), func(v string) []*edge {
+			return g.out[v]
+		}, func(e *edge) string {
+			return versionKey(e.to)
+		})
+	}
+
+	if path == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoMigrationPath, genMigratorKey(fromVersion, toVersion))
+	}
+
+	for _, e := range path {
+		p.Steps = append(p.Steps, &Step{
+			FromVersion: e.fromVersion,
+			ToVersion:   e.toVersion,
+			Revert:      down,
+			newMigrator: e.newMigrator,
+		})
+	}
+
+	return p, nil
+}
+
+// path returns the shortest path from -> to (breadth first). next returns the edges leaving a version in the order
+// they are tried, so paths of the same length are chosen deterministically. nil when there is no path
+func (g *Graph) path(from string, to string, next func(v string) []*edge, target func(e *edge) string) []*edge {
+	prev := map[string]*edge{}
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+
+	for len(queue) > 0 && !seen[to] {
+		v := queue[0]
+		queue = queue[1:]
+
+		for _, e := range next(v) {
+			t := target(e)
+
+			if seen[t] {
+				continue
+			}
+
+			seen[t] = true
+			prev[t] = e
+			queue = append(queue, t)
+		}
+	}
+
+	if !seen[to] {
+		return nil
+	}
+
+	path := []*edge{}
+
+	for v := to; v != from; {
+		e := prev[v]
+		path = append([]*edge{e}, path...)
+
+		if target(e) == versionKey(e.to) {
+			v = versionKey(e.from)
+		} else {
+			v = versionKey(e.to)
+		}
+	}
+
+	return path
+}
+
+// reachable returns true when to can be reached from from
+func (g *Graph) reachable(from string, to string) bool {
+	return g.path(from, to, func(v string) []*edge {
+		return g.out[v]
+	}, func(e *edge) string {
+		return versionKey(e.to)
+	}) != nil
+}
+
+func (g *Graph) factory(fromVersion string, toVersion string) NewMigrator {
+	if e, ok := g.edges[canonicalKey(fromVersion, toVersion)]; ok {
+		return e.newMigrator
+	}
+
+	return nil
+}
+
+// sortedEdges returns the migrations sorted by from and to version
+func (g *Graph) sortedEdges() []*edge {
+	edges := []*edge{}
+
+	for _, v := range g.sortedVersions() {
+		edges = append(edges, g.out[versionKey(v)]...)
+	}
+
+	return edges
+}
+
+func (g *Graph) sortedVersions() []semver.Version {
+	versions := make([]semver.Version, 0, len(g.versions))
+
+	for _, v := range g.versions {
+		versions = append(versions, v)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Less(versions[j])
+	})
+
+	return versions
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		edges:    map[string]*edge{},
+		out:      map[string][]*edge{},
+		in:       map[string][]*edge{},
+		versions: map[string]semver.Version{},
+	}
+}
+
+// Step a migration of a Plan
+type Step struct {
+	FromVersion string
+	ToVersion   string
+	// Revert the migration is reverted
+	Revert      bool
+	newMigrator NewMigrator
+}
+
+// Plan the migrations that take the schema from one version to another, in order
+type Plan struct {
+	FromVersion string
+	ToVersion   string
+	Steps       []*Step
+}
+
+func (p *Plan) String() string {
+	sb := &strings.Builder{}
+
+	sb.WriteString("migration plan " + genMigratorKey(p.FromVersion, p.ToVersion) + ":\n")
+
+	if len(p.Steps) == 0 {
+		sb.WriteString("  nothing to do\n")
+	}
+
+	for i, s := range p.Steps {
+		action := "apply"
+
+		if s.Revert {
+			action = "revert"
+		}
+
+		sb.WriteString(fmt.Sprintf("  %d. %s %s\n", i+1, action, genMigratorKey(s.FromVersion, s.ToVersion)))
+	}
+
+	return sb.String()
+}
+
+// versionKey the version without build metadata, it does not change the precedence of a version
+func versionKey(v semver.Version) string {
+	v.Build = ""
+
+	return v.String()
+}
+
+// canonicalVersion the version key of version. versions that are not semantic versions are used as is
+func canonicalVersion(version string) string {
+	if v, err := semver.Parse(version); err == nil {
+		return versionKey(v)
+	}
+
+	return version
+}
+
+// canonicalKey the key of the migration fromVersion -> toVersion
+func canonicalKey(fromVersion string, toVersion string) string {
+	return genMigratorKey(canonicalVersion(fromVersion), canonicalVersion(toVersion))
+}
@@ -0,0 +1,219 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	proto "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/models"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultLockLease how long the migration lock is held before it expires. the lease is renewed every time the
+	// migration updates the schema metadata. the lease must be longer than the longest migration: a migration that
+	// loses the lock while it runs fails with ErrLockLost instead of committing
+	DefaultLockLease = 10 * time.Minute
+)
+
+var (
+	ErrLocked   = errors.New("the schema is locked by another migration")
+	ErrLockLost = errors.New("the migration lock expired and was taken by another migration")
+)
+
+type migratorCtxKey string
+
+const (
+	lockCtxKey      migratorCtxKey = "migrator-lock"
+	lockLeaseCtxKey migratorCtxKey = "migrator-lock-lease"
+	dryRunCtxKey    migratorCtxKey = "migrator-dry-run"
+	outputCtxKey    migratorCtxKey = "migrator-output"
+)
+
+// lock the migration lock held by this process
+type lock struct {
+	owner string
+	lease time.Duration
+}
+
+// extend sets the lock on the schema metadata and renews its lease
+func (l *lock) extend(md *proto.SchemaMetaData) {
+	owner := l.owner
+	expiry := strconv.FormatInt(time.Now().Add(l.lease).UTC().Unix(), 10)
+	isMigrating := true
+
+	md.LockOwner = &owner
+	md.LockExpiry = &expiry
+	md.IsMigrating = &isMigrating
+}
+
+// check returns ErrLockLost when the lock on the schema metadata read in the transaction of ctx is no longer held by l.
+// a lock taken by another migration after the transaction started makes the transaction fail to commit, so checking
+// at the start of every attempt of the transaction is enough
+func (l *lock) check(ctx context.Context) error {
+	md, mdErr := models.GetSchemaMetaData(ctx)
+
+	if mdErr != nil {
+		return mdErr
+	}
+
+	return l.heldOn(md)
+}
+
+func (l *lock) heldOn(md *proto.SchemaMetaData) error {
+	if md.GetLockOwner() != l.owner {
+		return fmt.Errorf("%w (owner: %s)", ErrLockLost, md.GetLockOwner())
+	}
+
+	return nil
+}
+
+// WithLockLease sets how long the migration lock is held before it expires (see DefaultLockLease)
+func WithLockLease(ctx context.Context, lease time.Duration) context.Context {
+	return context.WithValue(ctx, lockLeaseCtxKey, lease)
+}
+
+// WithDryRun runs the migrations in transactions that are never committed. migrations should skip changes that are not
+// transactional (e.g. schema alterations) when IsDryRun is true
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunCtxKey, true)
+}
+
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunCtxKey).(bool)
+
+	return dryRun
+}
+
+// WithOutput writes the plan and the progress of the migrations to w. nothing is written by default
+func WithOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputCtxKey, w)
+}
+
+func output(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputCtxKey).(io.Writer); ok && w != nil {
+		return w
+	}
+
+	return io.Discard
+}
+
+// Lock acquires the migration lock stored in the schema metadata. the lock is a lease: when the process holding it dies
+// the lock expires instead of blocking migrations forever. the returned context holds the lock and must be used for
+// the migrations. unlock releases the lock
+//
+// when ctx already holds the lock, ctx is returned and unlock does nothing
+func Lock(ctx context.Context) (lockCtx context.Context, unlock func() error, err error) {
+	if getLock(ctx) != nil {
+		return ctx, func() error { return nil }, nil
+	}
+
+	l := &lock{
+		owner: newLockOwner(),
+		lease: DefaultLockLease,
+	}
+
+	if lease, ok := ctx.Value(lockLeaseCtxKey).(time.Duration); ok && lease > 0 {
+		l.lease = lease
+	}
+
+	lErr := db.WithTxn(ctx, func(tCtx context.Context) error {
+		md, mdErr := models.GetSchemaMetaData(tCtx)
+
+		if mdErr != nil {
+			return mdErr
+		}
+
+		if isLocked(md, l.owner, time.Now()) {
+			return fmt.Errorf("%w (owner: %s)", ErrLocked, md.GetLockOwner())
+		}
+
+		l.extend(md)
+
+		return models.Set(tCtx, md)
+	})
+
+	if lErr != nil {
+		return nil, nil, lErr
+	}
+
+	lockCtx = context.WithValue(ctx, lockCtxKey, l)
+
+	return lockCtx, func() error { return unlockSchema(ctx, l) }, nil
+}
+
+func unlockSchema(ctx context.Context, l *lock) error {
+	return db.WithTxn(ctx, func(tCtx context.Context) error {
+		md, mdErr := models.GetSchemaMetaData(tCtx)
+
+		if mdErr != nil {
+			return mdErr
+		}
+
+		if md.GetLockOwner() != l.owner {
+			// the lease expired and the lock was taken by another migration
+			return nil
+		}
+
+		owner := ""
+		expiry := ""
+		isMigrating := false
+
+		md.LockOwner = &owner
+		md.LockExpiry = &expiry
+		md.IsMigrating = &isMigrating
+
+		return models.Set(tCtx, md)
+	})
+}
+
+func getLock(ctx context.Context) *lock {
+	l, _ := ctx.Value(lockCtxKey).(*lock)
+
+	return l
+}
+
+// isLocked returns true when the schema is locked by another owner and the lease has not expired
+func isLocked(md *proto.SchemaMetaData, owner string, now time.Time) bool {
+	if md.GetLockOwner() == "" {
+		// migrations without a lease only set isMigrating
+		return md.GetIsMigrating()
+	}
+
+	if md.GetLockOwner() == owner {
+		return false
+	}
+
+	expiry, err := strconv.ParseInt(md.GetLockExpiry(), 10, 64)
+
+	if err != nil {
+		// an invalid expiry never expires, the lock has to be released by its owner
+		return true
+	}
+
+	return now.UTC().Unix() < expiry
+}
+
+func newLockOwner() string {
+	host, err := os.Hostname()
+
+	if err != nil {
+		host = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString())
+}
+
+// lockOwner the owner of the lock held by ctx. empty when ctx does not hold the lock
+func lockOwner(ctx context.Context) string {
+	if l := getLock(ctx); l != nil {
+		return l.owner
+	}
+
+	return ""
+}
@@ -1,9 +1,8 @@
-package dgraph
+package migrator
 
 import (
 	"context"
 	"fmt"
-	"github.com/smoxy-io/goSDK/util/db/dgraph/migrator"
 	"github.com/smoxy-io/goSDK/util/errors"
 )
 
@@ -16,12 +15,12 @@ func MigrateSchema(ctx context.Context, fromVersion string, toVersion string) er
 		return errors.ErrInvalid.WithVars("toVersion")
 	}
 
-	if toVersion == "latest" {
+	if toVersion == LatestVersion {
 		// perform all database migrations starting from `fromVersion`
-		return migrator.MigrateFrom(ctx, fromVersion)
+		return MigrateFrom(ctx, fromVersion)
 	}
 
-	newMigratorFn := migrator.GetMigratorFactory(fromVersion, toVersion)
+	newMigratorFn := GetMigratorFactory(fromVersion, toVersion)
 
 	if newMigratorFn == nil {
 		return fmt.Errorf("no migration defined for %s -> %s", fromVersion, toVersion)
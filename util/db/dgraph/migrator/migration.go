@@ -2,6 +2,8 @@ package migrator
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/schollz/progressbar/v3"
@@ -14,10 +16,19 @@ import (
 
 type AppyFn func(ctx context.Context) error
 
+var (
+	ErrNotReversible    = errors.New("migration is not reversible")
+	ErrChecksumMismatch = errors.New("migration was changed after it was applied")
+
+	// errDryRun rolls back the transaction of a dry run
+	errDryRun = errors.New("dry run")
+)
+
 // Migration base migrator struct
 type Migration struct {
 	fromVersion string
 	toVersion   string
+	checksum    string
 	ctx         context.Context
 	PBar        *progressbar.ProgressBar
 	schemaMeta  *proto.SchemaMetaData
@@ -29,181 +40,364 @@ func (m *Migration) Apply() error {
 	return errors.New("not implemented")
 }
 
+// Revert wrapper structs implement Revert with MigrateDown to make the migration reversible
+func (m *Migration) Revert() error {
+	return fmt.Errorf("%w: %s", ErrNotReversible, genMigratorKey(m.fromVersion, m.toVersion))
+}
+
 // Migrate wrapper structs call this function to reduce boilerplate code that's needed for a migration
 func (m *Migration) Migrate(applyFn AppyFn) error {
-	// check if migration can be performed
-	if !m.CanMigrate() {
-		return fmt.Errorf("cannot perform %s –> %s migration", m.fromVersion, m.toVersion)
+	return m.run(false, applyFn)
+}
+
+// MigrateDown reverts the migration: revertFn undoes the changes of the migration and the schema version is set back to
+// the migration's from version. wrapper structs call this function in Revert
+func (m *Migration) MigrateDown(revertFn AppyFn) error {
+	return m.run(true, revertFn)
+}
+
+func (m *Migration) run(down bool, fn AppyFn) error {
+	key := genMigratorKey(m.fromVersion, m.toVersion)
+
+	if down {
+		key = genMigratorKey(m.toVersion, m.fromVersion)
+	}
+
+	if IsDryRun(m.ctx) {
+		return m.dryRun(down, key, fn)
 	}
 
-	// start the migration transaction
-	nCtx, cErr := db.StartTxn(m.ctx)
+	// the lock is held for the whole migration. when the migration is part of a series of migrations, the lock is
+	// already held by the series
+	lockCtx, unlock, lErr := Lock(m.ctx)
 
-	if cErr != nil {
-		return cErr
+	if lErr != nil {
+		return lErr
 	}
 
-	defer db.Rollback(nCtx) // ensure the setup transaction is rolled back. safe to call after successful commit
+	defer func() {
+		_ = unlock()
+	}()
 
-	if m.migration == nil {
-		// create a new migration
-		m.migration = &proto.Migration{
-			FromVersion: m.fromVersion,
-			ToVersion:   m.toVersion,
-			LastStatus:  proto.MigrationStatus_SUCCESS,
-			Results:     make([]*proto.MigrationResult, 0),
-		}
+	ctx := m.ctx
+	m.ctx = lockCtx
 
-		if err := models.Set(nCtx, m.migration); err != nil {
+	defer func() {
+		m.ctx = ctx
+	}()
+
+	l := getLock(m.ctx)
+
+	// check if migration can be performed
+	if !m.canRun(down) {
+		return fmt.Errorf("cannot perform %s migration", key)
+	}
+
+	if down {
+		if err := m.verifyChecksum(); err != nil {
 			return err
 		}
+	}
+
+	// the transactions are retried when they are aborted. every attempt starts from the state before the transaction
+	setupState := m.saveState()
 
-		m.schemaMeta.Migrations = append(m.schemaMeta.Migrations, m.migration)
+	// start the migration
+	sErr := db.WithTxn(m.ctx, func(nCtx context.Context) error {
+		m.restoreState(setupState)
 
-		if err := models.Set(nCtx, m.schemaMeta); err != nil {
+		if err := l.check(nCtx); err != nil {
 			return err
 		}
-	}
 
-	// create a new migration result
-	m.result = &proto.MigrationResult{
-		Migration: m.migration,
-		StartTime: strconv.FormatInt(time.Now().UTC().Unix(), 10),
-		EndTime:   nil,
-		Status:    proto.MigrationStatus_IN_PROGRESS,
-	}
+		if m.migration == nil {
+			// create a new migration
+			m.migration = &proto.Migration{
+				FromVersion: m.fromVersion,
+				ToVersion:   m.toVersion,
+				LastStatus:  proto.MigrationStatus_SUCCESS,
+				Results:     make([]*proto.MigrationResult, 0),
+			}
 
-	if err := models.Set(nCtx, m.result); err != nil {
-		return err
-	}
+			if err := models.Set(nCtx, m.migration); err != nil {
+				return err
+			}
 
-	m.migration.LastStatus = m.result.Status
-	m.migration.Results = append(m.migration.Results, m.result)
+			m.schemaMeta.Migrations = append(m.schemaMeta.Migrations, m.migration)
 
-	if err := models.Set(nCtx, m.migration); err != nil {
-		return err
-	}
+			if err := models.Set(nCtx, m.schemaMeta); err != nil {
+				return err
+			}
+		}
 
-	isMigrating := true
-	m.schemaMeta.IsMigrating = &isMigrating
-	lastMigStatus := m.result.Status
-	m.schemaMeta.LastMigrationStatus = &lastMigStatus
+		if !down {
+			// the checksum of the applied migration
+			m.migration.Checksum = m.Checksum()
+		}
 
-	if err := models.Set(nCtx, m.schemaMeta); err != nil {
-		return err
-	}
+		// create a new migration result
+		m.result = &proto.MigrationResult{
+			Migration: m.migration,
+			StartTime: strconv.FormatInt(time.Now().UTC().Unix(), 10),
+			EndTime:   nil,
+			Status:    proto.MigrationStatus_IN_PROGRESS,
+			Revert:    down,
+		}
 
-	if err := db.Commit(nCtx); err != nil {
-		return err
-	}
+		if err := models.Set(nCtx, m.result); err != nil {
+			return err
+		}
 
-	// run the migration
-	mCtx, mcErr := db.StartTxn(m.ctx) // build the migration context off the original context
+		m.migration.LastStatus = m.result.Status
+		m.migration.Results = append(m.migration.Results, m.result)
 
-	if mcErr != nil {
-		return mcErr
-	}
+		if err := models.Set(nCtx, m.migration); err != nil {
+			return err
+		}
+
+		l.extend(m.schemaMeta)
+		lastMigStatus := m.result.Status
+		m.schemaMeta.LastMigrationStatus = &lastMigStatus
+
+		return models.Set(nCtx, m.schemaMeta)
+	})
 
-	defer db.Rollback(mCtx) // ensure the migration transaction is rolled back. safe to call after successful commit
+	if sErr != nil {
+		return sErr
+	}
 
-	aErr := applyFn(mCtx)
+	// a failed migration leaves the schema at its from version, a failed revert leaves the migration applied
+	failedVersion := m.fromVersion
+	failedStatus := proto.MigrationStatus_FAILED
+	doneVersion := m.toVersion
+	doneStatus := proto.MigrationStatus_SUCCESS
+
+	if down {
+		failedVersion = m.toVersion
+		failedStatus = proto.MigrationStatus_SUCCESS
+		doneVersion = m.fromVersion
+		doneStatus = proto.MigrationStatus_ROLLED_BACK
+	}
 
 	updateMetaOnErr := func() {
 		m.result.Status = proto.MigrationStatus_FAILED
 		endTime := strconv.FormatInt(time.Now().UTC().Unix(), 10)
 		m.result.EndTime = &endTime
 
-		m.migration.LastStatus = m.result.Status
+		m.migration.LastStatus = failedStatus
 
-		*m.schemaMeta.IsMigrating = false
-		*m.schemaMeta.LastMigrationStatus = m.result.Status
+		lastMigStatus := m.result.Status
+		m.schemaMeta.LastMigrationStatus = &lastMigStatus
 		m.schemaMeta.LastMigrationTime = &endTime
-		m.schemaMeta.Version = m.fromVersion
+		m.schemaMeta.Version = failedVersion
 
 		// attempt to update the metadata to reflect migration failure
 		// - won't work if failure is due to db being offline
-		eCtx, ecErr := db.StartTxn(m.ctx)
+		// - the metadata is not changed when the lock was taken by another migration
+		_ = db.WithTxn(m.ctx, func(eCtx context.Context) error {
+			if err := l.check(eCtx); err != nil {
+				return err
+			}
 
-		if ecErr != nil {
-			return
+			if err := models.Set(eCtx, m.result); err != nil {
+				return err
+			}
+
+			if err := models.Set(eCtx, m.migration); err != nil {
+				return err
+			}
+
+			l.extend(m.schemaMeta)
+
+			return models.Set(eCtx, m.schemaMeta)
+		})
+	}
+
+	migrateState := m.saveState()
+
+	// run the migration. the migration context is built off the original context
+	mErr := db.WithTxn(m.ctx, func(mCtx context.Context) error {
+		m.restoreState(migrateState)
+
+		if err := l.check(mCtx); err != nil {
+			return err
 		}
 
-		defer db.Rollback(eCtx)
+		if err := fn(mCtx); err != nil {
+			return err
+		}
+
+		// add the update to the schema metadata version and the migration results to the migration txn
+		m.result.Status = doneStatus
+		endTime := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+		m.result.EndTime = &endTime
 
-		if err := models.Set(eCtx, m.result); err != nil {
-			return
+		m.migration.LastStatus = m.result.Status
+
+		m.schemaMeta.Version = doneVersion
+		lastMigStatus := m.result.Status
+		m.schemaMeta.LastMigrationStatus = &lastMigStatus
+		m.schemaMeta.LastMigrationTime = m.result.EndTime
+		l.extend(m.schemaMeta)
+
+		if err := models.Set(mCtx, m.result); err != nil {
+			return err
 		}
 
-		if err := models.Set(eCtx, m.migration); err != nil {
-			return
+		if err := models.Set(mCtx, m.migration); err != nil {
+			return err
 		}
 
-		if err := models.Set(eCtx, m.schemaMeta); err != nil {
-			return
+		if err := models.Set(mCtx, m.schemaMeta); err != nil {
+			return err
 		}
 
-		_ = db.Commit(eCtx)
-	}
+		// check that schema metadata is updated
+		cm, cmErr := models.GetSchemaMetaData(mCtx)
 
-	if aErr != nil {
-		updateMetaOnErr()
-		return aErr
-	}
+		if cmErr != nil {
+			return cmErr
+		}
 
-	// add the update to the schema metadata version and the migration results to the migration txn
-	m.result.Status = proto.MigrationStatus_SUCCESS
-	endTime := strconv.FormatInt(time.Now().UTC().Unix(), 10)
-	m.result.EndTime = &endTime
+		if cm.Version != doneVersion {
+			return fmt.Errorf("schema version (%s) not updated to %s", cm.Version, doneVersion)
+		}
 
-	m.migration.LastStatus = m.result.Status
+		if cm.LastMigrationTime == nil || *cm.LastMigrationTime != *m.schemaMeta.LastMigrationTime {
+			return fmt.Errorf("schema last migration time not updated")
+		}
 
-	m.schemaMeta.Version = m.toVersion
-	*m.schemaMeta.IsMigrating = false
-	*m.schemaMeta.LastMigrationStatus = m.result.Status
-	m.schemaMeta.LastMigrationTime = m.result.EndTime
+		// the changes are applied to the database when the transaction is committed
+		return nil
+	})
 
-	if err := models.Set(mCtx, m.result); err != nil {
+	if mErr != nil {
 		updateMetaOnErr()
-		return err
+		return mErr
 	}
 
-	if err := models.Set(mCtx, m.migration); err != nil {
-		updateMetaOnErr()
-		return err
+	return nil
+}
+
+// dryRun runs fn in a transaction that is rolled back. the schema metadata is not changed and the lock is not taken
+func (m *Migration) dryRun(down bool, key string, fn AppyFn) error {
+	if !m.canRun(down) {
+		return fmt.Errorf("cannot perform %s migration", key)
 	}
 
-	if err := models.Set(mCtx, m.schemaMeta); err != nil {
-		updateMetaOnErr()
-		return err
+	if down {
+		if err := m.verifyChecksum(); err != nil {
+			return err
+		}
 	}
 
-	// check that schema metadata is updated
-	cm, cmErr := models.GetSchemaMetaData(mCtx)
+	err := db.WithTxn(m.ctx, func(dCtx context.Context) error {
+		if err := fn(dCtx); err != nil {
+			return err
+		}
 
-	if cmErr != nil {
-		updateMetaOnErr()
-		return cmErr
+		return errDryRun
+	})
+
+	if errors.Is(err, errDryRun) {
+		return nil
 	}
 
-	if cm.Version != m.toVersion {
-		updateMetaOnErr()
-		return fmt.Errorf("schema version (%s) not updated to %s", m.toVersion)
+	return err
+}
+
+func (m *Migration) canRun(down bool) bool {
+	if down {
+		return m.CanRevert()
 	}
 
-	if cm.LastMigrationTime == nil || *cm.LastMigrationTime != *m.schemaMeta.LastMigrationTime {
-		updateMetaOnErr()
-		return fmt.Errorf("schema last migration time not updated")
+	return m.CanMigrate()
+}
+
+// verifyChecksum checks that the applied migration was not changed. migrations without a checksum are not checked
+func (m *Migration) verifyChecksum() error {
+	if m.migration == nil || m.migration.Checksum == "" || m.Checksum() == "" {
+		return nil
 	}
 
-	// apply the changes to the database
-	if err := db.Commit(mCtx); err != nil {
-		updateMetaOnErr()
-		return err
+	if m.migration.Checksum != m.Checksum() {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, genMigratorKey(m.fromVersion, m.toVersion))
 	}
 
 	return nil
 }
 
+// migrationState the parts of the migration's models that are changed by its transactions
+type migrationState struct {
+	migration           *proto.Migration
+	result              *proto.MigrationResult
+	migrations          int
+	results             int
+	lastStatus          proto.MigrationStatus
+	resultStatus        proto.MigrationStatus
+	resultEndTime       *string
+	version             string
+	isMigrating         *bool
+	lastMigrationStatus *proto.MigrationStatus
+	lastMigrationTime   *string
+}
+
+func (m *Migration) saveState() *migrationState {
+	s := &migrationState{
+		migration:           m.migration,
+		result:              m.result,
+		migrations:          len(m.schemaMeta.Migrations),
+		version:             m.schemaMeta.Version,
+		isMigrating:         copyPtr(m.schemaMeta.IsMigrating),
+		lastMigrationStatus: copyPtr(m.schemaMeta.LastMigrationStatus),
+		lastMigrationTime:   copyPtr(m.schemaMeta.LastMigrationTime),
+	}
+
+	if m.migration != nil {
+		s.results = len(m.migration.Results)
+		s.lastStatus = m.migration.LastStatus
+	}
+
+	if m.result != nil {
+		s.resultStatus = m.result.Status
+		s.resultEndTime = copyPtr(m.result.EndTime)
+	}
+
+	return s
+}
+
+// restoreState restores the state saved before a transaction so that an aborted transaction can be retried
+func (m *Migration) restoreState(s *migrationState) {
+	m.migration = s.migration
+	m.result = s.result
+
+	m.schemaMeta.Migrations = m.schemaMeta.Migrations[:s.migrations]
+	m.schemaMeta.Version = s.version
+	m.schemaMeta.IsMigrating = copyPtr(s.isMigrating)
+	m.schemaMeta.LastMigrationStatus = copyPtr(s.lastMigrationStatus)
+	m.schemaMeta.LastMigrationTime = copyPtr(s.lastMigrationTime)
+
+	if m.migration != nil {
+		m.migration.Results = m.migration.Results[:s.results]
+		m.migration.LastStatus = s.lastStatus
+	}
+
+	if m.result != nil {
+		m.result.Status = s.resultStatus
+		m.result.EndTime = copyPtr(s.resultEndTime)
+	}
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+
+	v := *p
+
+	return &v
+}
+
 func (m *Migration) CanMigrate() bool {
 	md, err := models.GetSchemaMetaData(m.ctx)
 
@@ -221,30 +415,82 @@ func (m *Migration) CanMigrate() bool {
 		return false
 	}
 
-	if md.IsMigrating != nil && *md.IsMigrating {
+	if isLocked(md, lockOwner(m.ctx), time.Now()) {
 		return false
 	}
 
-	if md.LastMigrationStatus != nil && md.LastMigrationStatus.Name() == string(models.MigrationStatusInProgress) {
+	if mi := findMigration(md, m.fromVersion, m.toVersion); mi != nil {
+		// this migrator has been run before
+		m.migration = mi
+
+		// a migration that is still in progress without holding the lock was interrupted and can be run again
+		return mi.LastStatus.Name() != string(models.MigrationStatusSuccess)
+	}
+
+	return true
+}
+
+// CanRevert returns true when the migration is the last migration applied to the schema
+func (m *Migration) CanRevert() bool {
+	md, err := models.GetSchemaMetaData(m.ctx)
+
+	if err != nil {
 		return false
 	}
 
-	for _, mi := range md.Migrations {
-		if mi.FromVersion == m.fromVersion || mi.ToVersion == m.toVersion {
-			// this migrator has been run before
-			m.migration = mi
+	if md == nil {
+		return false
+	}
 
-			status := mi.LastStatus.Name()
+	m.schemaMeta = md
 
-			if status == string(models.MigrationStatusSuccess) || status == string(models.MigrationStatusInProgress) {
-				return false
-			}
+	if md.Version != m.toVersion {
+		return false
+	}
 
-			return true
+	if isLocked(md, lockOwner(m.ctx), time.Now()) {
+		return false
+	}
+
+	if mi := findMigration(md, m.fromVersion, m.toVersion); mi != nil {
+		m.migration = mi
+
+		return mi.LastStatus == proto.MigrationStatus_SUCCESS
+	}
+
+	return false
+}
+
+// findMigration returns the past runs of the migration from fromVersion to toVersion. nil when it has never been run.
+// other migrations from or to the same versions (branches of the migration graph) are different migrations
+func findMigration(md *proto.SchemaMetaData, fromVersion string, toVersion string) *proto.Migration {
+	for _, mi := range md.Migrations {
+		if mi.FromVersion == fromVersion && mi.ToVersion == toVersion {
+			return mi
 		}
 	}
 
-	return true
+	return nil
+}
+
+// Checksum the checksum of the data the migration was created with (see WithChecksum). empty when the migration has
+// no checksum
+func (m *Migration) Checksum() string {
+	return m.checksum
+}
+
+// WithChecksum sets the checksum of the migration to the sha256 of data, e.g. the schema or the queries the migration
+// applies. the checksum is stored when the migration is applied so edited migrations are detected (see Verify)
+func (m *Migration) WithChecksum(data ...[]byte) *Migration {
+	h := sha256.New()
+
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	m.checksum = hex.EncodeToString(h.Sum(nil))
+
+	return m
 }
 
 func (m *Migration) FromVersion() string {
@@ -3,13 +3,27 @@ package migrator
 import (
 	"context"
 	"fmt"
+	proto "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/models"
+	"github.com/smoxy-io/goSDK/util/errors"
+	"github.com/smoxy-io/goSDK/util/semver"
+	"sort"
+	"strings"
+)
+
+const (
+	// LatestVersion migrates to the highest registered version
+	LatestVersion = "latest"
 )
 
 type Migrator interface {
 	Apply() error
+	Revert() error
 	CanMigrate() bool
+	CanRevert() bool
 	FromVersion() string
 	ToVersion() string
+	Checksum() string
 }
 
 type MigrationOption func() Migrator
@@ -17,61 +31,275 @@ type MigrationOption func() Migrator
 type NewMigrator func(ctx context.Context) Migrator
 
 var (
-	migrators = map[string]NewMigrator{}
+	migrations = NewGraph()
 )
 
+// RegisterMigrator registers the migration fromVersion -> toVersion. it panics when the versions are not semantic
+// versions, when the migration is already registered or when it creates a cycle (see Graph.Register)
 func RegisterMigrator(fromVersion string, toVersion string, migrator NewMigrator) {
-	migrators[genMigratorKey(fromVersion, toVersion)] = migrator
+	if err := migrations.Register(fromVersion, toVersion, migrator); err != nil {
+		panic(err)
+	}
 }
 
-func GetMigratorFactory(fromVersion string, toVersion string) NewMigrator {
-	if m, ok := migrators[genMigratorKey(fromVersion, toVersion)]; ok {
-		return m
-	}
+// Validate checks the registered migrations for gaps (see Graph.Validate)
+func Validate() error {
+	return migrations.Validate()
+}
 
-	return nil
+func GetMigratorFactory(fromVersion string, toVersion string) NewMigrator {
+	return migrations.factory(fromVersion, toVersion)
 }
 
+// GetMigratorByFromVersion returns the first migration of the shortest path from fromVersion to the latest version.
+// nil when there is none
 func GetMigratorByFromVersion(ctx context.Context, fromVersion string) Migrator {
-	for _, migrator := range migrators {
-		m := migrator(ctx)
+	p, err := migrations.Plan(fromVersion, migrations.Latest())
 
-		if m.FromVersion() == fromVersion {
-			return m
-		}
+	if err != nil || len(p.Steps) == 0 || p.Steps[0].Revert {
+		return nil
 	}
 
-	return nil
+	return p.Steps[0].newMigrator(ctx)
 }
 
+// MigrateFrom applies the migrations on the shortest path from fromVersion to the latest version
 func MigrateFrom(ctx context.Context, fromVersion string) error {
 	if fromVersion == "" {
 		fromVersion = "v0.0.0"
 	}
 
-	for {
-		migrator := GetMigratorByFromVersion(ctx, fromVersion)
+	latest := migrations.Latest()
+
+	if latest == "" {
+		// no migrations are registered
+		return nil
+	}
+
+	if c, err := semver.Compare(fromVersion, latest); err != nil || c >= 0 {
+		// nothing to migrate
+		return err
+	}
+
+	p, err := migrations.Plan(fromVersion, latest)
+
+	if err != nil {
+		return err
+	}
+
+	return runPlan(ctx, p)
+}
+
+// Up applies all migrations starting from the current schema version
+func Up(ctx context.Context) error {
+	md, err := models.GetSchemaMetaData(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return MigrateFrom(ctx, md.Version)
+}
+
+// Down reverts the last steps migrations applied to the schema
+func Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return errors.ErrInvalid.WithVars("steps")
+	}
+
+	md, err := models.GetSchemaMetaData(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	p := &Plan{
+		FromVersion: md.Version,
+		ToVersion:   md.Version,
+		Steps:       []*Step{},
+	}
+
+	isApplied := appliedMigrations(md)
+
+	for i := 0; i < steps; i++ {
+		var applied *edge
+
+		for _, e := range migrations.in[canonicalVersion(p.ToVersion)] {
+			if isApplied(e) {
+				applied = e
+			}
+		}
+
+		if applied == nil {
+			return fmt.Errorf("no applied migration to revert %s", p.ToVersion)
+		}
+
+		p.Steps = append(p.Steps, &Step{
+			FromVersion: applied.fromVersion,
+			ToVersion:   applied.toVersion,
+			Revert:      true,
+			newMigrator: applied.newMigrator,
+		})
+
+		p.ToVersion = applied.fromVersion
+	}
+
+	return runPlan(ctx, p)
+}
+
+// PlanTo plans the migrations from the current schema version to version (or LatestVersion). only applied migrations
+// are reverted
+func PlanTo(ctx context.Context, version string) (*Plan, error) {
+	if version == "" {
+		return nil, errors.ErrInvalid.WithVars("version")
+	}
+
+	md, err := models.GetSchemaMetaData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if version == LatestVersion {
+		version = migrations.Latest()
+	}
+
+	return migrations.plan(md.Version, version, appliedMigrations(md))
+}
+
+// MigrateTo applies or reverts migrations until the schema is at version (or LatestVersion). the migrations are
+// planned and the plan is printed before any of them runs
+func MigrateTo(ctx context.Context, version string) error {
+	p, err := PlanTo(ctx, version)
+
+	if err != nil {
+		return err
+	}
+
+	return runPlan(ctx, p)
+}
 
-		if migrator == nil {
+func runPlan(ctx context.Context, p *Plan) error {
+	out := output(ctx)
+
+	_, _ = fmt.Fprint(out, p.String())
+
+	if len(p.Steps) == 0 {
+		return nil
+	}
+
+	if !IsDryRun(ctx) {
+		lockCtx, unlock, lErr := Lock(ctx)
+
+		if lErr != nil {
+			return lErr
+		}
+
+		defer func() {
+			_ = unlock()
+		}()
+
+		ctx = lockCtx
+	}
+
+	if err := Verify(ctx); err != nil {
+		return err
+	}
+
+	for _, step := range p.Steps {
+		// the migrators are created with the context holding the lock
+		migrator := step.newMigrator(ctx)
+		key := genMigratorKey(migrator.FromVersion(), migrator.ToVersion())
+
+		if step.Revert {
+			if !migrator.CanRevert() {
+				return fmt.Errorf("revert of migration %s is not allowed", key)
+			}
+
+			_, _ = fmt.Fprintf(out, "Reverting migration %s%s\n", key, dryRunSuffix(ctx))
+
+			if err := migrator.Revert(); err != nil {
+				return err
+			}
+		} else {
+			if !migrator.CanMigrate() {
+				return fmt.Errorf("migration %s is not allowed", key)
+			}
+
+			_, _ = fmt.Fprintf(out, "Performing migration %s%s\n", key, dryRunSuffix(ctx))
+
+			if err := migrator.Apply(); err != nil {
+				return err
+			}
+		}
+
+		if IsDryRun(ctx) {
+			// the next migration cannot run, the schema version did not change
 			break
 		}
+	}
+
+	return nil
+}
+
+// appliedMigrations returns a function that checks if a migration is applied to the schema
+func appliedMigrations(md *proto.SchemaMetaData) func(e *edge) bool {
+	applied := map[string]bool{}
+
+	for _, mi := range md.Migrations {
+		if mi.LastStatus == proto.MigrationStatus_SUCCESS {
+			applied[canonicalKey(mi.FromVersion, mi.ToVersion)] = true
+		}
+	}
+
+	return func(e *edge) bool {
+		return applied[e.key()]
+	}
+}
 
-		if !migrator.CanMigrate() {
-			return fmt.Errorf("migration %s is not allowed", genMigratorKey(migrator.FromVersion(), migrator.ToVersion()))
+// Verify checks that the applied migrations were not changed since they were applied (see Migration.WithChecksum)
+func Verify(ctx context.Context) error {
+	md, err := models.GetSchemaMetaData(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	changed := []string{}
+
+	for _, mi := range md.Migrations {
+		if mi.LastStatus != proto.MigrationStatus_SUCCESS || mi.Checksum == "" {
+			continue
 		}
 
-		fmt.Printf("Performing migration %s\n", genMigratorKey(migrator.FromVersion(), migrator.ToVersion()))
+		newMigratorFn := GetMigratorFactory(mi.FromVersion, mi.ToVersion)
+
+		if newMigratorFn == nil {
+			continue
+		}
 
-		if err := migrator.Apply(); err != nil {
-			return err
+		if checksum := newMigratorFn(ctx).Checksum(); checksum != "" && checksum != mi.Checksum {
+			changed = append(changed, genMigratorKey(mi.FromVersion, mi.ToVersion))
 		}
+	}
+
+	if len(changed) > 0 {
+		sort.Strings(changed)
 
-		fromVersion = migrator.ToVersion()
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(changed, ", "))
 	}
 
 	return nil
 }
 
+func dryRunSuffix(ctx context.Context) string {
+	if IsDryRun(ctx) {
+		return " (dry run)"
+	}
+
+	return ""
+}
+
 func genMigratorKey(fromVersion string, toVersion string) string {
 	return fromVersion + " -> " + toVersion
 }
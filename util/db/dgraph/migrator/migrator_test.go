@@ -0,0 +1,307 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	proto "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMigrator(from string, to string) NewMigrator {
+	return func(ctx context.Context) Migrator {
+		return NewMigration(from, to, ctx)
+	}
+}
+
+func testGraph(t *testing.T, edges ...[2]string) *Graph {
+	g := NewGraph()
+
+	for _, e := range edges {
+		if err := g.Register(e[0], e[1], testMigrator(e[0], e[1])); err != nil {
+			t.Fatalf("Register(%s, %s) returned an error: %v", e[0], e[1], err)
+		}
+	}
+
+	return g
+}
+
+func planKeys(p *Plan) []string {
+	keys := []string{}
+
+	for _, s := range p.Steps {
+		action := "apply "
+
+		if s.Revert {
+			action = "revert "
+		}
+
+		keys = append(keys, action+genMigratorKey(s.FromVersion, s.ToVersion))
+	}
+
+	return keys
+}
+
+func TestGraph_Register(t *testing.T) {
+	tests := []struct {
+		name   string
+		edges  [][2]string
+		from   string
+		to     string
+		expect error
+	}{
+		{name: "valid", edges: [][2]string{{"v1.0.0", "v1.1.0"}}, from: "v1.1.0", to: "v2.0.0"},
+		{name: "invalid from", from: "latest", to: "v1.0.0", expect: ErrInvalidVersion},
+		{name: "invalid to", from: "v1.0.0", to: "v1", expect: ErrInvalidVersion},
+		{name: "duplicate", edges: [][2]string{{"v1.0.0", "v1.1.0"}}, from: "1.0.0", to: "v1.1.0+build.2", expect: ErrDuplicateMigration},
+		{name: "same version", from: "v1.0.0", to: "v1.0.0", expect: ErrMigrationCycle},
+		{name: "cycle", edges: [][2]string{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v2.0.0"}}, from: "v2.0.0", to: "v1.0.0", expect: ErrMigrationCycle},
+		{name: "branch", edges: [][2]string{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v2.0.0"}}, from: "v1.0.0", to: "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGraph(t, tt.edges...)
+
+			err := g.Register(tt.from, tt.to, testMigrator(tt.from, tt.to))
+
+			if tt.expect == nil && err != nil {
+				t.Errorf("Register() returned an error: %v", err)
+			}
+
+			if tt.expect != nil && !errors.Is(err, tt.expect) {
+				t.Errorf("Register() = %v, expected %v", err, tt.expect)
+			}
+		})
+	}
+}
+
+func TestGraph_Validate(t *testing.T) {
+	if err := testGraph(t).Validate(); err != nil {
+		t.Errorf("Validate() of an empty graph returned an error: %v", err)
+	}
+
+	if err := testGraph(t, [2]string{"v1.0.0", "v1.1.0"}, [2]string{"v1.0.0", "v2.0.0"}, [2]string{"v1.1.0", "v2.0.0"}).Validate(); err != nil {
+		t.Errorf("Validate() returned an error: %v", err)
+	}
+
+	g := testGraph(t, [2]string{"v1.0.0", "v1.1.0"}, [2]string{"v1.2.0", "v1.3.0"})
+
+	if err := g.Validate(); !errors.Is(err, ErrMigrationGap) {
+		t.Errorf("Validate() = %v, expected ErrMigrationGap", err)
+	}
+
+	if _, err := g.Plan("v1.0.0", "v1.1.0"); !errors.Is(err, ErrMigrationGap) {
+		t.Errorf("Plan() = %v, expected ErrMigrationGap", err)
+	}
+}
+
+func TestGraph_Plan(t *testing.T) {
+	g := testGraph(t,
+		[2]string{"v0.0.0", "v1.0.0"},
+		[2]string{"v1.0.0", "v1.1.0"},
+		[2]string{"v1.1.0", "v1.2.0"},
+		[2]string{"v1.2.0", "v2.0.0"},
+		// a shortcut for new installs
+		[2]string{"v1.0.0", "v1.2.0"},
+		[2]string{"v1.1.0", "v1.1.1"},
+	)
+
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		expect []string
+		err    error
+	}{
+		{name: "nothing to do", from: "v1.1.0", to: "1.1.0", expect: []string{}},
+		{name: "shortest path", from: "v0.0.0", to: "v2.0.0", expect: []string{"apply v0.0.0 -> v1.0.0", "apply v1.0.0 -> v1.2.0", "apply v1.2.0 -> v2.0.0"}},
+		{name: "branch", from: "v1.0.0", to: "v1.1.1", expect: []string{"apply v1.0.0 -> v1.1.0", "apply v1.1.0 -> v1.1.1"}},
+		{name: "down", from: "v2.0.0", to: "v1.0.0", expect: []string{"revert v1.2.0 -> v2.0.0", "revert v1.0.0 -> v1.2.0"}},
+		{name: "no path", from: "v1.1.1", to: "v2.0.0", err: ErrNoMigrationPath},
+		{name: "invalid", from: "v1.1.1", to: "latest", err: ErrInvalidVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := g.Plan(tt.from, tt.to)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Plan() = %v, expected %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Plan() returned an error: %v", err)
+			}
+
+			if keys := planKeys(p); strings.Join(keys, ", ") != strings.Join(tt.expect, ", ") {
+				t.Errorf("Plan() = %v, expected %v", keys, tt.expect)
+			}
+		})
+	}
+
+	// only applied migrations are reverted
+	p, err := g.plan("v2.0.0", "v1.0.0", func(e *edge) bool {
+		return e.key() != "v1.0.0 -> v1.2.0"
+	})
+
+	if err != nil {
+		t.Fatalf("plan() returned an error: %v", err)
+	}
+
+	expect := "migration plan v2.0.0 -> v1.0.0:\n" +
+		"  1. revert v1.2.0 -> v2.0.0\n" +
+		"  2. revert v1.1.0 -> v1.2.0\n" +
+		"  3. revert v1.0.0 -> v1.1.0\n"
+
+	if p.String() != expect {
+		t.Errorf("plan() =\n%s\nexpected:\n%s", p, expect)
+	}
+
+	if latest := g.Latest(); latest != "v2.0.0" {
+		t.Errorf("Latest() = %s, expected v2.0.0", latest)
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	now := time.Now()
+	future := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+	past := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+	yes := true
+
+	tests := []struct {
+		name   string
+		md     *proto.SchemaMetaData
+		owner  string
+		expect bool
+	}{
+		{name: "unlocked", md: &proto.SchemaMetaData{}, expect: false},
+		{name: "migrating without lease", md: &proto.SchemaMetaData{IsMigrating: &yes}, expect: true},
+		{name: "other owner", md: &proto.SchemaMetaData{LockOwner: strPtr("a"), LockExpiry: &future}, owner: "b", expect: true},
+		{name: "own lock", md: &proto.SchemaMetaData{LockOwner: strPtr("a"), LockExpiry: &future}, owner: "a", expect: false},
+		{name: "expired", md: &proto.SchemaMetaData{LockOwner: strPtr("a"), LockExpiry: &past, IsMigrating: &yes}, owner: "b", expect: false},
+		{name: "invalid expiry", md: &proto.SchemaMetaData{LockOwner: strPtr("a"), LockExpiry: strPtr("")}, owner: "b", expect: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLocked(tt.md, tt.owner, now); got != tt.expect {
+				t.Errorf("isLocked() = %v, expected %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestLock_HeldOn(t *testing.T) {
+	l := &lock{owner: "a", lease: time.Minute}
+
+	if err := l.heldOn(&proto.SchemaMetaData{LockOwner: strPtr("a")}); err != nil {
+		t.Errorf("heldOn() of the own lock returned an error: %v", err)
+	}
+
+	for _, md := range []*proto.SchemaMetaData{{}, {LockOwner: strPtr("b")}} {
+		if err := l.heldOn(md); !errors.Is(err, ErrLockLost) {
+			t.Errorf("heldOn() of a lock owned by %q = %v, expected ErrLockLost", md.GetLockOwner(), err)
+		}
+	}
+}
+
+func TestFindMigration(t *testing.T) {
+	md := &proto.SchemaMetaData{Migrations: []*proto.Migration{
+		{Id: "0x1", FromVersion: "v1.0.0", ToVersion: "v1.1.0"},
+		{Id: "0x2", FromVersion: "v1.1.0", ToVersion: "v2.0.0"},
+	}}
+
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		expect string
+	}{
+		{name: "applied", from: "v1.0.0", to: "v1.1.0", expect: "0x1"},
+		{name: "branch from the same version", from: "v1.0.0", to: "v1.2.0", expect: ""},
+		{name: "branch to the same version", from: "v1.2.0", to: "v2.0.0", expect: ""},
+		{name: "never run", from: "v2.0.0", to: "v3.0.0", expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findMigration(md, tt.from, tt.to).GetId(); got != tt.expect {
+				t.Errorf("findMigration(%s, %s) = %s, expected %s", tt.from, tt.to, got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMigration_Checksum(t *testing.T) {
+	m := NewMigration("v1.0.0", "v1.1.0", context.Background())
+
+	if m.Checksum() != "" {
+		t.Errorf("Checksum() = %s, expected no checksum", m.Checksum())
+	}
+
+	m.WithChecksum([]byte("type User {}"))
+	applied := m.Checksum()
+
+	if applied == "" || applied != NewMigration("v1.0.0", "v1.1.0", nil).WithChecksum([]byte("type User {}")).Checksum() {
+		t.Errorf("Checksum() = %s, expected the same checksum for the same data", applied)
+	}
+
+	m.migration = &proto.Migration{Checksum: applied}
+
+	if err := m.verifyChecksum(); err != nil {
+		t.Errorf("verifyChecksum() returned an error: %v", err)
+	}
+
+	m.WithChecksum([]byte("type User { User.name }"))
+
+	if err := m.verifyChecksum(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("verifyChecksum() = %v, expected ErrChecksumMismatch", err)
+	}
+
+	if err := m.Revert(); !errors.Is(err, ErrNotReversible) {
+		t.Errorf("Revert() = %v, expected ErrNotReversible", err)
+	}
+}
+
+func TestRunPlan_Output(t *testing.T) {
+	p := &Plan{FromVersion: "v1.0.0", ToVersion: "v1.0.0"}
+	out := &strings.Builder{}
+
+	if err := runPlan(WithOutput(context.Background(), out), p); err != nil {
+		t.Fatalf("runPlan() returned an error: %v", err)
+	}
+
+	if out.String() != p.String() {
+		t.Errorf("runPlan() wrote %q, expected the plan %q", out.String(), p.String())
+	}
+
+	if output(context.Background()) != io.Discard {
+		t.Errorf("output() without a writer should discard the output")
+	}
+}
+
+func TestNewMigrateCmd(t *testing.T) {
+	cmd := NewMigrateCmd()
+
+	for _, name := range []string{"status", "plan", "up", "down", "to"} {
+		if c, _, err := cmd.Find([]string{name}); err != nil || c.Name() != name {
+			t.Errorf("migrate %s not found: %v", name, err)
+		}
+	}
+
+	if cmd.PersistentFlags().Lookup("dry-run") == nil {
+		t.Errorf("migrate --dry-run flag not found")
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"context"
+	proto "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/models"
+	"github.com/smoxy-io/goSDK/util/semver"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MigrationPending the registered migration has not been run
+	MigrationPending = "PENDING"
+)
+
+// MigrationInfo the state of a migration
+type MigrationInfo struct {
+	FromVersion string
+	ToVersion   string
+	// Status the last status of the migration, or MigrationPending
+	Status string
+	// Registered the migration is registered with RegisterMigrator
+	Registered bool
+	// Changed the migration was changed after it was applied
+	Changed bool
+	// LastRun the end time of the last run of the migration (unix seconds)
+	LastRun string
+}
+
+// SchemaStatus the state of the schema and its migrations
+type SchemaStatus struct {
+	Version    string
+	Locked     bool
+	LockOwner  string
+	LockExpiry time.Time
+	Migrations []*MigrationInfo
+}
+
+// Status returns the state of the schema, of the migrations that were run and of the registered migrations that
+// have not been run
+func Status(ctx context.Context) (*SchemaStatus, error) {
+	md, err := models.GetSchemaMetaData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SchemaStatus{
+		Version:    md.Version,
+		Locked:     isLocked(md, "", time.Now()),
+		LockOwner:  md.GetLockOwner(),
+		Migrations: []*MigrationInfo{},
+	}
+
+	if expiry, eErr := strconv.ParseInt(md.GetLockExpiry(), 10, 64); eErr == nil {
+		s.LockExpiry = time.Unix(expiry, 0).UTC()
+	}
+
+	run := map[string]bool{}
+
+	for _, mi := range md.Migrations {
+		run[canonicalKey(mi.FromVersion, mi.ToVersion)] = true
+
+		info := &MigrationInfo{
+			FromVersion: mi.FromVersion,
+			ToVersion:   mi.ToVersion,
+			Status:      mi.LastStatus.Name(),
+		}
+
+		for _, r := range mi.Results {
+			if r.GetEndTime() > info.LastRun {
+				info.LastRun = r.GetEndTime()
+			}
+		}
+
+		if newMigratorFn := GetMigratorFactory(mi.FromVersion, mi.ToVersion); newMigratorFn != nil {
+			info.Registered = true
+
+			checksum := newMigratorFn(ctx).Checksum()
+			info.Changed = mi.LastStatus == proto.MigrationStatus_SUCCESS && mi.Checksum != "" && checksum != "" && checksum != mi.Checksum
+		}
+
+		s.Migrations = append(s.Migrations, info)
+	}
+
+	for _, e := range migrations.sortedEdges() {
+		if run[e.key()] {
+			continue
+		}
+
+		s.Migrations = append(s.Migrations, &MigrationInfo{
+			FromVersion: e.fromVersion,
+			ToVersion:   e.toVersion,
+			Status:      MigrationPending,
+			Registered:  true,
+		})
+	}
+
+	sort.SliceStable(s.Migrations, func(i, j int) bool {
+		if c := compareVersions(s.Migrations[i].FromVersion, s.Migrations[j].FromVersion); c != 0 {
+			return c < 0
+		}
+
+		return compareVersions(s.Migrations[i].ToVersion, s.Migrations[j].ToVersion) < 0
+	})
+
+	return s, nil
+}
+
+// compareVersions compares semantic versions, versions that are not semantic versions are compared as strings
+func compareVersions(a string, b string) int {
+	if c, err := semver.Compare(a, b); err == nil {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	goerrors "github.com/go-errors/errors"
+	"github.com/smoxy-io/goSDK/util/arrays"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultBulkChunkSize the number of models written per mutation by SetBulk
+	DefaultBulkChunkSize = 500
+)
+
+// BulkItemError a failure to write a single model of a bulk operation
+type BulkItemError struct {
+	// Index the index of the model in the list passed to the bulk operation
+	Index int
+	Err   error
+}
+
+func (e *BulkItemError) Error() string {
+	return fmt.Sprintf("item %d: %s", e.Index, e.Err)
+}
+
+func (e *BulkItemError) Unwrap() error {
+	return e.Err
+}
+
+// BulkError returned by bulk operations when one or more models were not written. models that are not listed were
+// written successfully
+type BulkError struct {
+	Items []*BulkItemError
+}
+
+func (e *BulkError) Error() string {
+	if len(e.Items) == 0 {
+		return "bulk operation failed"
+	}
+
+	return fmt.Sprintf("%d item(s) failed, first error: %s", len(e.Items), e.Items[0])
+}
+
+// Failed returns the indexes of the models that were not written
+func (e *BulkError) Failed() []int {
+	failed := make([]int, len(e.Items))
+
+	for i, item := range e.Items {
+		failed[i] = item.Index
+	}
+
+	return failed
+}
+
+type bulkItem[T proto.Message] struct {
+	index int
+	model T
+	write *modelWrite
+}
+
+// SetBulk writes the models in the database in chunks of chunkSize models (DefaultBulkChunkSize by default), one
+// mutation per chunk. new models get their Id set to the uid created for them
+//
+// when the context has a transaction (see db.StartTxn) all chunks are written in it. a failed chunk rolls back the
+// transaction (see db.Rollback), so every model is reported as failed. without a transaction each chunk is committed on its own and
+// a failed chunk does not stop the remaining chunks
+//
+// like Set, the timestamps and the version of the models are maintained. a chunk with an outdated model is not
+// written, the outdated model is reported with a *ConflictError
+//
+// returns a *BulkError listing the models that were not written
+func SetBulk[T proto.Message](ctx context.Context, m []T, chunkSize ...int) error {
+	if len(m) == 0 {
+		return nil
+	}
+
+	size := DefaultBulkChunkSize
+
+	if len(chunkSize) > 0 && chunkSize[0] > 0 {
+		size = chunkSize[0]
+	}
+
+	// make sure every chunk uses the same client
+	_, cErr, ctx := db.GetClient(ctx)
+
+	if cErr != nil {
+		return cErr
+	}
+
+	extTxn := db.GetTxn(ctx) != nil
+	now := time.Now()
+
+	bulkErr := &BulkError{}
+	items := make([]*bulkItem[T], 0, len(m))
+
+	for i, d := range m {
+		// the blank node of a new model is numbered by its position in the batch so that every blank node in the
+		// batch is unique and can be mapped back to its model
+		w, pErr := prepareWrite(d, i+1, now)
+
+		if pErr != nil {
+			bulkErr.Items = append(bulkErr.Items, &BulkItemError{Index: i, Err: pErr})
+			continue
+		}
+
+		items = append(items, &bulkItem[T]{index: i, model: d, write: w})
+	}
+
+	if extTxn && len(bulkErr.Items) > 0 {
+		// nothing has been written yet. let the caller decide whether to write the valid models
+		for _, item := range items {
+			item.write.restore()
+		}
+
+		return bulkErr
+	}
+
+	for n, chunk := range arrays.Chunk(items, size) {
+		writes := make([]*modelWrite, len(chunk))
+
+		for i, item := range chunk {
+			writes[i] = item.write
+		}
+
+		resp, mErr := writeModels(ctx, writes)
+
+		if mErr != nil {
+			failed := chunk
+
+			if extTxn {
+				// a version conflict does not abort the transaction, discard it so that none of the models are
+				// written
+				_ = db.Rollback(ctx)
+
+				failed = items
+			}
+
+			for _, item := range failed {
+				item.write.restore()
+
+				err := fmt.Errorf("chunk %d: %w", n, mErr)
+
+				if item.write.conflict != nil {
+					err = item.write.conflict
+				}
+
+				bulkErr.Items = append(bulkErr.Items, &BulkItemError{
+					Index: item.index,
+					Err:   err,
+				})
+			}
+
+			if extTxn {
+				break
+			}
+
+			continue
+		}
+
+		for _, item := range chunk {
+			setNewId(item.model, item.write.uid, resp.Uids)
+
+			if e := setChange(item.model, strings.HasPrefix(item.write.uid, "_:")); e != nil {
+				publishChange(ctx, e)
+			}
+		}
+	}
+
+	if len(bulkErr.Items) > 0 {
+		slices.SortFunc(bulkErr.Items, func(a, b *BulkItemError) int {
+			return a.Index - b.Index
+		})
+
+		return bulkErr
+	}
+
+	return nil
+}
+
+func bulkNQuads(d any, uidCount int) (nquads string, uid string, ret error) {
+	defer func() {
+		if e := recover(); e != nil {
+			ret = goerrors.Wrap(e, 3)
+		}
+	}()
+
+	nquads, uid = ToNQuads(d, uidCount)
+
+	if nquads == "" || uid == "" {
+		return "", "", errors.New("cannot create nquads from model")
+	}
+
+	return nquads, uid, nil
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema/schematest"
+)
+
+func TestSetBulk_ConflictInTxn(t *testing.T) {
+	f := &fakeDgraph{respond: func(n int, _ *api.Request) (*api.Response, error) {
+		if n == 0 {
+			return versionResponse(0), nil
+		}
+
+		// the model of the second chunk was written by someone else
+		return versionResponse(), nil
+	}}
+
+	serveFakeDgraph(t, f)
+
+	ctx, err := db.StartTxn(context.Background())
+
+	if err != nil {
+		t.Fatalf("error starting a transaction: %v", err)
+	}
+
+	items := []*schematest.Item{{Id: "0x1", Version: 1}, {Id: "0x2", Version: 1}}
+
+	var bulkErr *BulkError
+
+	if err = SetBulk(ctx, items, 1); !errors.As(err, &bulkErr) {
+		t.Fatalf("SetBulk() = %v, expected a *BulkError", err)
+	}
+
+	if got := bulkErr.Failed(); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("SetBulk() failed = %v, expected every model", got)
+	}
+
+	if !errors.Is(bulkErr.Items[1].Err, ErrVersionConflict) {
+		t.Errorf("SetBulk() error of the outdated model = %v, expected a version conflict", bulkErr.Items[1].Err)
+	}
+
+	// the first chunk was written in the transaction, which must not be committable anymore
+	_ = db.Commit(ctx)
+
+	if f.aborted != 1 || f.committed != 0 {
+		t.Errorf("transaction aborted %d and committed %d times, expected to be aborted once", f.aborted, f.committed)
+	}
+
+	for i, item := range items {
+		if item.Version != 1 || item.UpdatedAt != 0 {
+			t.Errorf("item %d was not restored: %v", i, item)
+		}
+	}
+}
+
+func TestSetBulk(t *testing.T) {
+	f := &fakeDgraph{respond: func(n int, req *api.Request) (*api.Response, error) {
+		if n == 0 {
+			return nil, errors.New("chunk failed")
+		}
+
+		// uids are created for the blank nodes of the chunk only
+		uids := map[string]string{}
+
+		for i := 1; i <= 5; i++ {
+			if strings.Contains(string(req.Mutations[0].SetNquads), fmt.Sprintf("_:Item%d ", i)) {
+				uids[fmt.Sprintf("Item%d", i)] = fmt.Sprintf("0x%d0", i)
+			}
+		}
+
+		return &api.Response{Uids: uids}, nil
+	}}
+
+	serveFakeDgraph(t, f)
+
+	items := []*schematest.Item{{Name: "a"}, {Id: "invalid", Version: 1}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	var bulkErr *BulkError
+
+	if err := SetBulk(context.Background(), items, 2); !errors.As(err, &bulkErr) {
+		t.Fatalf("SetBulk() = %v, expected a *BulkError", err)
+	}
+
+	// the invalid model is left out of the chunks, the first chunk fails and the second one is still written
+	if got := bulkErr.Failed(); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("SetBulk() failed = %v, expected [0 1 2]", got)
+	}
+
+	if len(f.requests) != 2 {
+		t.Fatalf("SetBulk() sent %d mutations, expected 2", len(f.requests))
+	}
+
+	// blank nodes are numbered by the position of the model in the batch, not in the chunk
+	expect := [][]string{{"_:Item1 ", "_:Item3 "}, {"_:Item4 ", "_:Item5 "}}
+
+	for i, req := range f.requests {
+		nquads := string(req.Mutations[0].SetNquads)
+
+		for _, node := range expect[i] {
+			if !strings.Contains(nquads, node) {
+				t.Errorf("mutation %d does not contain %s:\n%s", i, node, nquads)
+			}
+		}
+	}
+
+	expectIds := []string{"", "invalid", "", "0x40", "0x50"}
+
+	for i, item := range items {
+		if item.Id != expectIds[i] {
+			t.Errorf("item %d id = %q, expected %q", i, item.Id, expectIds[i])
+		}
+	}
+
+	// the models of the failed chunk are restored
+	if items[0].CreatedAt != 0 || items[0].Version != 0 {
+		t.Errorf("item 0 was not restored: %v", items[0])
+	}
+}
+
+func TestSetNewId(t *testing.T) {
+	uids := map[string]string{"Item2": "0x9"}
+
+	tests := []struct {
+		name   string
+		id     string
+		uid    string
+		expect string
+	}{
+		{name: "new", uid: "_:Item2", expect: "0x9"},
+		{name: "no uid created", uid: "_:Item3", expect: ""},
+		{name: "existing", id: "0x1", uid: "<0x1>", expect: "0x1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &schematest.Item{Id: tt.id}
+
+			setNewId(item, tt.uid, uids)
+
+			if item.Id != tt.expect {
+				t.Errorf("setNewId() id = %q, expected %q", item.Id, tt.expect)
+			}
+		})
+	}
+}
+
+func TestBulkError(t *testing.T) {
+	err := &BulkError{Items: []*BulkItemError{
+		{Index: 2, Err: ErrVersionConflict},
+		{Index: 5, Err: errors.New("chunk 1: failed")},
+	}}
+
+	if got := err.Failed(); !reflect.DeepEqual(got, []int{2, 5}) {
+		t.Errorf("Failed() = %v, expected [2 5]", got)
+	}
+
+	if expect := "2 item(s) failed, first error: item 2: " + ErrVersionConflict.Error(); err.Error() != expect {
+		t.Errorf("Error() = %q, expected %q", err.Error(), expect)
+	}
+
+	if !errors.Is(err.Items[0], ErrVersionConflict) {
+		t.Errorf("item error does not unwrap to its cause")
+	}
+
+	if got := (&BulkError{}).Error(); got != "bulk operation failed" {
+		t.Errorf("Error() of an empty BulkError = %q", got)
+	}
+
+	if got := (&BulkError{}).Failed(); len(got) != 0 {
+		t.Errorf("Failed() of an empty BulkError = %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/smoxy-io/goSDK/modules/EventBus"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// ChangeRoutingKeyBase change events are published with the routing key type.model.<Type>.<op>
+	ChangeRoutingKeyBase = "type.model."
+	// AllChangesTopic the topic of the change events of all models
+	AllChangesTopic = ChangeRoutingKeyBase + "*"
+
+	ChangeOpCreate = "create"
+	ChangeOpUpdate = "update"
+	ChangeOpDelete = "delete"
+	// ChangeOpSoftDelete the model was soft deleted (see SoftDelete)
+	ChangeOpSoftDelete = "softDelete"
+)
+
+// ChangeEvent a change of a model. change events are published on the EventBus after the transaction that made the
+// change is committed, so rolled back changes never produce events. nothing is published when the EventBus is not
+// started
+type ChangeEvent struct {
+	// Type the dgraph type of the model
+	Type string
+	Id   string
+	// Op ChangeOpCreate, ChangeOpUpdate, ChangeOpDelete or ChangeOpSoftDelete
+	Op string
+	// Fields the fields that were written
+	Fields []string
+	// Before the values of the fields before the change. nil when they are not known
+	Before map[string]any
+	// After the values of the fields after the change. nil for deletes. edges are the ids of the related models
+	After map[string]any
+	// Time when the change was committed
+	Time time.Time
+}
+
+func (e *ChangeEvent) RoutingKey() string {
+	return ChangeRoutingKeyBase + e.Type + "." + e.Op
+}
+
+// ChangesTopic returns the topic of the change events of the models of type typeName, e.g. ChangesTopic("User", "")
+// for all changes of users or ChangesTopic("User", ChangeOpDelete) for deleted users
+func ChangesTopic(typeName string, op string) string {
+	if op == "" {
+		return ChangeRoutingKeyBase + typeName + ".*"
+	}
+
+	return ChangeRoutingKeyBase + typeName + "." + op
+}
+
+// publishChange publishes the change event after the transaction of ctx is committed
+func publishChange(ctx context.Context, e *ChangeEvent) {
+	if e.Type == "" || e.Id == "" {
+		return
+	}
+
+	db.AfterCommit(ctx, func() {
+		e.Time = time.Now().UTC()
+
+		_ = EventBus.Publish(e.RoutingKey(), e)
+	})
+}
+
+// setChange returns the change event of a model written with Set. nil when the EventBus is not started
+func setChange(m any, created bool) *ChangeEvent {
+	if !EventBus.IsStarted() {
+		return nil
+	}
+
+	pm, ok := m.(proto.Message)
+
+	if !ok {
+		return nil
+	}
+
+	e := &ChangeEvent{
+		Type: modelTypeName(m),
+		Id:   modelId(pm),
+		Op:   ChangeOpUpdate,
+	}
+
+	if created {
+		e.Op = ChangeOpCreate
+	}
+
+	e.Fields, e.After = changeValues(pm)
+
+	return e
+}
+
+// changeValues returns the names and the values of the fields of m that are written by Set
+func changeValues(m proto.Message) ([]string, map[string]any) {
+	fields := []string{}
+	values := map[string]any{}
+
+	pm := m.ProtoReflect()
+	fds := pm.Descriptor().Fields()
+
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+
+		if string(fd.Name()) == ModelIdField {
+			continue
+		}
+
+		if fd.HasPresence() && !pm.Has(fd) {
+			// unset optional fields and edges are not written
+			continue
+		}
+
+		v := pm.Get(fd)
+
+		if fd.IsList() && v.List().Len() == 0 {
+			continue
+		}
+
+		fields = append(fields, fd.JSONName())
+		values[fd.JSONName()] = changeValue(fd, v)
+	}
+
+	return fields, values
+}
+
+func changeValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.IsList() {
+		l := v.List()
+		values := make([]any, l.Len())
+
+		for i := 0; i < l.Len(); i++ {
+			values[i] = scalarChangeValue(fd, l.Get(i))
+		}
+
+		return values
+	}
+
+	return scalarChangeValue(fd, v)
+}
+
+func scalarChangeValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// edges are the ids of the related models
+		return modelId(v.Message().Interface())
+	case protoreflect.EnumKind:
+		return int32(v.Enum())
+	}
+
+	return v.Interface()
+}
+
+func modelId(m proto.Message) string {
+	pm := m.ProtoReflect()
+	fd := pm.Descriptor().Fields().ByName(ModelIdField)
+
+	if fd == nil || fd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+
+	return pm.Get(fd).String()
+}
+
+// nodeValues returns the type and the values of a node queried with dgraph.type and expand(_all_) { uid }.
+// predicates are named Type.field, the values are keyed by field. edges are the ids of the related nodes
+func nodeValues(raw json.RawMessage) (string, map[string]any) {
+	var nodes []map[string]any
+
+	if err := json.Unmarshal(raw, &nodes); err != nil || len(nodes) == 0 {
+		return "", nil
+	}
+
+	typeName := ""
+
+	if types, ok := nodes[0][DgraphTypePredicate].([]any); ok && len(types) > 0 {
+		typeName, _ = types[0].(string)
+	}
+
+	values := map[string]any{}
+
+	for pred, v := range nodes[0] {
+		if field, ok := strings.CutPrefix(pred, typeName+"."); ok && typeName != "" {
+			values[field] = nodeValue(v)
+		}
+	}
+
+	return typeName, values
+}
+
+func nodeValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if uid, ok := val["uid"].(string); ok && len(val) == 1 {
+			return uid
+		}
+	case []any:
+		values := make([]any, len(val))
+
+		for i := range val {
+			values[i] = nodeValue(val[i])
+		}
+
+		return values
+	}
+
+	return v
+}
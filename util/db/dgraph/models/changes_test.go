@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	proto "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/events"
+)
+
+func TestChangeValues(t *testing.T) {
+	migrating := true
+	status := proto.MigrationStatus_SUCCESS
+
+	md := &proto.SchemaMetaData{
+		Id:                  "0x1",
+		Version:             "v1.0.0",
+		IsMigrating:         &migrating,
+		LastMigrationStatus: &status,
+		Migrations:          []*proto.Migration{{Id: "0x2"}, {Id: "0x3"}},
+	}
+
+	fields, values := changeValues(md)
+
+	expectFields := []string{"version", "lastMigrationStatus", "isMigrating", "migrations"}
+
+	if !reflect.DeepEqual(fields, expectFields) {
+		t.Errorf("changeValues() fields = %v, expected %v", fields, expectFields)
+	}
+
+	expectValues := map[string]any{
+		"version":             "v1.0.0",
+		"lastMigrationStatus": int32(status),
+		"isMigrating":         true,
+		"migrations":          []any{"0x2", "0x3"},
+	}
+
+	if !reflect.DeepEqual(values, expectValues) {
+		t.Errorf("changeValues() values = %v, expected %v", values, expectValues)
+	}
+}
+
+func TestChangeEvent_RoutingKey(t *testing.T) {
+	e := &ChangeEvent{Type: "SchemaMetaData", Id: "0x1", Op: ChangeOpCreate}
+
+	if e.RoutingKey() != "type.model.SchemaMetaData.create" {
+		t.Errorf("RoutingKey() = %s, expected type.model.SchemaMetaData.create", e.RoutingKey())
+	}
+
+	rk := events.RoutingKey(e.RoutingKey())
+
+	if !rk.IsValid() {
+		t.Errorf("RoutingKey() = %s is not a valid routing key", rk)
+	}
+
+	tests := []struct {
+		topic  string
+		expect bool
+	}{
+		{topic: AllChangesTopic, expect: true},
+		{topic: ChangesTopic("SchemaMetaData", ""), expect: true},
+		{topic: ChangesTopic("SchemaMetaData", ChangeOpCreate), expect: true},
+		{topic: ChangesTopic("SchemaMetaData", ChangeOpDelete), expect: false},
+		{topic: ChangesTopic("Migration", ""), expect: false},
+	}
+
+	for _, tt := range tests {
+		if got := events.Topic(tt.topic).Matches(rk); got != tt.expect {
+			t.Errorf("Topic(%s).Matches(%s) = %v, expected %v", tt.topic, rk, got, tt.expect)
+		}
+	}
+}
+
+func TestNodeValues(t *testing.T) {
+	raw := json.RawMessage(`[{"dgraph.type":["SchemaMetaData"],"SchemaMetaData.version":"v1.0.0","SchemaMetaData.isMigrating":false,"SchemaMetaData.migrations":[{"uid":"0x2"}]}]`)
+
+	typeName, values := nodeValues(raw)
+
+	if typeName != "SchemaMetaData" {
+		t.Errorf("nodeValues() type = %s, expected SchemaMetaData", typeName)
+	}
+
+	expect := map[string]any{"version": "v1.0.0", "isMigrating": false, "migrations": []any{"0x2"}}
+
+	if !reflect.DeepEqual(values, expect) {
+		t.Errorf("nodeValues() values = %v, expected %v", values, expect)
+	}
+
+	if typeName, _ = nodeValues(json.RawMessage(`[]`)); typeName != "" {
+		t.Errorf("nodeValues() of a missing node = %s, expected no type", typeName)
+	}
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	utilErrors "github.com/smoxy-io/goSDK/util/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	ErrDeleteRestricted      = errors.New("delete restricted")
+	ErrSoftDeleteUnsupported = errors.New("soft delete not supported")
+)
+
+const (
+	nodeQuery = `
+query Q($id: string!){
+  node(func: uid($id), first: 1) {
+    dgraph.type
+    expand(_all_) {
+      uid
+    }
+  }
+}
+`
+)
+
+// Delete removes the model with the id from the database, following the delete rules of its edges (see the onDelete
+// option of smoxy.util.db.dgraph.field): the models of CASCADE edges are removed as well and nothing is removed while
+// a RESTRICT edge points to a model (ErrDeleteRestricted). the edges of other models that point to a removed model
+// are kept
+//
+// outside a transaction the models are removed in their own transaction (see db.WithTxn)
+func Delete(ctx context.Context, id string) error {
+	return deleteModel(ctx, id, false)
+}
+
+// SoftDelete sets the deleted at timestamp of the model with the id instead of removing it (see the timestamp option of
+// smoxy.util.db.dgraph.field). the delete rules are the same as for Delete. soft deleted models are not returned by Get
+// and GetByField
+//
+// returns ErrSoftDeleteUnsupported when the model, or a model of a CASCADE edge, has no deleted at timestamp
+func SoftDelete(ctx context.Context, id string) error {
+	return deleteModel(ctx, id, true)
+}
+
+// deletedNode a model that is deleted
+type deletedNode struct {
+	id       string
+	typeName string
+	// meta nil when the message of the type is not registered
+	meta   *modelMeta
+	values map[string]any
+}
+
+func deleteModel(ctx context.Context, id string, soft bool) error {
+	if !db.ValidId(id) {
+		return errors.New("model must have a valid id")
+	}
+
+	if db.GetTxn(ctx) == nil {
+		return db.WithTxn(ctx, func(tCtx context.Context) error {
+			return deleteModel(tCtx, id, soft)
+		})
+	}
+
+	nodes, dErr := deleteSet(ctx, id, soft)
+
+	if dErr != nil {
+		return dErr
+	}
+
+	if soft {
+		return softDeleteNodes(ctx, nodes, time.Now())
+	}
+
+	return deleteNodes(ctx, nodes)
+}
+
+// deleteSet returns the model with the id and the models it deletes through CASCADE edges
+func deleteSet(ctx context.Context, id string, soft bool) ([]*deletedNode, error) {
+	nodes := []*deletedNode{}
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+
+	for len(queue) > 0 {
+		nId := queue[0]
+		queue = queue[1:]
+
+		n, gErr := getNode(ctx, nId)
+
+		if gErr != nil {
+			return nil, gErr
+		}
+
+		if soft {
+			if n.typeName == "" && nId == id {
+				return nil, utilErrors.ErrNotFound
+			}
+
+			if n.typeName == "" {
+				// the related model does not exist
+				continue
+			}
+
+			if n.meta == nil || n.meta.deletedAt == nil {
+				return nil, fmt.Errorf("%w: %s %s has no deleted at timestamp", ErrSoftDeleteUnsupported, n.typeName, n.id)
+			}
+
+			if _, ok := n.values[n.meta.deletedAt.JSONName()]; ok {
+				// already soft deleted
+				continue
+			}
+		}
+
+		if n.meta != nil {
+			for _, fd := range n.meta.restrict {
+				if len(edgeIds(n.values[fd.JSONName()])) > 0 {
+					return nil, fmt.Errorf("%w: %s %s has %s", ErrDeleteRestricted, n.typeName, n.id, fd.JSONName())
+				}
+			}
+
+			for _, fd := range n.meta.cascade {
+				for _, eId := range edgeIds(n.values[fd.JSONName()]) {
+					if !seen[eId] {
+						seen[eId] = true
+						queue = append(queue, eId)
+					}
+				}
+			}
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+// getNode returns the type and the values of the model with the id. the type is empty when the node does not exist
+// or has no type
+func getNode(ctx context.Context, id string) (*deletedNode, error) {
+	res, qErr := Query(ctx, NewQueryParams(nodeQuery, map[string]string{"$id": id}, map[string]json.RawMessage{}))
+
+	if qErr != nil {
+		return nil, qErr
+	}
+
+	n := &deletedNode{id: id}
+
+	n.typeName, n.values = nodeValues(res["node"])
+
+	if n.typeName == "" {
+		return n, nil
+	}
+
+	if md := modelDescriptor(n.typeName); md != nil {
+		meta, mErr := getModelMeta(md)
+
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		n.meta = meta
+	}
+
+	return n, nil
+}
+
+func deleteNodes(ctx context.Context, nodes []*deletedNode) error {
+	nquads := make([]string, len(nodes))
+
+	for i, n := range nodes {
+		nquads[i] = fmt.Sprintf("<%s> * * .", n.id)
+	}
+
+	if _, err := doMutation(ctx, &api.Mutation{DelNquads: []byte(strings.Join(nquads, "\n"))}); err != nil {
+		return err
+	}
+
+	for _, n := range nodes {
+		publishChange(ctx, &ChangeEvent{
+			Type:   n.typeName,
+			Id:     n.id,
+			Op:     ChangeOpDelete,
+			Fields: slices.Sorted(maps.Keys(n.values)),
+			Before: n.values,
+		})
+	}
+
+	return nil
+}
+
+// softDeleteNodes sets the deleted at and the updated at timestamps of the nodes and increments their version
+func softDeleteNodes(ctx context.Context, nodes []*deletedNode, now time.Time) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	nquads := []string{}
+	events := []*ChangeEvent{}
+
+	for _, n := range nodes {
+		e := &ChangeEvent{
+			Type:   n.typeName,
+			Id:     n.id,
+			Op:     ChangeOpSoftDelete,
+			Fields: []string{},
+			Before: map[string]any{},
+			After:  map[string]any{},
+		}
+
+		set := func(fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+			field := fd.JSONName()
+
+			nquads = append(nquads, fmt.Sprintf("<%s> <%s> %s .", n.id, n.meta.predicate(fd), nquadValue(fd, v)))
+
+			e.Fields = append(e.Fields, field)
+			e.After[field] = v.Interface()
+
+			if before, ok := n.values[field]; ok {
+				e.Before[field] = before
+			}
+		}
+
+		set(n.meta.deletedAt, timestampValue(n.meta.deletedAt, now))
+
+		if n.meta.updatedAt != nil {
+			set(n.meta.updatedAt, timestampValue(n.meta.updatedAt, now))
+		}
+
+		if n.meta.version != nil {
+			version, _ := n.values[n.meta.version.JSONName()].(float64)
+
+			set(n.meta.version, intValue(n.meta.version, int64(version)+1))
+		}
+
+		events = append(events, e)
+	}
+
+	if _, err := mutate(ctx, strings.Join(nquads, "\n")); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		publishChange(ctx, e)
+	}
+
+	return nil
+}
+
+// edgeIds returns the ids of the related models of an edge value (see nodeValues)
+func edgeIds(v any) []string {
+	ids := []string{}
+
+	switch val := v.(type) {
+	case string:
+		ids = append(ids, val)
+	case []any:
+		for _, e := range val {
+			if id, ok := e.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
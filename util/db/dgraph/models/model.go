@@ -7,13 +7,18 @@ import (
 	"fmt"
 	"github.com/dgraph-io/dgo/v230/protos/api"
 	goerrors "github.com/go-errors/errors"
+	"github.com/smoxy-io/goSDK/modules/EventBus"
 	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/queries"
+	utilErrors "github.com/smoxy-io/goSDK/util/errors"
 	str "github.com/smoxy-io/goSDK/util/strings"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -48,13 +53,32 @@ const (
 query Q($id: string!){
   counter(func: uid($id), first: 1) @filter(has(%model%.%field%)) {
     old: A as %model%.%field%
-    new: B as math(A+%delta%)
-  }
+    new: B as math(A+%delta%)%versionFields%
+  }%unversioned%
 }
 `
 
 	incrementSetNQuads = `
 uid(A) <%model%.%field%> val(B) .
+`
+
+	// incrementVersion the version of a versioned model is incremented with the counter. a model written before it
+	// had a version gets version 1 (see writeModels)
+	incrementVersion = `
+    version: V as %model%.%version%
+    newVersion: W as math(V+1)`
+
+	incrementUnversioned = `
+  unversioned(func: uid(A)) @filter(NOT has(%model%.%version%)) {
+    U as uid
+  }`
+
+	incrementVersionNQuads = `
+uid(A) <%model%.%version%> val(W) .
+`
+
+	incrementUnversionedNQuads = `
+uid(U) <%model%.%version%> "1"^^<xs:int> .
 `
 )
 
@@ -115,55 +139,161 @@ func Get[T proto.Message](ctx context.Context, id string) (T, error) {
 	}
 
 	if len(q.Q) < 1 {
-		return resp, errors.New("not found")
+		return resp, utilErrors.ErrNotFound
 	}
 
 	return q.Q[0], nil
 }
 
+// GetByField gets the models that have value in field (proto or JSON name, see ModelField). the field's predicate
+// must be indexed
+func GetByField[T proto.Message](ctx context.Context, field string, value any) ([]T, error) {
+	var m T
+
+	fd, fErr := ModelField(m.ProtoReflect().Descriptor(), field)
+
+	if fErr != nil {
+		return nil, fErr
+	}
+
+	dgraph, cErr, ctx := db.GetClient(ctx)
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	txn := db.GetTxn(ctx)
+	extTxn := txn != nil
+
+	if !extTxn {
+		txn = dgraph.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+	}
+
+	vars := map[string]string{
+		"$value": fmt.Sprint(value),
+	}
+
+	qResp, qErr := txn.QueryWithVars(ctx, buildGetByFieldQuery(m, fd), vars)
+
+	if qErr != nil {
+		return nil, qErr
+	}
+
+	q := struct {
+		Q []T `json:"q"`
+	}{}
+
+	if err := json.Unmarshal(qResp.Json, &q); err != nil {
+		return nil, err
+	}
+
+	return q.Q, nil
+}
+
+// ModelField returns the field of the model md by its proto or JSON name. the id is not a field of the model (see
+// GetById)
+func ModelField(md protoreflect.MessageDescriptor, field string) (protoreflect.FieldDescriptor, error) {
+	fd := md.Fields().ByName(protoreflect.Name(field))
+
+	if fd == nil {
+		fd = md.Fields().ByJSONName(field)
+	}
+
+	if fd == nil || fd.Name() == ModelIdField {
+		return nil, fmt.Errorf("unknown field %s", field)
+	}
+
+	return fd, nil
+}
+
 func buildGetByIdQuery[T proto.Message](m T) string {
 	q := `
 query Q($id: string!) {
-  q(func: uid($id), first: 1) {
+  q(func: uid($id), first: 1)%%FILTER%% {
+    %%FIELDS%%
+  }
+}
+`
+
+	q = strings.ReplaceAll(q, "%%FILTER%%", softDeleteFilter(m))
+	q = strings.ReplaceAll(q, "%%FIELDS%%", strings.Join(modelFields(m), "\n"))
+
+	return q
+}
+
+func buildGetByFieldQuery[T proto.Message](m T, fd protoreflect.FieldDescriptor) string {
+	q := `
+query Q($value: string!) {
+  q(func: eq(%%PREDICATE%%, $value))%%FILTER%% {
     %%FIELDS%%
   }
 }
 `
 
-	fields := []string{}
+	q = strings.ReplaceAll(q, "%%PREDICATE%%", modelTypeName(m)+"."+fd.JSONName())
+	q = strings.ReplaceAll(q, "%%FILTER%%", softDeleteFilter(m))
+	q = strings.ReplaceAll(q, "%%FIELDS%%", strings.Join(modelFields(m), "\n"))
 
+	return q
+}
+
+// modelValue returns the struct value of m. a nil pointer returns the zero value of the struct
+func modelValue(m any) reflect.Value {
 	v := reflect.ValueOf(m)
 	vk := v.Kind()
 
-	if vk == reflect.Pointer || vk == reflect.Interface {
-		// dereference the pointer or interface
+	if vk == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+
+		// dereference the pointer
 		v = v.Elem()
-		vk = v.Kind()
 	}
 
-	if vk == reflect.Struct {
-		// convert each struct field into a dql field line
-		buildQuery(v, vk, fields)
-	}
+	return v
+}
 
-	q = strings.ReplaceAll(q, "%%FIELDS%%", strings.Join(fields, "\n"))
+func modelTypeName(m any) string {
+	v := modelValue(m)
 
-	return q
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	return v.Type().Name()
 }
 
-func buildQuery(v reflect.Value, vk reflect.Kind, flds []string) {
-	typeName := v.Type().Name()
+// modelFields returns the dql fields of m
+func modelFields(m any) []string {
+	v := modelValue(m)
 
+	if v.Kind() != reflect.Struct {
+		return []string{}
+	}
+
+	// convert each struct field into a dql field line
+	return buildQuery(v, v.Kind(), []string{})
+}
+
+func buildQuery(v reflect.Value, vk reflect.Kind, flds []string, parents ...reflect.Type) []string {
 	if vk == reflect.Pointer || vk == reflect.Interface {
 		// dereference the pointer or interface
-		v = v.Elem()
+		v = modelValue(v.Interface())
 		vk = v.Kind()
 	}
 
 	if vk != reflect.Struct {
-		return
+		return flds
 	}
 
+	typeName := v.Type().Name()
+
+	// relationships back to a model that is already being queried only get their ids, otherwise self referencing
+	// models would never stop expanding
+	parents = append(parents, v.Type())
+
 	for _, field := range reflect.VisibleFields(v.Type()) {
 		if !field.IsExported() {
 			// ignore unexported fields
@@ -179,7 +309,7 @@ func buildQuery(v reflect.Value, vk reflect.Kind, flds []string) {
 		if vfk == reflect.Pointer || vfk == reflect.Interface {
 			// dereference the pointer or interface
 			// necessary for handling nullable fields as they are pointers in the model struct
-			vf = vf.Elem()
+			vf = modelValue(vf.Interface())
 			vfk = vf.Kind()
 		}
 
@@ -192,36 +322,35 @@ func buildQuery(v reflect.Value, vk reflect.Kind, flds []string) {
 		default:
 			switch vfk {
 			case reflect.Slice, reflect.Array:
+				if vf.Type().Elem().Kind() == reflect.Uint8 {
+					// bytes are a scalar value
+					flds = append(flds, predName+": "+typeName+"."+predName)
+					continue
+				}
+
 				// this is a list of relationships
 				// dql query is similar to single relationship
-				vfR := vf.Elem()
+				vfR := modelValue(reflect.Zero(vf.Type().Elem()).Interface())
 				vkR := vfR.Kind()
 
-				if vkR == reflect.Pointer || vkR == reflect.Interface {
-					vfR = vfR.Elem()
-					vkR = vfR.Kind()
-				}
-
 				if vkR != reflect.Struct {
 					// no fields to iterate, treat as a standard field
 					flds = append(flds, predName+": "+typeName+"."+predName)
 					continue
 				}
 
-				f := []string{"id: uid"}
-
-				buildQuery(vfR, vkR, f)
-
-				flds = append(flds, predName+": "+typeName+"."+predName+" {\n"+strings.Join(f, "\n")+"\n}")
+				flds = append(flds, relationshipQuery(vfR, typeName+"."+predName, predName, parents))
 
 				continue
 			case reflect.Struct:
-				// this is a single relationship
-				f := []string{"id: uid"}
-
-				buildQuery(vf, vk, f)
+				if _, ok := reflect.New(vf.Type()).Interface().(Scalar); ok {
+					// scalar values like geo types are not relationships
+					flds = append(flds, predName+": "+typeName+"."+predName)
+					continue
+				}
 
-				flds = append(flds, predName+": "+typeName+"."+predName+" {\n"+strings.Join(f, "\n")+"\n}")
+				// this is a single relationship
+				flds = append(flds, relationshipQuery(vf, typeName+"."+predName, predName, parents))
 
 				continue
 			case reflect.Invalid:
@@ -233,18 +362,29 @@ func buildQuery(v reflect.Value, vk reflect.Kind, flds []string) {
 			}
 		}
 	}
+
+	return flds
+}
+
+func relationshipQuery(v reflect.Value, predicate string, alias string, parents []reflect.Type) string {
+	f := []string{"id: uid"}
+
+	if !slices.Contains(parents, v.Type()) {
+		f = buildQuery(v, v.Kind(), f, parents...)
+	}
+
+	return alias + ": " + predicate + " {\n" + strings.Join(f, "\n") + "\n}"
 }
 
 // Set writes the model in the database
+//
+// the timestamps of the model are maintained (see the timestamp option of smoxy.util.db.dgraph.field). a versioned
+// model is only written when its version in the database is still the version it was read with, otherwise Set
+// returns a *ConflictError (it wraps ErrVersionConflict) and the model is left unchanged
 func Set[T proto.Message](ctx context.Context, m T) error {
 	return upsert(ctx, m)
 }
 
-func SetBulk[T proto.Message](ctx context.Context, m []T) error {
-	// TODO: implement
-	return fmt.Errorf("not implemented")
-}
-
 // Query performs a query to retrieve models from the database
 func Query[T any](ctx context.Context, params *QueryParams[T]) (T, error) {
 	var nilT T
@@ -276,8 +416,50 @@ func Query[T any](ctx context.Context, params *QueryParams[T]) (T, error) {
 	return params.Res, nil
 }
 
+// Find runs a query built with queries.NewQuery and returns the models it selected. soft deleted models are left out
+// unless the query selects them with WithDeleted
+func Find[T proto.Message](ctx context.Context, q *queries.Query[T]) ([]T, error) {
+	dql, vars, bErr := q.Build()
+
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	res, qErr := Query(ctx, NewQueryParams(dql, vars, map[string]json.RawMessage{}))
+
+	if qErr != nil {
+		return nil, qErr
+	}
+
+	found := []T{}
+
+	if raw, ok := res[q.Name()]; ok {
+		if err := json.Unmarshal(raw, &found); err != nil {
+			return nil, err
+		}
+	}
+
+	return found, nil
+}
+
 // Increment performs an increment operation on a scalar predicate of a model
+//
+// like Set, the updated at timestamp and the version of the model are maintained, so a copy of the model read before
+// the increment is outdated
+//
+// outside a transaction, the increment runs in its own transaction that is retried when it conflicts with a
+// concurrent increment (see db.WithTxn)
 func Increment[T Number](ctx context.Context, model proto.Message, field string, delta T) error {
+	if db.GetTxn(ctx) == nil {
+		return db.WithTxn(ctx, func(tCtx context.Context) error {
+			return increment(tCtx, model, field, delta)
+		})
+	}
+
+	return increment(ctx, model, field, delta)
+}
+
+func increment[T Number](ctx context.Context, model proto.Message, field string, delta T) error {
 	modelName := string(model.ProtoReflect().Type().Descriptor().Name())
 
 	modelId := ""
@@ -317,13 +499,46 @@ func Increment[T Number](ctx context.Context, model proto.Message, field string,
 		break
 	}
 
-	query := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(incrementQuery, "%model%", modelName), "%field%", field), "%delta%", deltaStr)
+	meta, mErr := getModelMeta(model.ProtoReflect().Descriptor())
+
+	if mErr != nil {
+		return mErr
+	}
+
+	versionFields := ""
+	unversioned := ""
+	version := ""
+
+	if meta.version != nil {
+		versionFields = incrementVersion
+		unversioned = incrementUnversioned
+		version = meta.version.JSONName()
+	}
+
+	replacer := strings.NewReplacer("%model%", modelName, "%field%", field, "%delta%", deltaStr, "%version%", version)
+
+	query := strings.NewReplacer("%versionFields%", versionFields, "%unversioned%", unversioned).Replace(incrementQuery)
+	query = replacer.Replace(query)
 
 	queryVars := map[string]string{
 		"$id": modelId,
 	}
 
-	nQuads := strings.ReplaceAll(strings.ReplaceAll(incrementSetNQuads, "%model%", modelName), "%field%", field)
+	nQuads := replacer.Replace(incrementSetNQuads)
+
+	// like a write, the increment maintains the updated at timestamp and the version, so that a model read before the
+	// increment cannot overwrite it (see writeModels)
+	var updatedAt protoreflect.Value
+
+	if meta.updatedAt != nil {
+		updatedAt = timestampValue(meta.updatedAt, time.Now())
+
+		nQuads += fmt.Sprintf("uid(A) <%s> %s .\n", meta.predicate(meta.updatedAt), nquadValue(meta.updatedAt, updatedAt))
+	}
+
+	if meta.version != nil {
+		nQuads += strings.TrimPrefix(replacer.Replace(incrementVersionNQuads), "\n")
+	}
 
 	//fmt.Printf("[DEBUG] Increment query: %s\n", query)
 	//fmt.Printf("[DEBUG] Increment nquads: %s\n", nQuads)
@@ -335,12 +550,59 @@ func Increment[T Number](ctx context.Context, model proto.Message, field string,
 		Cond:      "@if(gt(len(A), 0))",
 	})
 
-	_, rErr := rawQuery(ctx, query, queryVars, mutations)
+	if meta.version != nil {
+		mutations = append(mutations, &api.Mutation{
+			SetNquads: []byte(replacer.Replace(incrementUnversionedNQuads)),
+			Cond:      "@if(gt(len(U), 0))",
+		})
+	}
+
+	resp, rErr := rawQuery(ctx, query, queryVars, mutations)
 
 	if rErr != nil {
 		return rErr
 	}
 
+	if EventBus.IsStarted() {
+		res := struct {
+			Counter []struct {
+				Old        any `json:"old"`
+				New        any `json:"new"`
+				Version    any `json:"version"`
+				NewVersion any `json:"newVersion"`
+			} `json:"counter"`
+		}{}
+
+		if err := json.Unmarshal(resp.Json, &res); err == nil && len(res.Counter) > 0 {
+			e := &ChangeEvent{
+				Type:   modelName,
+				Id:     modelId,
+				Op:     ChangeOpUpdate,
+				Fields: []string{field},
+				Before: map[string]any{field: res.Counter[0].Old},
+				After:  map[string]any{field: res.Counter[0].New},
+			}
+
+			if meta.updatedAt != nil {
+				e.Fields = append(e.Fields, meta.updatedAt.JSONName())
+				e.After[meta.updatedAt.JSONName()] = updatedAt.Interface()
+			}
+
+			if meta.version != nil {
+				e.Fields = append(e.Fields, version)
+				e.Before[version] = res.Counter[0].Version
+				e.After[version] = res.Counter[0].NewVersion
+
+				if res.Counter[0].NewVersion == nil {
+					// the model was written before it had a version
+					e.After[version] = 1
+				}
+			}
+
+			publishChange(ctx, e)
+		}
+	}
+
 	return nil
 }
 
@@ -579,6 +841,10 @@ func rawQuery(ctx context.Context, query string, queryVars map[string]string, mu
 }
 
 func mutate(ctx context.Context, nquads string) (*api.Response, error) {
+	return doMutation(ctx, &api.Mutation{SetNquads: []byte(nquads)})
+}
+
+func doMutation(ctx context.Context, mut *api.Mutation) (*api.Response, error) {
 	dgraph, cErr, ctx := db.GetClient(ctx)
 
 	if cErr != nil {
@@ -593,10 +859,7 @@ func mutate(ctx context.Context, nquads string) (*api.Response, error) {
 		defer txn.Discard(ctx)
 	}
 
-	mut := &api.Mutation{
-		SetNquads: []byte(nquads),
-		CommitNow: !extTxn,
-	}
+	mut.CommitNow = !extTxn
 
 	return txn.Mutate(ctx, mut)
 }
@@ -613,33 +876,62 @@ func upsert[T proto.Message](ctx context.Context, d T) (ret error) {
 		}
 	}()
 
-	nquads, uid := ToNQuads(d)
+	w, pErr := prepareWrite(d, 1, time.Now())
+
+	if pErr != nil {
+		return pErr
+	}
 
-	resp, rErr := mutate(ctx, nquads)
+	resp, rErr := writeModels(ctx, []*modelWrite{w})
 
 	if rErr != nil {
+		w.restore()
+
+		if w.conflict != nil {
+			return w.conflict
+		}
+
 		return rErr
 	}
 
-	if strings.HasPrefix(uid, "_:") {
-		// a new uid should have been created. grab it and add it to the object
-		if id, ok := resp.Uids[strings.TrimPrefix(uid, "_:")]; ok {
-			v := reflect.ValueOf(d)
-			vk := v.Kind()
+	setNewId(d, w.uid, resp.Uids)
 
-			if vk == reflect.Pointer || vk == reflect.Interface {
-				// dereference the pointer or interface
-				v = v.Elem()
-				vk = v.Kind()
-			}
+	if e := setChange(d, strings.HasPrefix(w.uid, "_:")); e != nil {
+		publishChange(ctx, e)
+	}
 
-			vId := v.FieldByName("Id")
+	return nil
+}
 
-			if vId.CanSet() {
-				vId.SetString(id)
-			}
-		}
+// setNewId sets the Id field of d to the uid created for its blank node
+func setNewId(d any, uid string, uids map[string]string) {
+	if !strings.HasPrefix(uid, "_:") {
+		return
 	}
 
-	return nil
+	// a new uid should have been created. grab it and add it to the object
+	id, ok := uids[strings.TrimPrefix(uid, "_:")]
+
+	if !ok {
+		return
+	}
+
+	v := reflect.ValueOf(d)
+	vk := v.Kind()
+
+	if vk == reflect.Pointer || vk == reflect.Interface {
+		// dereference the pointer or interface
+		v = v.Elem()
+		vk = v.Kind()
+	}
+
+	if vk != reflect.Struct {
+		return
+	}
+
+	vId := v.FieldByName("Id")
+
+	if vId.CanSet() && vId.Kind() == reflect.String {
+		vId.SetString(id)
+	}
 }
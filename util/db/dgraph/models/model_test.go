@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema/schematest"
+)
+
+func TestIncrement(t *testing.T) {
+	f := &fakeDgraph{respond: func(int, *api.Request) (*api.Response, error) {
+		return &api.Response{Json: []byte(`{"counter":[{"old":1,"new":3,"version":4,"newVersion":5}]}`)}, nil
+	}}
+
+	serveFakeDgraph(t, f)
+
+	if err := Increment(context.Background(), &schematest.Item{Id: "0x1"}, "count", 2); err != nil {
+		t.Fatalf("Increment() returned an error: %v", err)
+	}
+
+	if len(f.requests) != 1 || f.committed != 1 {
+		t.Fatalf("Increment() sent %d requests and committed %d times, expected 1 and 1", len(f.requests), f.committed)
+	}
+
+	req := f.requests[0]
+
+	expectQuery := `
+query Q($id: string!){
+  counter(func: uid($id), first: 1) @filter(has(Item.count)) {
+    old: A as Item.count
+    new: B as math(A+2)
+    version: V as Item.version
+    newVersion: W as math(V+1)
+  }
+  unversioned(func: uid(A)) @filter(NOT has(Item.version)) {
+    U as uid
+  }
+}
+`
+
+	if req.Query != expectQuery {
+		t.Errorf("Increment() query =\n%s\nexpected:\n%s", req.Query, expectQuery)
+	}
+
+	if len(req.Mutations) != 2 {
+		t.Fatalf("Increment() sent %d mutations, expected 2", len(req.Mutations))
+	}
+
+	set := string(req.Mutations[0].SetNquads)
+
+	for _, nquad := range []string{"uid(A) <Item.count> val(B) .", `uid(A) <Item.updatedAt> "`, "uid(A) <Item.version> val(W) ."} {
+		if !strings.Contains(set, nquad) || req.Mutations[0].Cond != "@if(gt(len(A), 0))" {
+			t.Errorf("Increment() mutation does not set %s: %s %s", nquad, req.Mutations[0].Cond, set)
+		}
+	}
+
+	if m := req.Mutations[1]; m.Cond != "@if(gt(len(U), 0))" || strings.TrimSpace(string(m.SetNquads)) != `uid(U) <Item.version> "1"^^<xs:int> .` {
+		t.Errorf("Increment() does not version a model without a version: %s %s", m.Cond, m.SetNquads)
+	}
+}
+
+func TestBuildGetByFieldQuery(t *testing.T) {
+	md := (&schematest.Item{}).ProtoReflect().Descriptor()
+
+	tests := []struct {
+		name      string
+		field     string
+		predicate string
+	}{
+		{name: "json name", field: "createdAt", predicate: "Item.createdAt"},
+		{name: "proto name", field: "created_at", predicate: "Item.createdAt"},
+		{name: "same names", field: "count", predicate: "Item.count"},
+		{name: "id", field: "id"},
+		{name: "unknown", field: "missing"},
+		{name: "injection", field: "count, $value)) { uid } all(func: has(Item.name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, err := ModelField(md, tt.field)
+
+			if tt.predicate == "" {
+				if err == nil {
+					t.Errorf("ModelField(%q) expected an error", tt.field)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ModelField(%q) returned an error: %v", tt.field, err)
+			}
+
+			if q := buildGetByFieldQuery(&schematest.Item{}, fd); !strings.Contains(q, "q(func: eq("+tt.predicate+", $value))") {
+				t.Errorf("buildGetByFieldQuery() =\n%s\nexpected a query on %s", q, tt.predicate)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// ConflictError the model was written by someone else since it was read. the model is not written
+type ConflictError struct {
+	Type string
+	Id   string
+	// Version the version the model was read with
+	Version int64
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s %s was written since version %d", ErrVersionConflict, e.Type, e.Id, e.Version)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// modelMeta the fields of a model that are maintained by the models package (see the timestamp, version and
+// onDelete options of smoxy.util.db.dgraph.field)
+type modelMeta struct {
+	typeName  string
+	createdAt protoreflect.FieldDescriptor
+	updatedAt protoreflect.FieldDescriptor
+	deletedAt protoreflect.FieldDescriptor
+	version   protoreflect.FieldDescriptor
+	// cascade the edges to the models that are deleted with the model
+	cascade []protoreflect.FieldDescriptor
+	// restrict the edges that prevent the model from being deleted
+	restrict []protoreflect.FieldDescriptor
+}
+
+// modelMetas the modelMeta by message name
+var modelMetas sync.Map
+
+// modelDescriptors the message descriptors by dgraph type name
+var modelDescriptors sync.Map
+
+func getModelMeta(md protoreflect.MessageDescriptor) (*modelMeta, error) {
+	if meta, ok := modelMetas.Load(md.FullName()); ok {
+		return meta.(*modelMeta), nil
+	}
+
+	meta := &modelMeta{
+		typeName: schema.TypeName(md),
+		cascade:  []protoreflect.FieldDescriptor{},
+		restrict: []protoreflect.FieldDescriptor{},
+	}
+
+	fds := md.Fields()
+
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		opts := schema.FieldOptions(fd)
+
+		if opts == nil {
+			continue
+		}
+
+		if ts := opts.GetTimestamp(); ts != pb.DgraphField_NO_TIMESTAMP {
+			if fd.IsList() || (fd.Kind() != protoreflect.StringKind && !isInteger(fd)) {
+				return nil, fmt.Errorf("%s: timestamp requires a string or an integer", fd.FullName())
+			}
+
+			target := map[pb.DgraphField_Timestamp]*protoreflect.FieldDescriptor{
+				pb.DgraphField_CREATED_AT: &meta.createdAt,
+				pb.DgraphField_UPDATED_AT: &meta.updatedAt,
+				pb.DgraphField_DELETED_AT: &meta.deletedAt,
+			}[ts]
+
+			if target == nil {
+				return nil, fmt.Errorf("%s: unknown timestamp %s", fd.FullName(), ts)
+			}
+
+			if *target != nil {
+				return nil, fmt.Errorf("%s: %s is already the %s timestamp", fd.FullName(), (*target).Name(), ts)
+			}
+
+			*target = fd
+		}
+
+		if opts.GetVersion() {
+			if fd.IsList() || !isInteger(fd) {
+				return nil, fmt.Errorf("%s: version requires an integer", fd.FullName())
+			}
+
+			if meta.version != nil {
+				return nil, fmt.Errorf("%s: %s is already the version", fd.FullName(), meta.version.Name())
+			}
+
+			meta.version = fd
+		}
+
+		switch opts.GetOnDelete() {
+		case pb.DgraphField_KEEP:
+			continue
+		case pb.DgraphField_CASCADE:
+			meta.cascade = append(meta.cascade, fd)
+		case pb.DgraphField_RESTRICT:
+			meta.restrict = append(meta.restrict, fd)
+		}
+
+		if fd.Message() == nil {
+			return nil, fmt.Errorf("%s: onDelete requires an edge", fd.FullName())
+		}
+	}
+
+	modelMetas.Store(md.FullName(), meta)
+
+	return meta, nil
+}
+
+// modelDescriptor returns the descriptor of the model with the dgraph type typeName. nil when its message is not
+// registered
+func modelDescriptor(typeName string) protoreflect.MessageDescriptor {
+	if md, ok := modelDescriptors.Load(typeName); ok {
+		return md.(protoreflect.MessageDescriptor)
+	}
+
+	var md protoreflect.MessageDescriptor
+
+	protoregistry.GlobalTypes.RangeMessages(func(mt protoreflect.MessageType) bool {
+		if schema.TypeName(mt.Descriptor()) == typeName {
+			md = mt.Descriptor()
+
+			return false
+		}
+
+		return true
+	})
+
+	if md != nil {
+		modelDescriptors.Store(typeName, md)
+	}
+
+	return md
+}
+
+func (m *modelMeta) predicate(fd protoreflect.FieldDescriptor) string {
+	return m.typeName + "." + fd.JSONName()
+}
+
+// softDeleteFilter returns the filter that excludes soft deleted models of the type of m. empty when the type cannot
+// be soft deleted
+func softDeleteFilter(m proto.Message) string {
+	meta, err := getModelMeta(m.ProtoReflect().Descriptor())
+
+	if err != nil || meta.deletedAt == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" @filter(NOT has(%s))", meta.predicate(meta.deletedAt))
+}
+
+// noVersionCheck the version of the model is not checked when it is written
+const noVersionCheck = -1
+
+// modelWrite a model prepared to be written
+type modelWrite struct {
+	model  proto.Message
+	meta   *modelMeta
+	nquads string
+	uid    string
+	// version the version the model must have in the database, or noVersionCheck
+	version int64
+	// conflict set when the version of the model in the database is not version
+	conflict *ConflictError
+	// restore restores the maintained fields of the model when it is not written
+	restore func()
+}
+
+// prepareWrite sets the maintained fields of m and creates its nquads. the blank node of a new model is numbered
+// uidCount (see ToNQuads)
+//
+// the created at timestamp is set when the model is new, the updated at timestamp every time the model is written and
+// the version is incremented. unset created at and deleted at timestamps are not written, so they are not cleared
+// when a model is written that was not read with them
+func prepareWrite(m proto.Message, uidCount int, now time.Time) (*modelWrite, error) {
+	pm := m.ProtoReflect()
+
+	meta, mErr := getModelMeta(pm.Descriptor())
+
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	saved := map[protoreflect.FieldDescriptor]protoreflect.Value{}
+
+	set := func(fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+		if _, ok := saved[fd]; !ok {
+			saved[fd] = protoreflect.Value{}
+
+			if pm.Has(fd) {
+				saved[fd] = pm.Get(fd)
+			}
+		}
+
+		pm.Set(fd, v)
+	}
+
+	w := &modelWrite{
+		model:   m,
+		meta:    meta,
+		version: noVersionCheck,
+		restore: func() {
+			for fd, v := range saved {
+				if v.IsValid() {
+					pm.Set(fd, v)
+				} else {
+					pm.Clear(fd)
+				}
+			}
+		},
+	}
+
+	id := modelId(m)
+	isNew := id == ""
+
+	if meta.version != nil && !isNew && !db.ValidId(id) {
+		return nil, errors.New("model must have a valid id")
+	}
+
+	if meta.createdAt != nil && isNew && !pm.Has(meta.createdAt) {
+		set(meta.createdAt, timestampValue(meta.createdAt, now))
+	}
+
+	if meta.updatedAt != nil {
+		set(meta.updatedAt, timestampValue(meta.updatedAt, now))
+	}
+
+	if meta.version != nil {
+		if isNew {
+			set(meta.version, intValue(meta.version, 1))
+		} else {
+			w.version = intOf(pm.Get(meta.version))
+
+			set(meta.version, intValue(meta.version, w.version+1))
+		}
+	}
+
+	nquads, uid, nErr := bulkNQuads(m, uidCount)
+
+	if nErr != nil {
+		w.restore()
+
+		return nil, nErr
+	}
+
+	for _, fd := range []protoreflect.FieldDescriptor{meta.createdAt, meta.deletedAt} {
+		if fd != nil && !pm.Has(fd) {
+			nquads = withoutPredicate(nquads, uid, meta.predicate(fd))
+		}
+	}
+
+	w.nquads = nquads
+	w.uid = uid
+
+	return w, nil
+}
+
+// writeModels writes the models in one mutation. when a model's version is checked and it does not match the version
+// in the database none of the models are written, the conflict of the model is set and the returned error wraps
+// ErrVersionConflict
+func writeModels(ctx context.Context, writes []*modelWrite) (*api.Response, error) {
+	nquads := make([]string, len(writes))
+	checked := []*modelWrite{}
+
+	for i, w := range writes {
+		nquads[i] = w.nquads
+
+		if w.version != noVersionCheck {
+			checked = append(checked, w)
+		}
+	}
+
+	if len(checked) == 0 {
+		return mutate(ctx, strings.Join(nquads, "\n"))
+	}
+
+	// the models are only written when every checked model is found with its version
+	blocks := make([]string, len(checked))
+	conds := make([]string, len(checked))
+
+	for i, w := range checked {
+		pred := w.meta.predicate(w.meta.version)
+		filter := fmt.Sprintf("eq(%s, %d)", pred, w.version)
+
+		if w.version == 0 {
+			// the model was written before it had a version
+			filter = fmt.Sprintf("(NOT has(%s) OR %s)", pred, filter)
+		}
+
+		blocks[i] = fmt.Sprintf("  v%d(func: uid(%s)) @filter(%s) {\n    V%d as uid\n  }", i, modelId(w.model), filter, i)
+		conds[i] = fmt.Sprintf("eq(len(V%d), 1)", i)
+	}
+
+	resp, rErr := rawQuery(ctx, "query {\n"+strings.Join(blocks, "\n")+"\n}", nil, []*api.Mutation{{
+		SetNquads: []byte(strings.Join(nquads, "\n")),
+		Cond:      "@if(" + strings.Join(conds, " AND ") + ")",
+	}})
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	res := map[string][]json.RawMessage{}
+
+	if err := json.Unmarshal(resp.Json, &res); err != nil {
+		return nil, err
+	}
+
+	conflicts := []error{}
+
+	for i, w := range checked {
+		if len(res[fmt.Sprintf("v%d", i)]) > 0 {
+			continue
+		}
+
+		w.conflict = &ConflictError{
+			Type:    w.meta.typeName,
+			Id:      modelId(w.model),
+			Version: w.version,
+		}
+
+		conflicts = append(conflicts, w.conflict)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, errors.Join(conflicts...)
+	}
+
+	return resp, nil
+}
+
+// withoutPredicate removes the nquads of the predicate of the node uid
+func withoutPredicate(nquads string, uid string, predicate string) string {
+	prefix := uid + " <" + predicate + "> "
+	lines := []string{}
+
+	for _, line := range strings.Split(nquads, "\n") {
+		if !strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// timestampValue returns now as a value of the timestamp field: unix seconds, or RFC 3339 for datetime strings
+func timestampValue(fd protoreflect.FieldDescriptor, now time.Time) protoreflect.Value {
+	now = now.UTC()
+
+	if fd.Kind() != protoreflect.StringKind {
+		return intValue(fd, now.Unix())
+	}
+
+	if schema.FieldOptions(fd).GetType() == "datetime" {
+		return protoreflect.ValueOfString(now.Format(time.RFC3339))
+	}
+
+	return protoreflect.ValueOfString(strconv.FormatInt(now.Unix(), 10))
+}
+
+// nquadValue returns the nquad literal of a value of the field (strings and integers)
+func nquadValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.Kind() == protoreflect.StringKind {
+		return fmt.Sprintf("\"%s\"^^<xs:string>", v.String())
+	}
+
+	return fmt.Sprintf("\"%d\"^^<xs:int>", intOf(v))
+}
+
+func isInteger(fd protoreflect.FieldDescriptor) bool {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+
+	return false
+}
+
+func intValue(fd protoreflect.FieldDescriptor, n int64) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(uint64(n))
+	}
+
+	return protoreflect.ValueOfInt64(n)
+}
+
+func intOf(v protoreflect.Value) int64 {
+	switch n := v.Interface().(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	}
+
+	return 0
+}
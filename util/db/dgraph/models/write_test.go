@@ -0,0 +1,417 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema/schematest"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// fakeDgraph a dgraph alpha that records the requests it receives and answers queries with respond
+type fakeDgraph struct {
+	api.UnimplementedDgraphServer
+
+	// respond returns the response to the nth query (from 0)
+	respond func(n int, req *api.Request) (*api.Response, error)
+
+	lock      sync.Mutex
+	requests  []*api.Request
+	committed int
+	aborted   int
+}
+
+func (f *fakeDgraph) Query(_ context.Context, req *api.Request) (*api.Response, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.requests = append(f.requests, req)
+
+	resp, err := f.respond(len(f.requests)-1, req)
+
+	if resp != nil && resp.Txn == nil {
+		resp.Txn = &api.TxnContext{StartTs: 1}
+	}
+
+	return resp, err
+}
+
+func (f *fakeDgraph) CommitOrAbort(_ context.Context, tc *api.TxnContext) (*api.TxnContext, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if tc.Aborted {
+		f.aborted++
+	} else {
+		f.committed++
+	}
+
+	return tc, nil
+}
+
+// serveFakeDgraph makes GetClient use f until the end of the test
+func serveFakeDgraph(t *testing.T, f *fakeDgraph) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	api.RegisterDgraphServer(srv, f)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	if cErr := db.Configure(&db.ClientConfig{Endpoints: []string{lis.Addr().String()}}); cErr != nil {
+		t.Fatalf("error configuring the client: %v", cErr)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Configure(nil)
+		srv.Stop()
+	})
+}
+
+// versionResponse the response to the version check of writeModels. the models of the blocks in found have the
+// version they were read with
+func versionResponse(found ...int) *api.Response {
+	blocks := []string{}
+
+	for _, i := range found {
+		blocks = append(blocks, fmt.Sprintf(`"v%d":[{"uid":"0x%d"}]`, i, i+1))
+	}
+
+	return &api.Response{Json: []byte("{" + strings.Join(blocks, ",") + "}")}
+}
+
+func TestGetModelMeta(t *testing.T) {
+	md := schematest.Message(t, "User",
+		schematest.Field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+		schematest.Field("created_at", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT}),
+		schematest.Field("updated_at", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, &pb.DgraphField{Timestamp: pb.DgraphField_UPDATED_AT}),
+		schematest.Field("deleted_at", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Timestamp: pb.DgraphField_DELETED_AT, Type: "datetime"}),
+		schematest.Field("version", 5, descriptorpb.FieldDescriptorProto_TYPE_UINT64, &pb.DgraphField{Version: true}),
+		schematest.Edge("notes", 6, "Note", &pb.DgraphField{OnDelete: pb.DgraphField_CASCADE}),
+		schematest.Edge("owners", 7, "Note", &pb.DgraphField{OnDelete: pb.DgraphField_RESTRICT}),
+		schematest.Edge("friends", 8, "Note", nil),
+	)
+
+	meta, err := getModelMeta(md)
+
+	if err != nil {
+		t.Fatalf("getModelMeta() returned an error: %v", err)
+	}
+
+	names := func(fds ...protoreflect.FieldDescriptor) []string {
+		n := []string{}
+
+		for _, fd := range fds {
+			n = append(n, meta.predicate(fd))
+		}
+
+		return n
+	}
+
+	expect := []string{"User.createdAt", "User.updatedAt", "User.deletedAt", "User.version"}
+
+	if got := names(meta.createdAt, meta.updatedAt, meta.deletedAt, meta.version); !reflect.DeepEqual(got, expect) {
+		t.Errorf("getModelMeta() maintained fields = %v, expected %v", got, expect)
+	}
+
+	if got := names(meta.cascade...); !reflect.DeepEqual(got, []string{"User.notes"}) {
+		t.Errorf("getModelMeta() cascade = %v, expected [User.notes]", got)
+	}
+
+	if got := names(meta.restrict...); !reflect.DeepEqual(got, []string{"User.owners"}) {
+		t.Errorf("getModelMeta() restrict = %v, expected [User.owners]", got)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if v := timestampValue(meta.createdAt, now).String(); v != "1767323045" {
+		t.Errorf("timestampValue() = %s, expected unix seconds", v)
+	}
+
+	if v := timestampValue(meta.updatedAt, now).Int(); v != 1767323045 {
+		t.Errorf("timestampValue() = %d, expected unix seconds", v)
+	}
+
+	if v := timestampValue(meta.deletedAt, now).String(); v != "2026-01-02T03:04:05Z" {
+		t.Errorf("timestampValue() = %s, expected RFC 3339", v)
+	}
+
+	if v := nquadValue(meta.version, intValue(meta.version, 3)); v != `"3"^^<xs:int>` {
+		t.Errorf("nquadValue() = %s, expected \"3\"^^<xs:int>", v)
+	}
+}
+
+func TestGetModelMeta_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []*descriptorpb.FieldDescriptorProto
+	}{
+		{name: "timestamp bool", fields: []*descriptorpb.FieldDescriptorProto{
+			schematest.Field("f", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL, &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT}),
+		}},
+		{name: "two created at", fields: []*descriptorpb.FieldDescriptorProto{
+			schematest.Field("a", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT}),
+			schematest.Field("b", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT}),
+		}},
+		{name: "version string", fields: []*descriptorpb.FieldDescriptorProto{
+			schematest.Field("f", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Version: true}),
+		}},
+		{name: "onDelete scalar", fields: []*descriptorpb.FieldDescriptorProto{
+			schematest.Field("f", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{OnDelete: pb.DgraphField_CASCADE}),
+		}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := schematest.Message(t, "Invalid"+string(rune('A'+i)), tt.fields...)
+
+			if _, err := getModelMeta(md); err == nil {
+				t.Errorf("getModelMeta() expected an error")
+			}
+		})
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	var err error = &ConflictError{Type: "User", Id: "0x1", Version: 3}
+
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("ConflictError does not wrap ErrVersionConflict")
+	}
+
+	if err.Error() != "version conflict: User 0x1 was written since version 3" {
+		t.Errorf("Error() = %s", err.Error())
+	}
+}
+
+func TestWithoutPredicate(t *testing.T) {
+	nquads := "<0x1> <User.name> \"a\"^^<xs:string> .\n" +
+		"<0x1> <User.createdAt> \"\"^^<xs:string> .\n" +
+		"<0x1> <User.createdAtDay> \"\"^^<xs:string> ."
+
+	expect := "<0x1> <User.name> \"a\"^^<xs:string> .\n" +
+		"<0x1> <User.createdAtDay> \"\"^^<xs:string> ."
+
+	if got := withoutPredicate(nquads, "<0x1>", "User.createdAt"); got != expect {
+		t.Errorf("withoutPredicate() =\n%s\nexpected:\n%s", got, expect)
+	}
+}
+
+func TestEdgeIds(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		expect []string
+	}{
+		{name: "none", value: nil, expect: []string{}},
+		{name: "edge", value: nodeValue(map[string]any{"uid": "0x2"}), expect: []string{"0x2"}},
+		{name: "edges", value: nodeValue([]any{map[string]any{"uid": "0x2"}, map[string]any{"uid": "0x3"}}), expect: []string{"0x2", "0x3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := edgeIds(tt.value); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("edgeIds() = %v, expected %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestPrepareWrite(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		item       *schematest.Item
+		expect     *schematest.Item
+		uid        string
+		version    int64
+		predicates []string
+		omitted    []string
+	}{
+		{
+			name:       "new",
+			item:       &schematest.Item{Name: "a", Version: 7},
+			expect:     &schematest.Item{Name: "a", CreatedAt: now.Unix(), UpdatedAt: now.Unix(), Version: 1},
+			uid:        "_:Item3",
+			version:    noVersionCheck,
+			predicates: []string{"Item.createdAt", "Item.updatedAt", `<Item.version> "1"`},
+			omitted:    []string{"Item.deletedAt"},
+		},
+		{
+			name:       "existing",
+			item:       &schematest.Item{Id: "0x1", Name: "a", UpdatedAt: 1, Version: 4},
+			expect:     &schematest.Item{Id: "0x1", Name: "a", UpdatedAt: now.Unix(), Version: 5},
+			uid:        "<0x1>",
+			version:    4,
+			predicates: []string{"Item.updatedAt", `<Item.version> "5"`},
+			omitted:    []string{"Item.createdAt", "Item.deletedAt"},
+		},
+		{
+			name:       "existing without version",
+			item:       &schematest.Item{Id: "0x1", CreatedAt: 1, DeletedAt: "2025-01-01T00:00:00Z"},
+			expect:     &schematest.Item{Id: "0x1", CreatedAt: 1, UpdatedAt: now.Unix(), DeletedAt: "2025-01-01T00:00:00Z", Version: 1},
+			uid:        "<0x1>",
+			version:    0,
+			predicates: []string{"Item.createdAt", "Item.deletedAt", `<Item.version> "1"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := prepareWrite(tt.item, 3, now)
+
+			if err != nil {
+				t.Fatalf("prepareWrite() returned an error: %v", err)
+			}
+
+			if !reflect.DeepEqual(itemValues(tt.item), itemValues(tt.expect)) {
+				t.Errorf("prepareWrite() model = %v, expected %v", itemValues(tt.item), itemValues(tt.expect))
+			}
+
+			if w.uid != tt.uid || w.version != tt.version {
+				t.Errorf("prepareWrite() uid = %s, version = %d, expected %s, %d", w.uid, w.version, tt.uid, tt.version)
+			}
+
+			for _, p := range tt.predicates {
+				if !strings.Contains(w.nquads, tt.uid+" <"+strings.TrimPrefix(p, "<")) {
+					t.Errorf("prepareWrite() nquads do not set %s:\n%s", p, w.nquads)
+				}
+			}
+
+			for _, p := range tt.omitted {
+				if strings.Contains(w.nquads, "<"+p+">") {
+					t.Errorf("prepareWrite() nquads set %s:\n%s", p, w.nquads)
+				}
+			}
+		})
+	}
+
+	if _, err := prepareWrite(&schematest.Item{Id: "invalid", Version: 1}, 1, now); err == nil {
+		t.Errorf("prepareWrite() expected an error for an invalid id")
+	}
+}
+
+func TestPrepareWrite_Restore(t *testing.T) {
+	item := &schematest.Item{Id: "0x1", Name: "a", UpdatedAt: 1, Version: 4}
+	expect := itemValues(item)
+
+	w, err := prepareWrite(item, 1, time.Now())
+
+	if err != nil {
+		t.Fatalf("prepareWrite() returned an error: %v", err)
+	}
+
+	w.restore()
+
+	if got := itemValues(item); !reflect.DeepEqual(got, expect) {
+		t.Errorf("restore() model = %v, expected %v", got, expect)
+	}
+
+	item = &schematest.Item{Name: "b"}
+
+	if w, err = prepareWrite(item, 1, time.Now()); err != nil {
+		t.Fatalf("prepareWrite() returned an error: %v", err)
+	}
+
+	w.restore()
+
+	// the fields that were not set are cleared again
+	if got := itemValues(item); !reflect.DeepEqual(got, []any{"", "b", int64(0), int64(0), int64(0), "", int64(0)}) {
+		t.Errorf("restore() model = %v, expected only the name", got)
+	}
+}
+
+func TestWriteModels(t *testing.T) {
+	f := &fakeDgraph{respond: func(n int, _ *api.Request) (*api.Response, error) {
+		if n == 0 {
+			return versionResponse(0, 1), nil
+		}
+
+		// the second model was written by someone else
+		return versionResponse(0), nil
+	}}
+
+	serveFakeDgraph(t, f)
+
+	prepare := func() ([]*modelWrite, []*schematest.Item) {
+		items := []*schematest.Item{{Id: "0x1", Version: 3}, {Id: "0x2"}, {Name: "new"}}
+		writes := make([]*modelWrite, len(items))
+
+		for i, item := range items {
+			w, err := prepareWrite(item, i+1, time.Now())
+
+			if err != nil {
+				t.Fatalf("prepareWrite() returned an error: %v", err)
+			}
+
+			writes[i] = w
+		}
+
+		return writes, items
+	}
+
+	writes, _ := prepare()
+
+	if _, err := writeModels(context.Background(), writes); err != nil {
+		t.Fatalf("writeModels() returned an error: %v", err)
+	}
+
+	req := f.requests[0]
+
+	expectQuery := "query {\n" +
+		"  v0(func: uid(0x1)) @filter(eq(Item.version, 3)) {\n    V0 as uid\n  }\n" +
+		"  v1(func: uid(0x2)) @filter((NOT has(Item.version) OR eq(Item.version, 0))) {\n    V1 as uid\n  }\n" +
+		"}"
+
+	if req.Query != expectQuery {
+		t.Errorf("writeModels() query =\n%s\nexpected:\n%s", req.Query, expectQuery)
+	}
+
+	if len(req.Mutations) != 1 || req.Mutations[0].Cond != "@if(eq(len(V0), 1) AND eq(len(V1), 1))" {
+		t.Fatalf("writeModels() mutations = %v, expected one mutation conditioned on both versions", req.Mutations)
+	}
+
+	for _, w := range writes {
+		if !strings.Contains(string(req.Mutations[0].SetNquads), w.nquads) {
+			t.Errorf("writeModels() mutation does not contain the nquads of %s", w.uid)
+		}
+	}
+
+	writes, _ = prepare()
+
+	_, err := writeModels(context.Background(), writes)
+
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("writeModels() = %v, expected a version conflict", err)
+	}
+
+	if writes[0].conflict != nil || writes[2].conflict != nil {
+		t.Errorf("writeModels() reported a conflict for a model that was found with its version")
+	}
+
+	if c := writes[1].conflict; c == nil || c.Id != "0x2" || c.Version != 0 || c.Type != "Item" {
+		t.Errorf("writeModels() conflict = %v, expected Item 0x2 version 0", c)
+	}
+}
+
+// itemValues the values of the fields of an item
+func itemValues(item *schematest.Item) []any {
+	return []any{item.Id, item.Name, item.Count, item.CreatedAt, item.UpdatedAt, item.DeletedAt, item.Version}
+}
@@ -0,0 +1,270 @@
+package dgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dgraph-io/dgo/v230"
+	"github.com/dgraph-io/dgo/v230/protos/api"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// retryServiceConfig retries the idempotent requests to the Dgraph service (CheckVersion and Login) that fail
+	// because the alpha is unavailable. queries and mutations are not retried here: a mutation or a commit can be
+	// applied even though its response is lost, transactions are retried by WithTxn instead
+	retryServiceConfig = `{
+  "methodConfig": [{
+    "name": [{"service": "api.Dgraph", "method": "CheckVersion"}, {"service": "api.Dgraph", "method": "Login"}],
+    "retryPolicy": {
+      "maxAttempts": %d,
+      "initialBackoff": "0.1s",
+      "maxBackoff": "2s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }]
+}`
+)
+
+var (
+	defaultPool     = NewPool(nil)
+	defaultPoolLock = &sync.RWMutex{}
+)
+
+// Pool shares a Dgraph client, and its connections, between all the contexts that use it. connections are
+// established on first use
+type Pool struct {
+	cfg    *ClientConfig
+	conns  []*grpc.ClientConn
+	api    api.DgraphClient
+	client *dgo.Dgraph
+	lock   *sync.Mutex
+}
+
+// Client returns the pool's client. connects (and logs in) on first use. a failed attempt is retried by the next call
+func (p *Pool) Client(ctx context.Context) (*dgo.Dgraph, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	cfg := p.cfg
+
+	if cfg == nil {
+		envCfg, err := ConfigFromEnv()
+
+		if err != nil {
+			return nil, err
+		}
+
+		cfg = envCfg
+	}
+
+	conns, cErr := dialAll(cfg)
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	clients := make([]api.DgraphClient, len(conns))
+
+	for i, conn := range conns {
+		clients[i] = api.NewDgraphClient(conn)
+	}
+
+	rr := &roundRobinClient{clients: clients, next: &atomic.Uint64{}}
+	client := dgo.NewDgraphClient(rr)
+
+	if err := login(ctx, client, cfg); err != nil {
+		closeAll(conns)
+		return nil, err
+	}
+
+	p.conns = conns
+	p.api = rr
+	p.client = client
+
+	return client, nil
+}
+
+// Ping checks that the database is reachable
+func (p *Pool) Ping(ctx context.Context) error {
+	if _, err := p.Client(ctx); err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	dc := p.api
+	p.lock.Unlock()
+
+	if dc == nil {
+		return errors.New("pool has been closed")
+	}
+
+	_, vErr := dc.CheckVersion(ctx, &api.Check{})
+
+	return vErr
+}
+
+// Close closes the pool's connections. the next call to Client reconnects
+func (p *Pool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	err := closeAll(p.conns)
+
+	p.conns = nil
+	p.api = nil
+	p.client = nil
+
+	return err
+}
+
+// NewPool creates a pool with the config. a nil config is read from the environment (see ConfigFromEnv)
+func NewPool(cfg *ClientConfig) *Pool {
+	return &Pool{
+		cfg:  cfg,
+		lock: &sync.Mutex{},
+	}
+}
+
+// DefaultPool returns the pool used by GetClient
+func DefaultPool() *Pool {
+	defaultPoolLock.RLock()
+	defer defaultPoolLock.RUnlock()
+
+	return defaultPool
+}
+
+// Configure replaces the pool used by GetClient with a pool for cfg. the previous pool is closed
+func Configure(cfg *ClientConfig) error {
+	defaultPoolLock.Lock()
+	old := defaultPool
+	defaultPool = NewPool(cfg)
+	defaultPoolLock.Unlock()
+
+	return old.Close()
+}
+
+// roundRobinClient spreads requests over the connections to the alphas
+type roundRobinClient struct {
+	clients []api.DgraphClient
+	next    *atomic.Uint64
+}
+
+func (r *roundRobinClient) client() api.DgraphClient {
+	return r.clients[(r.next.Add(1)-1)%uint64(len(r.clients))]
+}
+
+func (r *roundRobinClient) Login(ctx context.Context, in *api.LoginRequest, opts ...grpc.CallOption) (*api.Response, error) {
+	return r.client().Login(ctx, in, opts...)
+}
+
+func (r *roundRobinClient) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	return r.client().Query(ctx, in, opts...)
+}
+
+func (r *roundRobinClient) Alter(ctx context.Context, in *api.Operation, opts ...grpc.CallOption) (*api.Payload, error) {
+	return r.client().Alter(ctx, in, opts...)
+}
+
+func (r *roundRobinClient) CommitOrAbort(ctx context.Context, in *api.TxnContext, opts ...grpc.CallOption) (*api.TxnContext, error) {
+	return r.client().CommitOrAbort(ctx, in, opts...)
+}
+
+func (r *roundRobinClient) CheckVersion(ctx context.Context, in *api.Check, opts ...grpc.CallOption) (*api.Version, error) {
+	return r.client().CheckVersion(ctx, in, opts...)
+}
+
+func dialAll(cfg *ClientConfig) ([]*grpc.ClientConn, error) {
+	opts, oErr := dialOptions(cfg)
+
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	conns := []*grpc.ClientConn{}
+
+	for _, endpoint := range cfg.endpoints() {
+		for i := 0; i < cfg.connsPerEndpoint(); i++ {
+			conn, err := grpc.NewClient(endpoint, opts...)
+
+			if err != nil {
+				closeAll(conns)
+				return nil, err
+			}
+
+			conns = append(conns, conn)
+		}
+	}
+
+	return conns, nil
+}
+
+func dialOptions(cfg *ClientConfig) ([]grpc.DialOption, error) {
+	tlsCfg, tErr := cfg.tlsConfig()
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	creds := insecure.NewCredentials()
+
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithInitialWindowSize(math.MaxInt32),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: cfg.dialTimeout(),
+		}),
+	}
+
+	if retries := cfg.retries(); retries > 0 {
+		opts = append(opts, grpc.WithDefaultServiceConfig(fmt.Sprintf(retryServiceConfig, retries+1)))
+	}
+
+	return opts, nil
+}
+
+func login(ctx context.Context, client *dgo.Dgraph, cfg *ClientConfig) error {
+	if cfg.User == "" {
+		if cfg.Namespace != 0 {
+			return errors.New("logging into a namespace requires a user")
+		}
+
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.dialTimeout())
+	defer cancel()
+
+	return client.LoginIntoNamespace(ctx, cfg.User, cfg.Password, cfg.Namespace)
+}
+
+func closeAll(conns []*grpc.ClientConn) error {
+	var err error
+
+	for _, conn := range conns {
+		if cErr := conn.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,693 @@
+package queries
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// IdField the name of the field that holds a model's uid
+	IdField = "id"
+
+	indent = "  "
+)
+
+type order struct {
+	field string
+	desc  bool
+}
+
+type edge struct {
+	field string
+	block *Block
+}
+
+type aggregate struct {
+	fn    string
+	field string
+	alias string
+}
+
+type count struct {
+	field string
+	alias string
+}
+
+// Block selects the fields, edges and aggregations of a model. the root block of a Query selects the queried models,
+// the block of an edge selects the related models
+//
+// field names are the model's proto field names (or their json names). predicates are derived from the proto
+// descriptor using the Model.field convention of the models package
+//
+// soft deleted models (models with a deleted at timestamp, see the timestamp option of smoxy.util.db.dgraph.field)
+// are left out, like models.Get leaves them out. use WithDeleted to select them too
+type Block struct {
+	desc        protoreflect.MessageDescriptor
+	fields      []string
+	filters     []Filter
+	withDeleted bool
+	first       int
+	offset      int
+	after       string
+	orders      []order
+	edges       []*edge
+	counts      []count
+	aggregates  []aggregate
+	countUids   string
+	err         error
+}
+
+// Fields selects fields of the model. all scalar fields are selected when no fields are selected
+func (b *Block) Fields(fields ...string) *Block {
+	b.fields = append(b.fields, fields...)
+
+	return b
+}
+
+// Filter adds filters to the block's @filter directive. multiple filters are combined with AND
+func (b *Block) Filter(filters ...Filter) *Block {
+	b.filters = append(b.filters, filters...)
+
+	return b
+}
+
+// WithDeleted selects soft deleted models too
+func (b *Block) WithDeleted() *Block {
+	b.withDeleted = true
+
+	return b
+}
+
+// First limits the number of models to n. a negative n selects the last n models
+func (b *Block) First(n int) *Block {
+	b.first = n
+
+	return b
+}
+
+// Offset skips the first n models
+func (b *Block) Offset(n int) *Block {
+	b.offset = n
+
+	return b
+}
+
+// After selects the models after the id (cursor based pagination)
+func (b *Block) After(id string) *Block {
+	b.after = id
+
+	return b
+}
+
+// OrderAsc orders the models by field in ascending order. fields are ordered by in the order they are added
+func (b *Block) OrderAsc(field string) *Block {
+	b.orders = append(b.orders, order{field: field})
+
+	return b
+}
+
+// OrderDesc orders the models by field in descending order. fields are ordered by in the order they are added
+func (b *Block) OrderDesc(field string) *Block {
+	b.orders = append(b.orders, order{field: field, desc: true})
+
+	return b
+}
+
+// Edge selects the related models of the field. the related models are selected by the block passed to fn
+func (b *Block) Edge(field string, fn ...func(e *Block)) *Block {
+	fd, fErr := fieldDescriptor(b.desc, field)
+
+	if fErr != nil {
+		b.setErr(fErr)
+		return b
+	}
+
+	if fd.Message() == nil {
+		b.setErr(fmt.Errorf("field %s of %s is not an edge", field, b.desc.Name()))
+		return b
+	}
+
+	e := newBlock(fd.Message())
+
+	for _, f := range fn {
+		f(e)
+	}
+
+	b.edges = append(b.edges, &edge{field: field, block: e})
+
+	return b
+}
+
+// Count selects the number of related models of the field as alias
+func (b *Block) Count(field string, alias string) *Block {
+	b.counts = append(b.counts, count{field: field, alias: alias})
+
+	return b
+}
+
+// CountUids selects the number of models matched by the block as alias
+func (b *Block) CountUids(alias string) *Block {
+	b.countUids = alias
+
+	return b
+}
+
+// Min selects the smallest value of field in the block as alias. the value is selected in the parent block (or in
+// the aggregates block of the query for the root block)
+func (b *Block) Min(field string, alias string) *Block {
+	return b.aggregate("min", field, alias)
+}
+
+// Max selects the largest value of field in the block as alias (see Min)
+func (b *Block) Max(field string, alias string) *Block {
+	return b.aggregate("max", field, alias)
+}
+
+// Sum selects the sum of the values of field in the block as alias (see Min)
+func (b *Block) Sum(field string, alias string) *Block {
+	return b.aggregate("sum", field, alias)
+}
+
+// Avg selects the average of the values of field in the block as alias (see Min)
+func (b *Block) Avg(field string, alias string) *Block {
+	return b.aggregate("avg", field, alias)
+}
+
+func (b *Block) aggregate(fn string, field string, alias string) *Block {
+	b.aggregates = append(b.aggregates, aggregate{fn: fn, field: field, alias: alias})
+
+	return b
+}
+
+func (b *Block) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// args renders the block's pagination and ordering arguments
+func (b *Block) args() ([]string, error) {
+	args := []string{}
+
+	if b.first != 0 {
+		args = append(args, "first: "+strconv.Itoa(b.first))
+	}
+
+	if b.offset > 0 {
+		args = append(args, "offset: "+strconv.Itoa(b.offset))
+	}
+
+	if b.after != "" {
+		if !db.ValidId(b.after) {
+			return nil, fmt.Errorf("invalid id %s", b.after)
+		}
+
+		args = append(args, "after: "+b.after)
+	}
+
+	for _, o := range b.orders {
+		pred, pErr := predicate(b.desc, o.field)
+
+		if pErr != nil {
+			return nil, pErr
+		}
+
+		if o.desc {
+			args = append(args, "orderdesc: "+pred)
+			continue
+		}
+
+		args = append(args, "orderasc: "+pred)
+	}
+
+	return args, nil
+}
+
+func (b *Block) filter(r *renderer) (string, error) {
+	filters := b.filters
+
+	if fd := deletedAtField(b.desc); fd != nil && !b.withDeleted {
+		filters = append(slices.Clone(filters), Not(Has(string(fd.Name()))))
+	}
+
+	if len(filters) == 0 {
+		return "", nil
+	}
+
+	f, err := And(filters...).render(r, b.desc)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(f, "(") {
+		f = "(" + f + ")"
+	}
+
+	return " @filter" + f, nil
+}
+
+// body renders the selections of the block. aggregations of the block's values are returned to be rendered in the
+// parent block
+func (b *Block) body(r *renderer, depth int) ([]string, []string, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+
+	pad := strings.Repeat(indent, depth)
+	lines := []string{}
+	parentLines := []string{}
+
+	fields := b.fields
+
+	if len(fields) == 0 {
+		fields = scalarFields(b.desc)
+	}
+
+	for _, field := range fields {
+		fd, fErr := fieldDescriptor(b.desc, field)
+
+		if fErr != nil {
+			return nil, nil, fErr
+		}
+
+		if fd.Name() == IdField {
+			lines = append(lines, pad+"id: uid")
+			continue
+		}
+
+		if fd.Message() != nil {
+			return nil, nil, fmt.Errorf("field %s of %s is an edge. use Edge to select it", field, b.desc.Name())
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s: %s.%s", pad, fd.Name(), b.desc.Name(), fd.JSONName()))
+	}
+
+	for _, e := range b.edges {
+		fd, _ := fieldDescriptor(b.desc, e.field)
+		pred := fmt.Sprintf("%s.%s", b.desc.Name(), fd.JSONName())
+
+		args, aErr := e.block.args()
+
+		if aErr != nil {
+			return nil, nil, aErr
+		}
+
+		argStr := ""
+
+		if len(args) > 0 {
+			argStr = " (" + strings.Join(args, ", ") + ")"
+		}
+
+		filter, fErr := e.block.filter(r)
+
+		if fErr != nil {
+			return nil, nil, fErr
+		}
+
+		eLines, eParentLines, eErr := e.block.body(r, depth+1)
+
+		if eErr != nil {
+			return nil, nil, eErr
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s: %s%s%s {", pad, fd.Name(), pred, argStr, filter))
+		lines = append(lines, eLines...)
+		lines = append(lines, pad+"}")
+
+		for _, l := range eParentLines {
+			lines = append(lines, pad+l)
+		}
+	}
+
+	for _, c := range b.counts {
+		pred, pErr := predicate(b.desc, c.field)
+
+		if pErr != nil {
+			return nil, nil, pErr
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s: count(%s)", pad, c.alias, pred))
+	}
+
+	if b.countUids != "" {
+		lines = append(lines, fmt.Sprintf("%s%s: count(uid)", pad, b.countUids))
+	}
+
+	for _, a := range b.aggregates {
+		pred, pErr := predicate(b.desc, a.field)
+
+		if pErr != nil {
+			return nil, nil, pErr
+		}
+
+		valVar := r.valueVariable()
+
+		lines = append(lines, fmt.Sprintf("%s%s as %s", pad, valVar, pred))
+		parentLines = append(parentLines, fmt.Sprintf("%s: %s(val(%s))", a.alias, a.fn, valVar))
+	}
+
+	return lines, parentLines, nil
+}
+
+func newBlock(desc protoreflect.MessageDescriptor) *Block {
+	return &Block{
+		desc:       desc,
+		fields:     []string{},
+		filters:    []Filter{},
+		orders:     []order{},
+		edges:      []*edge{},
+		counts:     []count{},
+		aggregates: []aggregate{},
+	}
+}
+
+// Query builds a parameterized DQL query for models of type T
+//
+//	dql, vars, err := queries.NewQuery[*pb.Migration]("migrations").
+//		Filter(queries.Eq("fromVersion", "v1.0.0")).
+//		OrderDesc("toVersion").
+//		First(10).
+//		Edge("results", func(e *queries.Block) {
+//			e.First(1)
+//		}).
+//		Build()
+type Query[T proto.Message] struct {
+	name string
+	fn   Filter
+	root *Block
+}
+
+// Name the name of the query's root block. the results are in the response under this name
+func (q *Query[T]) Name() string {
+	return q.name
+}
+
+// Root returns the root block of the query
+func (q *Query[T]) Root() *Block {
+	return q.root
+}
+
+// Func sets the root function that selects the models. defaults to all models of the type
+func (q *Query[T]) Func(fn Filter) *Query[T] {
+	q.fn = fn
+
+	return q
+}
+
+// ByIds selects the models with the ids
+func (q *Query[T]) ByIds(ids ...string) *Query[T] {
+	return q.Func(Uid(ids...))
+}
+
+func (q *Query[T]) Fields(fields ...string) *Query[T] {
+	q.root.Fields(fields...)
+
+	return q
+}
+
+func (q *Query[T]) Filter(filters ...Filter) *Query[T] {
+	q.root.Filter(filters...)
+
+	return q
+}
+
+func (q *Query[T]) WithDeleted() *Query[T] {
+	q.root.WithDeleted()
+
+	return q
+}
+
+func (q *Query[T]) First(n int) *Query[T] {
+	q.root.First(n)
+
+	return q
+}
+
+func (q *Query[T]) Offset(n int) *Query[T] {
+	q.root.Offset(n)
+
+	return q
+}
+
+func (q *Query[T]) After(id string) *Query[T] {
+	q.root.After(id)
+
+	return q
+}
+
+func (q *Query[T]) OrderAsc(field string) *Query[T] {
+	q.root.OrderAsc(field)
+
+	return q
+}
+
+func (q *Query[T]) OrderDesc(field string) *Query[T] {
+	q.root.OrderDesc(field)
+
+	return q
+}
+
+func (q *Query[T]) Edge(field string, fn ...func(e *Block)) *Query[T] {
+	q.root.Edge(field, fn...)
+
+	return q
+}
+
+func (q *Query[T]) Count(field string, alias string) *Query[T] {
+	q.root.Count(field, alias)
+
+	return q
+}
+
+func (q *Query[T]) CountUids(alias string) *Query[T] {
+	q.root.CountUids(alias)
+
+	return q
+}
+
+// Min selects the smallest value of field of the matched models as alias, in the query's aggregates block (named
+// <name>Aggregates)
+func (q *Query[T]) Min(field string, alias string) *Query[T] {
+	q.root.Min(field, alias)
+
+	return q
+}
+
+func (q *Query[T]) Max(field string, alias string) *Query[T] {
+	q.root.Max(field, alias)
+
+	return q
+}
+
+func (q *Query[T]) Sum(field string, alias string) *Query[T] {
+	q.root.Sum(field, alias)
+
+	return q
+}
+
+func (q *Query[T]) Avg(field string, alias string) *Query[T] {
+	q.root.Avg(field, alias)
+
+	return q
+}
+
+// Build renders the query. returns the DQL and its variables, to be passed to QueryWithVars
+func (q *Query[T]) Build() (string, map[string]string, error) {
+	r := newRenderer()
+
+	fn := q.fn
+
+	if fn == nil {
+		fn = Type(string(q.root.desc.Name()))
+	}
+
+	rootFn, fErr := fn.render(r, q.root.desc)
+
+	if fErr != nil {
+		return "", nil, fErr
+	}
+
+	args, aErr := q.root.args()
+
+	if aErr != nil {
+		return "", nil, aErr
+	}
+
+	filter, filterErr := q.root.filter(r)
+
+	if filterErr != nil {
+		return "", nil, filterErr
+	}
+
+	lines, aggregates, bErr := q.root.body(r, 2)
+
+	if bErr != nil {
+		return "", nil, bErr
+	}
+
+	sb := &strings.Builder{}
+
+	sb.WriteString("query Q" + r.declarations() + " {\n")
+	sb.WriteString(fmt.Sprintf("%s%s(func: %s%s)%s {\n", indent, q.name, rootFn, strings.Join(prefixAll(args, ", "), ""), filter))
+	sb.WriteString(strings.Join(lines, "\n") + "\n")
+	sb.WriteString(indent + "}\n")
+
+	if len(aggregates) > 0 {
+		sb.WriteString(fmt.Sprintf("%s%sAggregates() {\n", indent, q.name))
+		sb.WriteString(strings.Join(prefixAll(aggregates, indent+indent), "\n") + "\n")
+		sb.WriteString(indent + "}\n")
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String(), r.vars, nil
+}
+
+// NewQuery creates a query named name for models of type T
+func NewQuery[T proto.Message](name string) *Query[T] {
+	var m T
+
+	return &Query[T]{
+		name: name,
+		root: newBlock(m.ProtoReflect().Descriptor()),
+	}
+}
+
+// renderer collects the variables of a query while it is rendered
+type renderer struct {
+	vars      map[string]string
+	valueVars int
+}
+
+// variable adds value as a query variable and returns its name
+func (r *renderer) variable(value any) string {
+	name := "$v" + strconv.Itoa(len(r.vars))
+
+	if e, ok := value.(protoreflect.Enum); ok {
+		// enums are stored as their number
+		value = e.Number()
+	}
+
+	r.vars[name] = fmt.Sprint(value)
+
+	return name
+}
+
+// valueVariable returns the name of a new value variable
+func (r *renderer) valueVariable() string {
+	name := "a" + strconv.Itoa(r.valueVars)
+
+	r.valueVars++
+
+	return name
+}
+
+func (r *renderer) declarations() string {
+	if len(r.vars) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(r.vars))
+
+	for name := range r.vars {
+		names = append(names, name)
+	}
+
+	// variables are named in the order they were added
+	sort.Slice(names, func(i, j int) bool {
+		a, _ := strconv.Atoi(names[i][2:])
+		b, _ := strconv.Atoi(names[j][2:])
+
+		return a < b
+	})
+
+	for i, name := range names {
+		names[i] = name + ": string"
+	}
+
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+func newRenderer() *renderer {
+	return &renderer{vars: map[string]string{}}
+}
+
+// fieldDescriptor returns the descriptor of the field of the model by its proto name or json name
+func fieldDescriptor(desc protoreflect.MessageDescriptor, field string) (protoreflect.FieldDescriptor, error) {
+	fd := desc.Fields().ByName(protoreflect.Name(field))
+
+	if fd == nil {
+		fd = desc.Fields().ByJSONName(field)
+	}
+
+	if fd == nil {
+		return nil, fmt.Errorf("unknown field %s of %s", field, desc.Name())
+	}
+
+	return fd, nil
+}
+
+// predicate returns the dgraph predicate of the field of the model
+func predicate(desc protoreflect.MessageDescriptor, field string) (string, error) {
+	fd, fErr := fieldDescriptor(desc, field)
+
+	if fErr != nil {
+		return "", fErr
+	}
+
+	if fd.Name() == IdField {
+		return "", fmt.Errorf("%s is the uid of %s and has no predicate. use Uid to filter by id", field, desc.Name())
+	}
+
+	return fmt.Sprintf("%s.%s", desc.Name(), fd.JSONName()), nil
+}
+
+// deletedAtField returns the deleted at timestamp field of the model. nil when the model cannot be soft deleted
+func deletedAtField(desc protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	for i := 0; i < desc.Fields().Len(); i++ {
+		fd := desc.Fields().Get(i)
+
+		if schema.FieldOptions(fd).GetTimestamp() == pb.DgraphField_DELETED_AT {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+// scalarFields returns the names of the fields of the model that are not edges
+func scalarFields(desc protoreflect.MessageDescriptor) []string {
+	fields := []string{}
+
+	for i := 0; i < desc.Fields().Len(); i++ {
+		fd := desc.Fields().Get(i)
+
+		if fd.Message() != nil {
+			continue
+		}
+
+		fields = append(fields, string(fd.Name()))
+	}
+
+	return fields
+}
+
+func prefixAll(s []string, prefix string) []string {
+	out := make([]string, len(s))
+
+	for i, v := range s {
+		out[i] = prefix + v
+	}
+
+	return out
+}
@@ -0,0 +1,194 @@
+package queries
+
+import (
+	"maps"
+	"testing"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func Test_Query_Build(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  *Query[*pb.Migration]
+		expect string
+		vars   map[string]string
+		err    bool
+	}{
+		{
+			name:  "all scalar fields",
+			query: NewQuery[*pb.Migration]("migrations"),
+			expect: `query Q {
+  migrations(func: type(Migration)) {
+    id: uid
+    fromVersion: Migration.fromVersion
+    toVersion: Migration.toVersion
+    lastStatus: Migration.lastStatus
+    checksum: Migration.checksum
+  }
+}
+`,
+			vars: map[string]string{},
+		},
+		{
+			name: "filters, pagination and ordering",
+			query: NewQuery[*pb.Migration]("migrations").
+				Fields("id", "toVersion").
+				Filter(Eq("fromVersion", "v1"), Or(Ge("lastStatus", pb.MigrationStatus_FAILED), Not(Has("toVersion")))).
+				First(10).
+				Offset(5).
+				After("0x1a").
+				OrderDesc("toVersion").
+				OrderAsc("fromVersion"),
+			expect: `query Q($v0: string, $v1: string) {
+  migrations(func: type(Migration), first: 10, offset: 5, after: 0x1a, orderdesc: Migration.toVersion, orderasc: Migration.fromVersion) @filter(eq(Migration.fromVersion, $v0) AND (ge(Migration.lastStatus, $v1) OR NOT has(Migration.toVersion))) {
+    id: uid
+    toVersion: Migration.toVersion
+  }
+}
+`,
+			vars: map[string]string{"$v0": "v1", "$v1": "2"},
+		},
+		{
+			name: "edges and aggregations",
+			query: NewQuery[*pb.Migration]("migrations").
+				Func(AllOfTerms("toVersion", "v2")).
+				Fields("id").
+				Edge("results", func(e *Block) {
+					e.Fields("startTime").
+						Filter(Lt("startTime", "2024")).
+						First(-1).
+						OrderAsc("startTime").
+						Max("endTime", "lastEnd").
+						Edge("migration")
+				}).
+				Count("results", "resultCount").
+				CountUids("total").
+				Min("fromVersion", "oldest"),
+			expect: `query Q($v0: string, $v1: string) {
+  migrations(func: allofterms(Migration.toVersion, $v0)) {
+    id: uid
+    results: Migration.results (first: -1, orderasc: MigrationResult.startTime) @filter(lt(MigrationResult.startTime, $v1)) {
+      startTime: MigrationResult.startTime
+      migration: MigrationResult.migration {
+        id: uid
+        fromVersion: Migration.fromVersion
+        toVersion: Migration.toVersion
+        lastStatus: Migration.lastStatus
+        checksum: Migration.checksum
+      }
+      a0 as MigrationResult.endTime
+    }
+    lastEnd: max(val(a0))
+    resultCount: count(Migration.results)
+    total: count(uid)
+    a1 as Migration.fromVersion
+  }
+  migrationsAggregates() {
+    oldest: min(val(a1))
+  }
+}
+`,
+			vars: map[string]string{"$v0": "v2", "$v1": "2024"},
+		},
+		{
+			name:   "by ids",
+			query:  NewQuery[*pb.Migration]("migration").ByIds("0x1", "0x2").Fields("fromVersion"),
+			expect: "query Q {\n  migration(func: uid(0x1, 0x2)) {\n    fromVersion: Migration.fromVersion\n  }\n}\n",
+			vars:   map[string]string{},
+		},
+		{name: "unknown field", query: NewQuery[*pb.Migration]("q").Fields("missing"), err: true},
+		{name: "unknown filter field", query: NewQuery[*pb.Migration]("q").Filter(Eq("missing", 1)), err: true},
+		{name: "edge as field", query: NewQuery[*pb.Migration]("q").Fields("results"), err: true},
+		{name: "scalar as edge", query: NewQuery[*pb.Migration]("q").Edge("toVersion"), err: true},
+		{name: "filter by id", query: NewQuery[*pb.Migration]("q").Filter(Eq("id", "0x1")), err: true},
+		{name: "invalid id", query: NewQuery[*pb.Migration]("q").ByIds("1) { uid } #"), err: true},
+		{name: "invalid after", query: NewQuery[*pb.Migration]("q").After("x"), err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dql, vars, err := tt.query.Build()
+
+			if tt.err {
+				if err == nil {
+					t.Errorf("Build() expected an error, got:\n%s", dql)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Build() returned an error: %v", err)
+			}
+
+			if dql != tt.expect {
+				t.Errorf("Build() =\n%s\nexpected:\n%s", dql, tt.expect)
+			}
+
+			if !maps.Equal(vars, tt.vars) {
+				t.Errorf("Build() vars = %v, expected %v", vars, tt.vars)
+			}
+		})
+	}
+}
+
+func Test_Block_SoftDeleted(t *testing.T) {
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test/softDelete.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Note"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("id"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("text"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("deleted_at"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Options: deletedAtOptions()},
+			},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+
+	if err != nil {
+		t.Fatalf("invalid test descriptor: %v", err)
+	}
+
+	desc := fd.Messages().Get(0)
+
+	tests := []struct {
+		name   string
+		block  *Block
+		expect string
+	}{
+		{name: "left out", block: newBlock(desc), expect: " @filter(NOT has(Note.deletedAt))"},
+		{name: "with filters", block: newBlock(desc).Filter(Has("text")), expect: " @filter(has(Note.text) AND NOT has(Note.deletedAt))"},
+		{name: "with deleted", block: newBlock(desc).WithDeleted(), expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fErr := tt.block.filter(newRenderer())
+
+			if fErr != nil {
+				t.Fatalf("filter() returned an error: %v", fErr)
+			}
+
+			if got != tt.expect {
+				t.Errorf("filter() = %q, expected %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+func deletedAtOptions() *descriptorpb.FieldOptions {
+	opts := &descriptorpb.FieldOptions{}
+
+	proto.SetExtension(opts, pb.E_Field, &pb.DgraphField{Timestamp: pb.DgraphField_DELETED_AT})
+
+	return opts
+}
@@ -0,0 +1,190 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Filter a DQL function used as a block's root function or in its @filter directive. values are passed as query
+// variables
+type Filter interface {
+	render(r *renderer, desc protoreflect.MessageDescriptor) (string, error)
+}
+
+type fnFilter struct {
+	fn    string
+	field string
+	value any
+}
+
+func (f *fnFilter) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	pred, pErr := predicate(desc, f.field)
+
+	if pErr != nil {
+		return "", pErr
+	}
+
+	return fmt.Sprintf("%s(%s, %s)", f.fn, pred, r.variable(f.value)), nil
+}
+
+type hasFilter struct {
+	field string
+}
+
+func (f *hasFilter) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	pred, pErr := predicate(desc, f.field)
+
+	if pErr != nil {
+		return "", pErr
+	}
+
+	return fmt.Sprintf("has(%s)", pred), nil
+}
+
+type uidFilter struct {
+	ids []string
+}
+
+func (f *uidFilter) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	if len(f.ids) == 0 {
+		return "", fmt.Errorf("uid requires at least one id")
+	}
+
+	for _, id := range f.ids {
+		if !db.ValidId(id) {
+			// ids are validated rather than passed as variables. only hex uids can get here
+			return "", fmt.Errorf("invalid id %s", id)
+		}
+	}
+
+	return fmt.Sprintf("uid(%s)", strings.Join(f.ids, ", ")), nil
+}
+
+type typeFilter struct {
+	name string
+}
+
+func (f *typeFilter) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	return fmt.Sprintf("type(%s)", f.name), nil
+}
+
+type connective struct {
+	op      string
+	filters []Filter
+}
+
+func (f *connective) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	if len(f.filters) == 0 {
+		return "", fmt.Errorf("%s requires at least one filter", f.op)
+	}
+
+	parts := make([]string, len(f.filters))
+
+	for i, filter := range f.filters {
+		s, err := filter.render(r, desc)
+
+		if err != nil {
+			return "", err
+		}
+
+		parts[i] = s
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	return "(" + strings.Join(parts, " "+f.op+" ") + ")", nil
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f *notFilter) render(r *renderer, desc protoreflect.MessageDescriptor) (string, error) {
+	s, err := f.filter.render(r, desc)
+
+	if err != nil {
+		return "", err
+	}
+
+	return "NOT " + s, nil
+}
+
+// Eq matches models where field equals value
+func Eq(field string, value any) Filter {
+	return &fnFilter{fn: "eq", field: field, value: value}
+}
+
+// Lt matches models where field is less than value
+func Lt(field string, value any) Filter {
+	return &fnFilter{fn: "lt", field: field, value: value}
+}
+
+// Le matches models where field is less than or equal to value
+func Le(field string, value any) Filter {
+	return &fnFilter{fn: "le", field: field, value: value}
+}
+
+// Gt matches models where field is greater than value
+func Gt(field string, value any) Filter {
+	return &fnFilter{fn: "gt", field: field, value: value}
+}
+
+// Ge matches models where field is greater than or equal to value
+func Ge(field string, value any) Filter {
+	return &fnFilter{fn: "ge", field: field, value: value}
+}
+
+// AllOfTerms matches models where field contains all the terms in terms. requires a term index
+func AllOfTerms(field string, terms string) Filter {
+	return &fnFilter{fn: "allofterms", field: field, value: terms}
+}
+
+// AnyOfTerms matches models where field contains any of the terms in terms. requires a term index
+func AnyOfTerms(field string, terms string) Filter {
+	return &fnFilter{fn: "anyofterms", field: field, value: terms}
+}
+
+// AllOfText matches models where field contains all the words in text. requires a fulltext index
+func AllOfText(field string, text string) Filter {
+	return &fnFilter{fn: "alloftext", field: field, value: text}
+}
+
+// AnyOfText matches models where field contains any of the words in text. requires a fulltext index
+func AnyOfText(field string, text string) Filter {
+	return &fnFilter{fn: "anyoftext", field: field, value: text}
+}
+
+// Has matches models that have a value for field
+func Has(field string) Filter {
+	return &hasFilter{field: field}
+}
+
+// Uid matches the models with the ids
+func Uid(ids ...string) Filter {
+	return &uidFilter{ids: ids}
+}
+
+// Type matches models with the dgraph type name
+func Type(name string) Filter {
+	return &typeFilter{name: name}
+}
+
+// And matches models that match all the filters
+func And(filters ...Filter) Filter {
+	return &connective{op: "AND", filters: filters}
+}
+
+// Or matches models that match any of the filters
+func Or(filters ...Filter) Filter {
+	return &connective{op: "OR", filters: filters}
+}
+
+// Not matches models that do not match filter
+func Not(filter Filter) Filter {
+	return &notFilter{filter: filter}
+}
@@ -26,6 +26,8 @@ lastUpdateTime: SchemaMetaData.lastUpdateTime
 lastMigrationTime: SchemaMetaData.lastMigrationTime
 lastMigrationStatus: SchemaMetaData.lastMigrationStatus
 isMigrating: SchemaMetaData.isMigrating
+lockOwner: SchemaMetaData.lockOwner
+lockExpiry: SchemaMetaData.lockExpiry
 migrations: SchemaMetaData.migrations {
   ` + MigrationFragment + `
 }
@@ -38,6 +40,7 @@ id: uid
 fromVersion: Migration.fromVersion
 toVersion: Migration.toVersion
 lastStatus: Migration.lastStatus
+checksum: Migration.checksum
 results: Migration.results {
   ` + MigrationResultFragment + `
 }
@@ -50,5 +53,6 @@ id: uid
 startTime: MigrationResult.startTime
 endTime: MigrationResult.endTime
 status: MigrationResult.status
+revert: MigrationResult.revert
 `
 )
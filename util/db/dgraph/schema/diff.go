@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change a difference between two schemas
+type Change struct {
+	// Kind ChangeAdded, ChangeRemoved or ChangeChanged
+	Kind string
+	// Name the name of the predicate, or type X for types
+	Name string
+	From string
+	To   string
+}
+
+func (c *Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + c.To
+	case ChangeRemoved:
+		return "- " + c.From
+	}
+
+	return "~ " + c.From + "\n  " + c.To
+}
+
+// Diff returns the changes needed to go from the from schema (e.g. a saved schema) to the to schema (e.g. the schema
+// generated from the current proto files). predicates are compared first, then types
+func Diff(from *Schema, to *Schema) []*Change {
+	changes := []*Change{}
+
+	for _, name := range to.predicateNames() {
+		p := to.Predicates[name]
+		fp, ok := from.Predicates[name]
+
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Kind: ChangeAdded, Name: name, To: p.DQL()})
+		case fp.DQL() != p.DQL():
+			changes = append(changes, &Change{Kind: ChangeChanged, Name: name, From: fp.DQL(), To: p.DQL()})
+		}
+	}
+
+	for _, name := range from.predicateNames() {
+		if _, ok := to.Predicates[name]; !ok {
+			changes = append(changes, &Change{Kind: ChangeRemoved, Name: name, From: from.Predicates[name].DQL()})
+		}
+	}
+
+	fromTypes := typesByName(from)
+	toTypes := typesByName(to)
+
+	for _, t := range to.Types {
+		ft, ok := fromTypes[t.Name]
+
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Kind: ChangeAdded, Name: "type " + t.Name, To: typeDQL(t)})
+		case typeDQL(ft) != typeDQL(t):
+			changes = append(changes, &Change{Kind: ChangeChanged, Name: "type " + t.Name, From: typeDQL(ft), To: typeDQL(t)})
+		}
+	}
+
+	for _, t := range from.Types {
+		if _, ok := toTypes[t.Name]; !ok {
+			changes = append(changes, &Change{Kind: ChangeRemoved, Name: "type " + t.Name, From: typeDQL(t)})
+		}
+	}
+
+	return changes
+}
+
+func typesByName(s *Schema) map[string]*Type {
+	types := make(map[string]*Type, len(s.Types))
+
+	for _, t := range s.Types {
+		types[t.Name] = t
+	}
+
+	return types
+}
+
+// typeDQL renders the type on one line. predicates are sorted so the order of the fields does not matter
+func typeDQL(t *Type) string {
+	preds := make([]string, len(t.Fields))
+
+	for i, f := range t.Fields {
+		preds[i] = f.Predicate.Name
+	}
+
+	slices.Sort(preds)
+
+	return "type " + t.Name + " { " + strings.Join(preds, " ") + " }"
+}
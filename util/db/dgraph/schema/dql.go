@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	dqlCommentRegexp   = regexp.MustCompile(`#[^\n]*`)
+	dqlTypeRegexp      = regexp.MustCompile(`(?s)\btype\s+<?([\w.]+)>?\s*\{([^}]*)\}`)
+	dqlPredicateRegexp = regexp.MustCompile(`^<?([^<>\s:]+)>?\s*:\s*(\[?\s*\w+\s*\]?)\s*(.*?)\s*\.$`)
+	dqlDirectiveRegexp = regexp.MustCompile(`@(\w+)(?:\(([^)]*)\))?`)
+)
+
+// DQL renders the schema as a DQL schema, to be applied with an alter operation
+func (s *Schema) DQL() string {
+	sb := &strings.Builder{}
+
+	for _, name := range s.predicateNames() {
+		sb.WriteString(s.Predicates[name].DQL() + "\n")
+	}
+
+	for _, t := range s.Types {
+		sb.WriteString("\ntype " + t.Name + " {\n")
+
+		for _, f := range t.Fields {
+			sb.WriteString("  " + f.Predicate.Name + "\n")
+		}
+
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
+
+// DQL renders the predicate's schema definition
+func (p *Predicate) DQL() string {
+	parts := []string{p.Name + ":", p.Type}
+
+	if len(p.Indexes) > 0 {
+		parts = append(parts, "@index("+strings.Join(p.Indexes, ", ")+")")
+	}
+
+	if p.Reverse {
+		parts = append(parts, "@reverse")
+	}
+
+	if p.Count {
+		parts = append(parts, "@count")
+	}
+
+	if p.Lang {
+		parts = append(parts, "@lang")
+	}
+
+	if p.Upsert {
+		parts = append(parts, "@upsert")
+	}
+
+	if p.Unique {
+		parts = append(parts, "@unique")
+	}
+
+	return strings.Join(parts, " ") + " ."
+}
+
+// ParseDQL parses a DQL schema, e.g. one saved from DQL. the types of the parsed schema only have the names and
+// predicates of their fields
+func ParseDQL(dql string) (*Schema, error) {
+	s := newSchema()
+
+	dql = dqlCommentRegexp.ReplaceAllString(dql, "")
+
+	for _, match := range dqlTypeRegexp.FindAllStringSubmatch(dql, -1) {
+		t := &Type{Name: match[1], Fields: []*Field{}}
+
+		for _, field := range strings.Fields(match[2]) {
+			pred := strings.Trim(field, "<>")
+
+			t.Fields = append(t.Fields, &Field{
+				Name:      pred[strings.LastIndex(pred, ".")+1:],
+				Predicate: &Predicate{Name: pred},
+			})
+		}
+
+		s.Types = append(s.Types, t)
+	}
+
+	dql = dqlTypeRegexp.ReplaceAllStringFunc(dql, func(t string) string {
+		// keep the lines so errors have the right line numbers
+		return strings.Repeat("\n", strings.Count(t, "\n"))
+	})
+
+	for i, line := range strings.Split(dql, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		match := dqlPredicateRegexp.FindStringSubmatch(line)
+
+		if match == nil {
+			return nil, fmt.Errorf("line %d: invalid predicate definition: %s", i+1, line)
+		}
+
+		p := &Predicate{Name: match[1], Type: strings.ReplaceAll(match[2], " ", ""), Indexes: []string{}}
+
+		for _, d := range dqlDirectiveRegexp.FindAllStringSubmatch(match[3], -1) {
+			switch d[1] {
+			case "index":
+				for _, tokenizer := range strings.Split(d[2], ",") {
+					if tokenizer = strings.TrimSpace(tokenizer); tokenizer != "" {
+						p.Indexes = append(p.Indexes, tokenizer)
+					}
+				}
+			case "reverse":
+				p.Reverse = true
+			case "count":
+				p.Count = true
+			case "lang":
+				p.Lang = true
+			case "upsert":
+				p.Upsert = true
+			case "unique":
+				p.Unique = true
+			default:
+				return nil, fmt.Errorf("line %d: unknown directive @%s", i+1, d[1])
+			}
+		}
+
+		s.Predicates[p.Name] = p
+	}
+
+	// link the fields of the types to their predicates
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			if p, ok := s.Predicates[f.Predicate.Name]; ok {
+				f.Predicate = p
+			}
+		}
+	}
+
+	s.sort()
+
+	return s, nil
+}
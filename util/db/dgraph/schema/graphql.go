@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"strings"
+)
+
+// graphqlSearch the GraphQL @search arguments of the DQL index tokenizers that differ
+var graphqlSearch = map[string]string{
+	"trigram": "regexp",
+	"geo":     "point",
+}
+
+// GraphQL renders the schema as GraphQL SDL for Dgraph's GraphQL API. the fields map to the same predicates as the
+// DQL schema (Type.field)
+//
+// enums are stored as their number, so enum fields are Int fields. reverse edges are only available in DQL
+func (s *Schema) GraphQL() string {
+	sb := &strings.Builder{}
+
+	for i, t := range s.Types {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("type " + t.Name + " {\n")
+		sb.WriteString("  " + IdField + ": ID!\n")
+
+		for _, f := range t.Fields {
+			sb.WriteString("  " + f.GraphQL() + "\n")
+		}
+
+		sb.WriteString("}\n")
+	}
+
+	for _, e := range s.Enums {
+		sb.WriteString("\n# " + e.Name + " values are stored as their number: " + strings.Join(e.Values, ", ") + "\n")
+	}
+
+	return sb.String()
+}
+
+// GraphQL renders the field's GraphQL definition
+func (f *Field) GraphQL() string {
+	p := f.Predicate
+	gqlType := graphqlType(p.BaseType())
+
+	if f.Edge != "" {
+		gqlType = f.Edge
+	}
+
+	if p.IsList() {
+		gqlType = "[" + gqlType + "!]"
+	}
+
+	if !f.Optional {
+		gqlType += "!"
+	}
+
+	parts := []string{f.Name + ":", gqlType}
+
+	if len(p.Indexes) > 0 && f.Edge == "" {
+		search := make([]string, len(p.Indexes))
+
+		for i, tokenizer := range p.Indexes {
+			if s, ok := graphqlSearch[tokenizer]; ok {
+				tokenizer = s
+			}
+
+			search[i] = tokenizer
+		}
+
+		parts = append(parts, "@search(by: ["+strings.Join(search, ", ")+"])")
+	}
+
+	if p.Unique && p.BaseType() == "string" {
+		parts = append(parts, "@id")
+	}
+
+	if f.Enum != "" {
+		parts = append(parts, "# "+f.Enum)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func graphqlType(dqlType string) string {
+	switch dqlType {
+	case "int":
+		return "Int"
+	case "float":
+		return "Float"
+	case "bool":
+		return "Boolean"
+	case "datetime":
+		return "DateTime"
+	case "geo":
+		return "Point"
+	case "int64":
+		return "Int64"
+	}
+
+	return "String"
+}
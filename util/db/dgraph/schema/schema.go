@@ -0,0 +1,351 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// IdField the name of the field that holds a model's uid. it does not get a predicate
+	IdField = "id"
+)
+
+// Predicate a Dgraph predicate
+type Predicate struct {
+	Name string
+	// Type the predicate's type, e.g. string, [uid]
+	Type    string
+	Indexes []string
+	Reverse bool
+	Upsert  bool
+	Unique  bool
+	Count   bool
+	Lang    bool
+}
+
+// IsList returns true when the predicate holds a list of values
+func (p *Predicate) IsList() bool {
+	return strings.HasPrefix(p.Type, "[")
+}
+
+// BaseType returns the type of the predicate's values
+func (p *Predicate) BaseType() string {
+	return strings.TrimSuffix(strings.TrimPrefix(p.Type, "["), "]")
+}
+
+// Field a field of a Type
+type Field struct {
+	Name      string
+	Predicate *Predicate
+	// Optional the field may not have a value
+	Optional bool
+	// Edge the name of the type of the related models when the field is an edge
+	Edge string
+	// Enum the name of the enum when the field is an enum
+	Enum string
+}
+
+// Type a Dgraph type
+type Type struct {
+	Name   string
+	Fields []*Field
+}
+
+// Enum a proto enum. enums are stored as ints
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// Schema a Dgraph schema generated from proto messages (see FromMessages) or parsed from DQL (see ParseDQL)
+type Schema struct {
+	Types []*Type
+	Enums []*Enum
+	// Predicates the predicates of the types, by name
+	Predicates map[string]*Predicate
+}
+
+func (s *Schema) sort() {
+	sort.Slice(s.Types, func(i, j int) bool {
+		return s.Types[i].Name < s.Types[j].Name
+	})
+
+	sort.Slice(s.Enums, func(i, j int) bool {
+		return s.Enums[i].Name < s.Enums[j].Name
+	})
+}
+
+// predicateNames returns the names of the predicates in order
+func (s *Schema) predicateNames() []string {
+	names := make([]string, 0, len(s.Predicates))
+
+	for name := range s.Predicates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func newSchema() *Schema {
+	return &Schema{
+		Types:      []*Type{},
+		Enums:      []*Enum{},
+		Predicates: map[string]*Predicate{},
+	}
+}
+
+// FromFiles generates the schema of the messages of the proto files, and of the messages they reference
+func FromFiles(files ...protoreflect.FileDescriptor) (*Schema, error) {
+	msgs := []protoreflect.MessageDescriptor{}
+
+	for _, f := range files {
+		msgs = append(msgs, fileMessages(f.Messages())...)
+	}
+
+	return FromMessages(msgs...)
+}
+
+// FromRegistry generates the schema of the messages of the registered proto files whose path starts with prefix, e.g.
+// FromRegistry(protoregistry.GlobalFiles, "proto/smoxy/")
+func FromRegistry(reg *protoregistry.Files, prefix string) (*Schema, error) {
+	files := []protoreflect.FileDescriptor{}
+
+	reg.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+		if strings.HasPrefix(f.Path(), prefix) {
+			files = append(files, f)
+		}
+
+		return true
+	})
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path() < files[j].Path()
+	})
+
+	return FromFiles(files...)
+}
+
+// FromMessages generates the schema of the messages, and of the messages they reference
+//
+// a message becomes a type named after its go type and each field becomes a predicate named Type.field (the field's
+// json name), the same names the models package uses. the id field holds the uid and does not get a predicate.
+// predicates are configured with the smoxy.util.db.dgraph.field option
+func FromMessages(msgs ...protoreflect.MessageDescriptor) (*Schema, error) {
+	g := &generator{
+		schema: newSchema(),
+		types:  map[protoreflect.FullName]bool{},
+		enums:  map[protoreflect.FullName]bool{},
+	}
+
+	for _, m := range msgs {
+		if err := g.addMessage(m); err != nil {
+			return nil, err
+		}
+	}
+
+	g.schema.sort()
+
+	return g.schema, nil
+}
+
+type generator struct {
+	schema *Schema
+	types  map[protoreflect.FullName]bool
+	enums  map[protoreflect.FullName]bool
+}
+
+func (g *generator) addMessage(m protoreflect.MessageDescriptor) error {
+	if g.types[m.FullName()] || m.IsMapEntry() || typeOptions(m).GetSkip() {
+		return nil
+	}
+
+	g.types[m.FullName()] = true
+
+	t := &Type{Name: TypeName(m), Fields: []*Field{}}
+
+	g.schema.Types = append(g.schema.Types, t)
+
+	for i := 0; i < m.Fields().Len(); i++ {
+		fd := m.Fields().Get(i)
+		opts := FieldOptions(fd)
+
+		if string(fd.Name()) == IdField || opts.GetSkip() {
+			continue
+		}
+
+		if fd.IsMap() {
+			return fmt.Errorf("%s: map fields are not supported", fd.FullName())
+		}
+
+		p, pErr := predicate(t.Name, fd, opts)
+
+		if pErr != nil {
+			return pErr
+		}
+
+		g.schema.Predicates[p.Name] = p
+
+		f := &Field{
+			Name:      fd.JSONName(),
+			Predicate: p,
+			Optional:  fd.HasPresence() || fd.Message() != nil,
+		}
+
+		switch {
+		case fd.Message() != nil && p.BaseType() == "uid":
+			f.Edge = TypeName(fd.Message())
+
+			if err := g.addMessage(fd.Message()); err != nil {
+				return err
+			}
+		case fd.Enum() != nil:
+			f.Enum = enumName(fd.Enum())
+
+			g.addEnum(fd.Enum())
+		}
+
+		t.Fields = append(t.Fields, f)
+	}
+
+	return nil
+}
+
+func (g *generator) addEnum(e protoreflect.EnumDescriptor) {
+	if g.enums[e.FullName()] {
+		return
+	}
+
+	g.enums[e.FullName()] = true
+
+	enum := &Enum{Name: enumName(e), Values: []string{}}
+
+	for i := 0; i < e.Values().Len(); i++ {
+		enum.Values = append(enum.Values, string(e.Values().Get(i).Name()))
+	}
+
+	g.schema.Enums = append(g.schema.Enums, enum)
+}
+
+func predicate(typeName string, fd protoreflect.FieldDescriptor, opts *pb.DgraphField) (*Predicate, error) {
+	p := &Predicate{
+		Name:    typeName + "." + fd.JSONName(),
+		Type:    opts.GetType(),
+		Indexes: slices.Clone(opts.GetIndex()),
+		Reverse: opts.GetReverse(),
+		Upsert:  opts.GetUpsert(),
+		Unique:  opts.GetUnique(),
+		Count:   opts.GetCount(),
+		Lang:    opts.GetLang(),
+	}
+
+	if p.Type == "" {
+		p.Type = scalarType(fd)
+	}
+
+	if fd.IsList() && !strings.HasPrefix(p.Type, "[") {
+		p.Type = "[" + p.Type + "]"
+	}
+
+	switch {
+	case p.Reverse && p.BaseType() != "uid":
+		return nil, fmt.Errorf("%s: reverse requires an edge", fd.FullName())
+	case p.Upsert && len(p.Indexes) == 0:
+		return nil, fmt.Errorf("%s: upsert requires an index", fd.FullName())
+	case p.Unique && (!p.Upsert || len(p.Indexes) == 0):
+		return nil, fmt.Errorf("%s: unique requires an index and upsert", fd.FullName())
+	case p.Lang && p.BaseType() != "string":
+		return nil, fmt.Errorf("%s: lang requires a string", fd.FullName())
+	case opts.GetTimestamp() != pb.DgraphField_NO_TIMESTAMP && (fd.IsList() || !slices.Contains([]string{"string", "int", "datetime"}, p.BaseType()) || fd.Enum() != nil):
+		return nil, fmt.Errorf("%s: timestamp requires a string or an integer", fd.FullName())
+	case opts.GetVersion() && (fd.IsList() || p.BaseType() != "int" || fd.Enum() != nil):
+		return nil, fmt.Errorf("%s: version requires an integer", fd.FullName())
+	case opts.GetOnDelete() != pb.DgraphField_KEEP && p.BaseType() != "uid":
+		return nil, fmt.Errorf("%s: onDelete requires an edge", fd.FullName())
+	}
+
+	return p, nil
+}
+
+// scalarType returns the dgraph type of the values of the field
+func scalarType(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+		protoreflect.EnumKind:
+		// enums are stored as their number
+		return "int"
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "float"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "uid"
+	}
+
+	return "string"
+}
+
+// TypeName returns the dgraph type name of the message: the name of its go type
+func TypeName(m protoreflect.MessageDescriptor) string {
+	name := string(m.Name())
+
+	for p, ok := m.Parent().(protoreflect.MessageDescriptor); ok; p, ok = p.Parent().(protoreflect.MessageDescriptor) {
+		// nested messages are named Parent_Child in go
+		name = string(p.Name()) + "_" + name
+	}
+
+	return name
+}
+
+func enumName(e protoreflect.EnumDescriptor) string {
+	name := string(e.Name())
+
+	for p, ok := e.Parent().(protoreflect.MessageDescriptor); ok; p, ok = p.Parent().(protoreflect.MessageDescriptor) {
+		name = string(p.Name()) + "_" + name
+	}
+
+	return name
+}
+
+func fileMessages(msgs protoreflect.MessageDescriptors) []protoreflect.MessageDescriptor {
+	out := []protoreflect.MessageDescriptor{}
+
+	for i := 0; i < msgs.Len(); i++ {
+		out = append(out, msgs.Get(i))
+		out = append(out, fileMessages(msgs.Get(i).Messages())...)
+	}
+
+	return out
+}
+
+// FieldOptions returns the (smoxy.util.db.dgraph.field) options of the field. nil when the field has none
+func FieldOptions(fd protoreflect.FieldDescriptor) *pb.DgraphField {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+
+	if !ok || opts == nil || !proto.HasExtension(opts, pb.E_Field) {
+		return nil
+	}
+
+	return proto.GetExtension(opts, pb.E_Field).(*pb.DgraphField)
+}
+
+func typeOptions(m protoreflect.MessageDescriptor) *pb.DgraphType {
+	opts, ok := m.Options().(*descriptorpb.MessageOptions)
+
+	if !ok || opts == nil || !proto.HasExtension(opts, pb.E_Type) {
+		return nil
+	}
+
+	return proto.GetExtension(opts, pb.E_Type).(*pb.DgraphType)
+}
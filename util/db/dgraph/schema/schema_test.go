@@ -0,0 +1,197 @@
+package schema
+
+import (
+	"testing"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/schema/schematest"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func Test_FromMessages(t *testing.T) {
+	tags := schematest.Field("tags", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Index: []string{"exact"}})
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	user := schematest.Message(t, "User",
+		schematest.Field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+		schematest.Field("email", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Index: []string{"hash"}, Upsert: true, Unique: true}),
+		schematest.Edge("friends", 3, "User", &pb.DgraphField{Reverse: true, Count: true}),
+		schematest.Field("bio", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Index: []string{"fulltext", "trigram"}, Lang: true}),
+		schematest.Field("secret", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Skip: true}),
+		schematest.Field("created_at", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Type: "datetime", Index: []string{"hour"}}),
+		tags,
+		schematest.Field("age", 8, descriptorpb.FieldDescriptorProto_TYPE_UINT32, nil),
+	)
+
+	s, err := FromMessages(user)
+
+	if err != nil {
+		t.Fatalf("FromMessages() returned an error: %v", err)
+	}
+
+	expectDQL := `User.age: int .
+User.bio: string @index(fulltext, trigram) @lang .
+User.createdAt: datetime @index(hour) .
+User.email: string @index(hash) @upsert @unique .
+User.friends: [uid] @reverse @count .
+User.tags: [string] @index(exact) .
+
+type User {
+  User.email
+  User.friends
+  User.bio
+  User.createdAt
+  User.tags
+  User.age
+}
+`
+
+	if dql := s.DQL(); dql != expectDQL {
+		t.Errorf("DQL() =\n%s\nexpected:\n%s", dql, expectDQL)
+	}
+
+	expectGraphQL := `type User {
+  id: ID!
+  email: String! @search(by: [hash]) @id
+  friends: [User!]
+  bio: String! @search(by: [fulltext, regexp])
+  createdAt: DateTime! @search(by: [hour])
+  tags: [String!]! @search(by: [exact])
+  age: Int!
+}
+`
+
+	if gql := s.GraphQL(); gql != expectGraphQL {
+		t.Errorf("GraphQL() =\n%s\nexpected:\n%s", gql, expectGraphQL)
+	}
+
+	// the saved schema can be parsed and compared
+	parsed, pErr := ParseDQL("# saved schema\n" + s.DQL())
+
+	if pErr != nil {
+		t.Fatalf("ParseDQL() returned an error: %v", pErr)
+	}
+
+	if changes := Diff(parsed, s); len(changes) != 0 {
+		t.Errorf("Diff() of the parsed schema returned changes: %v", changes)
+	}
+}
+
+func Test_FromMessages_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *pb.DgraphField
+	}{
+		{name: "reverse scalar", opts: &pb.DgraphField{Reverse: true}},
+		{name: "upsert without index", opts: &pb.DgraphField{Upsert: true}},
+		{name: "unique without upsert", opts: &pb.DgraphField{Unique: true, Index: []string{"exact"}}},
+		{name: "lang int", opts: &pb.DgraphField{Lang: true, Type: "int"}},
+		{name: "timestamp bool", opts: &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT, Type: "bool"}},
+		{name: "version string", opts: &pb.DgraphField{Version: true}},
+		{name: "onDelete scalar", opts: &pb.DgraphField{OnDelete: pb.DgraphField_CASCADE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := schematest.Message(t, "User", schematest.Field("f", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, tt.opts))
+
+			if _, err := FromMessages(m); err == nil {
+				t.Errorf("FromMessages() expected an error")
+			}
+		})
+	}
+}
+
+func Test_FromRegistry(t *testing.T) {
+	s, err := FromRegistry(protoregistry.GlobalFiles, "proto/smoxy/util/db/dgraph/")
+
+	if err != nil {
+		t.Fatalf("FromRegistry() returned an error: %v", err)
+	}
+
+	names := []string{}
+
+	for _, typ := range s.Types {
+		names = append(names, typ.Name)
+	}
+
+	for _, expect := range []string{"Migration", "MigrationResult", "SchemaMetaData"} {
+		found := false
+
+		for _, name := range names {
+			found = found || name == expect
+		}
+
+		if !found {
+			t.Errorf("FromRegistry() types = %v, missing %s", names, expect)
+		}
+	}
+
+	// the option messages are annotated with skip
+	for _, name := range names {
+		if name == "DgraphField" || name == "DgraphType" {
+			t.Errorf("FromRegistry() types = %v, %s should be skipped", names, name)
+		}
+	}
+
+	if p := s.Predicates["SchemaMetaData.migrations"]; p == nil || p.Type != "[uid]" {
+		t.Errorf("SchemaMetaData.migrations = %v, expected [uid]", p)
+	}
+
+	if p := s.Predicates["Migration.lastStatus"]; p == nil || p.Type != "int" {
+		t.Errorf("Migration.lastStatus = %v, expected int", p)
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	saved, _ := ParseDQL(`
+<User.name>: string @index(exact) .
+User.age: int .
+User.old: string .
+
+type User {
+  User.name
+  User.age
+  User.old
+}
+type Removed {
+}
+`)
+
+	current, _ := ParseDQL(`
+User.name: string @index(exact, term) .
+User.age: int .
+User.email: string .
+
+type User {
+  User.age
+  User.name
+  User.email
+}
+`)
+
+	changes := Diff(saved, current)
+
+	expect := []string{
+		"+ User.email: string .",
+		"~ User.name: string @index(exact) .\n  User.name: string @index(exact, term) .",
+		"- User.old: string .",
+		"~ type User { User.age User.name User.old }\n  type User { User.age User.email User.name }",
+		"- type Removed {  }",
+	}
+
+	if len(changes) != len(expect) {
+		t.Fatalf("Diff() returned %d changes, expected %d: %v", len(changes), len(expect), changes)
+	}
+
+	for i, c := range changes {
+		if c.String() != expect[i] {
+			t.Errorf("change %d = %q, expected %q", i, c.String(), expect[i])
+		}
+	}
+
+	if _, err := ParseDQL("User.name string ."); err == nil {
+		t.Errorf("ParseDQL() of an invalid predicate should return an error")
+	}
+}
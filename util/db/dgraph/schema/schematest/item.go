@@ -0,0 +1,102 @@
+package schematest
+
+import (
+	"reflect"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoimpl"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Item a registered model with the timestamps and the version maintained by the models package, for the tests that
+// need a go type (e.g. to create nquads). equivalent to the generated code of
+//
+//	message Item {
+//	  string id = 1;
+//	  string name = 2 [(smoxy.util.db.dgraph.field) = {index: ["exact"]}];
+//	  int64 count = 3;
+//	  int64 created_at = 4 [(smoxy.util.db.dgraph.field) = {timestamp: CREATED_AT}];
+//	  int64 updated_at = 5 [(smoxy.util.db.dgraph.field) = {timestamp: UPDATED_AT}];
+//	  string deleted_at = 6 [(smoxy.util.db.dgraph.field) = {timestamp: DELETED_AT, type: "datetime"}];
+//	  int64 version = 7 [(smoxy.util.db.dgraph.field) = {version: true}];
+//	}
+type Item struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Count     int64  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	CreatedAt int64  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt int64  `protobuf:"varint,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt string `protobuf:"bytes,6,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Version   int64  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &itemMsgTypes[0]
+
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+
+		return ms
+	}
+
+	return mi.MessageOf(x)
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+var itemMsgTypes = make([]protoimpl.MessageInfo, 1)
+
+func init() {
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("schematest/item.proto"),
+		Package:    proto.String("schematest"),
+		Dependency: []string{pb.File_proto_smoxy_util_db_dgraph_options_proto.Path()},
+		Syntax:     proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Item"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				Field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+				Field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Index: []string{"exact"}}),
+				Field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, nil),
+				Field("created_at", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64, &pb.DgraphField{Timestamp: pb.DgraphField_CREATED_AT}),
+				Field("updated_at", 5, descriptorpb.FieldDescriptorProto_TYPE_INT64, &pb.DgraphField{Timestamp: pb.DgraphField_UPDATED_AT}),
+				Field("deleted_at", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pb.DgraphField{Timestamp: pb.DgraphField_DELETED_AT, Type: "datetime"}),
+				Field("version", 7, descriptorpb.FieldDescriptorProto_TYPE_INT64, &pb.DgraphField{Version: true}),
+			},
+		}},
+	}
+
+	rawDesc, err := proto.Marshal(fdp)
+
+	if err != nil {
+		panic(err)
+	}
+
+	protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf((*Item)(nil)).Elem().PkgPath(),
+			RawDescriptor: rawDesc,
+			NumMessages:   1,
+		},
+		GoTypes:      []any{(*Item)(nil)},
+		MessageInfos: itemMsgTypes,
+	}.Build()
+}
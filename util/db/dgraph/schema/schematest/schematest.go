@@ -0,0 +1,86 @@
+// Package schematest messages with dgraph field options for the tests of the dgraph packages
+package schematest
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Package the proto package of the messages created by Message
+const Package = "test"
+
+// FieldOptions returns field options with the dgraph field options
+func FieldOptions(opts *pb.DgraphField) *descriptorpb.FieldOptions {
+	o := &descriptorpb.FieldOptions{}
+
+	proto.SetExtension(o, pb.E_Field, opts)
+
+	return o
+}
+
+// Field returns an optional field. opts can be nil
+func Field(name string, number int32, t descriptorpb.FieldDescriptorProto_Type, opts *pb.DgraphField) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   t.Enum(),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+
+	if opts != nil {
+		f.Options = FieldOptions(opts)
+	}
+
+	return f
+}
+
+// Edge returns a list of edges to the message typeName of Package. opts can be nil
+func Edge(name string, number int32, typeName string, opts *pb.DgraphField) *descriptorpb.FieldDescriptorProto {
+	f := Field(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, opts)
+	f.TypeName = proto.String("." + Package + "." + typeName)
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	return f
+}
+
+// Message returns the descriptor of the message name of Package. the message is created in a file of its own, with
+// an empty message for every other message its edges refer to. the file is not registered
+func Message(t testing.TB, name string, fields ...*descriptorpb.FieldDescriptorProto) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	messages := []*descriptorpb.DescriptorProto{{Name: proto.String(name), Field: fields}}
+	seen := map[string]bool{name: true}
+
+	for _, f := range fields {
+		ref := strings.TrimPrefix(f.GetTypeName(), "."+Package+".")
+
+		if f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE || seen[ref] {
+			continue
+		}
+
+		seen[ref] = true
+		messages = append(messages, &descriptorpb.DescriptorProto{Name: proto.String(ref)})
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(Package + "/" + strings.ToLower(name) + ".proto"),
+		Package:     proto.String(Package),
+		Syntax:      proto.String("proto3"),
+		MessageType: messages,
+	}
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+
+	if err != nil {
+		t.Fatalf("invalid test descriptor: %v", err)
+	}
+
+	return fd.Messages().Get(0)
+}
@@ -0,0 +1,139 @@
+package dgraph
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230"
+	utilTime "github.com/smoxy-io/goSDK/util/time"
+)
+
+const (
+	DefaultTxnRetries = 5
+	DefaultTxnBackoff = 50 * time.Millisecond
+	DefaultTxnMaxWait = 2 * time.Second
+)
+
+type TxnFn func(ctx context.Context) error
+
+type TxnOptions struct {
+	// Retries the number of times an aborted transaction is retried. defaults to DefaultTxnRetries, < 0 disables
+	// retries
+	Retries int
+	// Backoff the base wait before a retry. it doubles with every retry. defaults to DefaultTxnBackoff
+	Backoff time.Duration
+	// MaxWait the maximum wait before a retry. defaults to DefaultTxnMaxWait
+	MaxWait  time.Duration
+	ReadOnly bool
+}
+
+func (o TxnOptions) retries() int {
+	switch {
+	case o.Retries < 0:
+		return 0
+	case o.Retries == 0:
+		return DefaultTxnRetries
+	}
+
+	return o.Retries
+}
+
+// wait returns the jittered wait before the retry
+func (o TxnOptions) wait(retry int) time.Duration {
+	backoff := o.Backoff
+	maxWait := o.MaxWait
+
+	if backoff <= 0 {
+		backoff = DefaultTxnBackoff
+	}
+
+	if maxWait <= 0 {
+		maxWait = DefaultTxnMaxWait
+	}
+
+	for i := 1; i < retry && backoff < maxWait; i++ {
+		backoff *= 2
+	}
+
+	backoff = min(backoff, maxWait)
+
+	// full jitter: wait a random duration in [backoff/2, backoff]
+	return backoff/2 + utilTime.RandDuration(int(backoff/2/time.Millisecond)+1, time.Millisecond)
+}
+
+// WithTxn runs fn in a transaction and commits it (see WithTxnOptions)
+func WithTxn(ctx context.Context, fn TxnFn) error {
+	return WithTxnOptions(ctx, TxnOptions{}, fn)
+}
+
+// WithTxnOptions runs fn in a transaction and commits it. the transaction is rolled back when fn returns an error or
+// panics (the panic is not recovered)
+//
+// when the transaction is aborted because it conflicts with another transaction, fn is run again in a new transaction
+// after a jittered backoff. fn MUST be safe to run more than once
+//
+// when ctx already has a transaction, fn runs in it as a nested transaction and is not retried. the outermost
+// transaction is committed (and retried) by its owner
+func WithTxnOptions(ctx context.Context, opts TxnOptions, fn TxnFn) error {
+	nested := GetTxn(ctx) != nil
+	retries := opts.retries()
+
+	if nested {
+		retries = 0
+	}
+
+	var err error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(opts.wait(attempt)):
+			}
+		}
+
+		err = runTxn(ctx, opts.ReadOnly, fn)
+
+		if !errors.Is(err, dgo.ErrAborted) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func runTxn(ctx context.Context, readOnly bool, fn TxnFn) (ret error) {
+	txnCtx, sErr := StartTxn(ctx, readOnly)
+
+	if sErr != nil {
+		return sErr
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			_ = Rollback(txnCtx)
+
+			// continue to panic
+			panic(e)
+		}
+	}()
+
+	if err := fn(txnCtx); err != nil {
+		_ = Rollback(txnCtx)
+		return err
+	}
+
+	if readOnly {
+		// read only transactions cannot be committed
+		return nil
+	}
+
+	if err := Commit(txnCtx); err != nil {
+		_ = Rollback(txnCtx)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package dgraph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v230"
+)
+
+func Test_WithTxn(t *testing.T) {
+	// connections are established lazily and transactions without mutations do not contact the server, so no
+	// server is needed
+	opts := TxnOptions{Retries: 3, Backoff: time.Millisecond, MaxWait: 2 * time.Millisecond}
+	errTest := errors.New("test")
+
+	tests := []struct {
+		name   string
+		fails  int
+		err    error
+		calls  int
+		expect error
+	}{
+		{name: "success", calls: 1},
+		{name: "retried", fails: 2, err: dgo.ErrAborted, calls: 3},
+		{name: "retries exhausted", fails: 10, err: dgo.ErrAborted, calls: 4, expect: dgo.ErrAborted},
+		{name: "not retried", fails: 10, err: errTest, calls: 1, expect: errTest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+
+			err := WithTxnOptions(context.Background(), opts, func(ctx context.Context) error {
+				calls++
+
+				if GetTxn(ctx) == nil {
+					t.Errorf("fn should run in a transaction")
+				}
+
+				if calls <= tt.fails {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			if !errors.Is(err, tt.expect) || (tt.expect == nil && err != nil) {
+				t.Errorf("WithTxnOptions() = %v, expected %v", err, tt.expect)
+			}
+
+			if calls != tt.calls {
+				t.Errorf("fn called %d times, expected %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func Test_WithTxn_Nested(t *testing.T) {
+	ctx, _ := StartTxn(context.Background())
+	calls := 0
+
+	err := WithTxnOptions(ctx, TxnOptions{Backoff: time.Millisecond}, func(nCtx context.Context) error {
+		calls++
+
+		if GetTxn(nCtx) != GetTxn(ctx) {
+			t.Errorf("nested fn should run in the outer transaction")
+		}
+
+		return dgo.ErrAborted
+	})
+
+	if !errors.Is(err, dgo.ErrAborted) || calls != 1 {
+		t.Errorf("nested transactions should not be retried, got %v after %d calls", err, calls)
+	}
+}
+
+func Test_WithTxn_Panic(t *testing.T) {
+	var txnCtx context.Context
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Fatalf("WithTxn() should not recover the panic")
+		}
+
+		if err := GetTxn(txnCtx).Commit(context.Background()); !errors.Is(err, dgo.ErrFinished) {
+			t.Errorf("transaction should be rolled back after a panic, commit returned %v", err)
+		}
+	}()
+
+	_ = WithTxn(context.Background(), func(ctx context.Context) error {
+		txnCtx = ctx
+
+		panic("test")
+	})
+}
+
+func Test_AfterCommit(t *testing.T) {
+	ran := []string{}
+
+	AfterCommit(context.Background(), func() {
+		ran = append(ran, "no txn")
+	})
+
+	if len(ran) != 1 {
+		t.Fatalf("without a transaction the hook should run immediately")
+	}
+
+	errTest := errors.New("test")
+
+	err := WithTxn(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() {
+			ran = append(ran, "rolled back")
+		})
+
+		return errTest
+	})
+
+	if !errors.Is(err, errTest) {
+		t.Errorf("WithTxn() = %v, expected %v", err, errTest)
+	}
+
+	err = WithTxn(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() {
+			ran = append(ran, "outer")
+		})
+
+		return WithTxn(ctx, func(nCtx context.Context) error {
+			AfterCommit(nCtx, func() {
+				ran = append(ran, "nested")
+			})
+
+			if len(ran) != 1 {
+				t.Errorf("hooks ran before the transaction was committed: %v", ran)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		t.Errorf("WithTxn() returned an error: %v", err)
+	}
+
+	if strings.Join(ran, ", ") != "no txn, outer, nested" {
+		t.Errorf("hooks ran: %v, expected [no txn outer nested]", ran)
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+
+	"github.com/dgraph-io/dgo/v230"
+	db "github.com/smoxy-io/goSDK/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/db/dgraph/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// DgraphRepository a Repository backed by Dgraph. uses the client and transaction in the context (see db.GetClient
+// and db.StartTxn)
+//
+// GetByField requires the field's predicate to be indexed
+type DgraphRepository[T proto.Message] struct{}
+
+func (r *DgraphRepository[T]) Get(ctx context.Context, id string) (T, error) {
+	m, err := models.Get[T](ctx, id)
+
+	return m, dgraphErr(err)
+}
+
+func (r *DgraphRepository[T]) Set(ctx context.Context, m T) error {
+	return dgraphErr(models.Set(ctx, m))
+}
+
+func (r *DgraphRepository[T]) Delete(ctx context.Context, id string) error {
+	return dgraphErr(models.Delete(ctx, id))
+}
+
+func (r *DgraphRepository[T]) GetByField(ctx context.Context, field string, value any) ([]T, error) {
+	m, err := models.GetByField[T](ctx, field, value)
+
+	return m, dgraphErr(err)
+}
+
+func (r *DgraphRepository[T]) Increment(ctx context.Context, id string, field string, delta int64) error {
+	m := newModel[T]()
+
+	setId(m, id)
+
+	return dgraphErr(models.Increment(ctx, m, field, delta))
+}
+
+func (r *DgraphRepository[T]) StartTxn(ctx context.Context, readOnly ...bool) (context.Context, error) {
+	return db.StartTxn(ctx, readOnly...)
+}
+
+func (r *DgraphRepository[T]) Commit(ctx context.Context) error {
+	return dgraphErr(db.Commit(ctx))
+}
+
+func (r *DgraphRepository[T]) Rollback(ctx context.Context) error {
+	return dgraphErr(db.Rollback(ctx))
+}
+
+func NewDgraphRepository[T proto.Message]() *DgraphRepository[T] {
+	return &DgraphRepository[T]{}
+}
+
+// dgraphErr converts dgo's transaction errors to the repository's errors
+func dgraphErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case stdErrors.Is(err, dgo.ErrAborted):
+		return ErrAborted
+	case stdErrors.Is(err, dgo.ErrFinished):
+		return ErrFinished
+	case stdErrors.Is(err, dgo.ErrReadOnly):
+		return ErrReadOnly
+	}
+
+	return err
+}
@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/smoxy-io/goSDK/util/db/dgraph/models"
+	"github.com/smoxy-io/goSDK/util/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	memTxnCtxKey      = "memory-db-txn"
+	memTxnDepthCtxKey = "memory-db-txn-depth"
+)
+
+var (
+	// lastMemoryId ids are unique across all memory repositories, like uids are unique across a Dgraph database
+	lastMemoryId = &atomic.Uint64{}
+
+	// lastCommit the sequence number of the last commit to any memory repository. transactions see the models as they
+	// were at the commit that was last when they started (their snapshot)
+	lastCommit = &atomic.Uint64{}
+
+	// commitLock serializes the commits to memory repositories
+	commitLock = &sync.Mutex{}
+)
+
+// MemoryRepository an in-memory Repository for tests and local development
+//
+// models are copied when they are written and read, so changes to a model are only visible after it is set.
+// transactions are shared by all memory repositories, so a single transaction can write models of different types.
+// older versions of the models are not kept, so reading a model that was changed after the transaction started fails
+// with ErrAborted instead of returning the version of the transaction's snapshot
+type MemoryRepository[T proto.Message] struct {
+	models map[string]T
+	// versions the commit that last wrote each model. kept for deleted models
+	versions map[string]uint64
+	lock     *sync.RWMutex
+}
+
+func (r *MemoryRepository[T]) Get(ctx context.Context, id string) (T, error) {
+	var nilT T
+
+	txn, tErr := getMemTxn(ctx)
+
+	if tErr != nil {
+		return nilT, tErr
+	}
+
+	if txn != nil {
+		if w, ok := txn.write(r, id); ok {
+			if w.model == nil {
+				// deleted in the transaction
+				return nilT, errors.ErrNotFound
+			}
+
+			return proto.Clone(w.model).(T), nil
+		}
+	}
+
+	r.lock.RLock()
+	m, ok := r.models[id]
+	version := r.versions[id]
+	r.lock.RUnlock()
+
+	if txn != nil && version > txn.start {
+		// changed after the transaction's snapshot
+		return nilT, ErrAborted
+	}
+
+	if !ok {
+		return nilT, errors.ErrNotFound
+	}
+
+	return proto.Clone(m).(T), nil
+}
+
+func (r *MemoryRepository[T]) Set(ctx context.Context, m T) error {
+	id := getId(m)
+
+	if id == "" {
+		if idField(m.ProtoReflect()) == nil {
+			return fmt.Errorf("model %s does not have a string %s field", m.ProtoReflect().Descriptor().FullName(), IdField)
+		}
+
+		id = "0x" + strconv.FormatUint(lastMemoryId.Add(1), 16)
+
+		setId(m, id)
+	}
+
+	return r.write(ctx, id, proto.Clone(m))
+}
+
+func (r *MemoryRepository[T]) Delete(ctx context.Context, id string) error {
+	return r.write(ctx, id, nil)
+}
+
+func (r *MemoryRepository[T]) GetByField(ctx context.Context, field string, value any) ([]T, error) {
+	// fields are resolved like the dgraph implementation does
+	fd, fErr := models.ModelField(newModel[T]().ProtoReflect().Descriptor(), field)
+
+	if fErr != nil {
+		return nil, fErr
+	}
+
+	txn, tErr := getMemTxn(ctx)
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	r.lock.RLock()
+
+	candidates := make(map[string]proto.Message, len(r.models))
+
+	for id, m := range r.models {
+		candidates[id] = m
+	}
+
+	changed := false
+
+	for _, version := range r.versions {
+		if txn != nil && version > txn.start {
+			changed = true
+			break
+		}
+	}
+
+	r.lock.RUnlock()
+
+	if changed {
+		// a model was changed after the transaction's snapshot
+		return nil, ErrAborted
+	}
+
+	if txn != nil {
+		// the transaction's own writes replace the stored models
+		for id, w := range txn.writes(r) {
+			if w.model == nil {
+				delete(candidates, id)
+				continue
+			}
+
+			candidates[id] = w.model
+		}
+	}
+
+	found := []T{}
+
+	for _, m := range candidates {
+		if fieldEquals(m.ProtoReflect().Get(fd), fd, value) {
+			found = append(found, proto.Clone(m).(T))
+		}
+	}
+
+	return found, nil
+}
+
+func (r *MemoryRepository[T]) Increment(ctx context.Context, id string, field string, delta int64) error {
+	txn, tErr := getMemTxn(ctx)
+
+	if tErr != nil {
+		return tErr
+	}
+
+	if txn != nil {
+		m, gErr := r.Get(ctx, id)
+
+		if gErr != nil {
+			if errors.ErrNotFound.SameAs(gErr) {
+				// nothing to increment
+				return nil
+			}
+
+			return gErr
+		}
+
+		if err := increment(m, field, delta); err != nil {
+			return err
+		}
+
+		return txn.set(r, id, m)
+	}
+
+	// hold the locks while incrementing so concurrent increments are not lost
+	commitLock.Lock()
+	defer commitLock.Unlock()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	m, ok := r.models[id]
+
+	if !ok {
+		// nothing to increment
+		return nil
+	}
+
+	m = proto.Clone(m).(T)
+
+	if err := increment(m, field, delta); err != nil {
+		return err
+	}
+
+	r.apply(map[string]*memWrite{id: {model: m}})
+
+	return nil
+}
+
+func (r *MemoryRepository[T]) StartTxn(ctx context.Context, readOnly ...bool) (context.Context, error) {
+	if t, _ := ctx.Value(memTxnCtxKey).(*memTxn); t != nil {
+		// context already has an active transaction. increment transaction depth
+		depth, _ := ctx.Value(memTxnDepthCtxKey).(int)
+
+		return context.WithValue(ctx, memTxnDepthCtxKey, depth+1), nil
+	}
+
+	return context.WithValue(ctx, memTxnCtxKey, newMemTxn(len(readOnly) > 0 && readOnly[0])), nil
+}
+
+func (r *MemoryRepository[T]) Commit(ctx context.Context) error {
+	if depth, _ := ctx.Value(memTxnDepthCtxKey).(int); depth != 0 {
+		// this is a nested transaction. the actual commit will be done at the top level context
+		return nil
+	}
+
+	txn, _ := ctx.Value(memTxnCtxKey).(*memTxn)
+
+	if txn == nil {
+		// no txn to commit
+		return nil
+	}
+
+	return txn.commit()
+}
+
+func (r *MemoryRepository[T]) Rollback(ctx context.Context) error {
+	txn, _ := ctx.Value(memTxnCtxKey).(*memTxn)
+
+	if txn == nil {
+		// no txn to rollback
+		return nil
+	}
+
+	txn.discard()
+
+	return nil
+}
+
+// write sets (or deletes when m is nil) the model with the id. the write is only applied when the context's
+// transaction is committed
+func (r *MemoryRepository[T]) write(ctx context.Context, id string, m proto.Message) error {
+	txn, tErr := getMemTxn(ctx)
+
+	if tErr != nil {
+		return tErr
+	}
+
+	if txn != nil {
+		return txn.set(r, id, m)
+	}
+
+	commitLock.Lock()
+	defer commitLock.Unlock()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.apply(map[string]*memWrite{id: {model: m}})
+
+	return nil
+}
+
+// conflicts returns true when one of the models was written after the commit start
+func (r *MemoryRepository[T]) conflicts(writes map[string]*memWrite, start uint64) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for id := range writes {
+		if r.versions[id] > start {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *MemoryRepository[T]) commitWrites(writes map[string]*memWrite) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.apply(writes)
+}
+
+// apply MUST be called with commitLock and the write lock held
+func (r *MemoryRepository[T]) apply(writes map[string]*memWrite) {
+	version := lastCommit.Add(1)
+
+	for id, w := range writes {
+		r.versions[id] = version
+
+		if w.model == nil {
+			delete(r.models, id)
+			continue
+		}
+
+		r.models[id] = w.model.(T)
+	}
+}
+
+func NewMemoryRepository[T proto.Message]() *MemoryRepository[T] {
+	return &MemoryRepository[T]{
+		models:   map[string]T{},
+		versions: map[string]uint64{},
+		lock:     &sync.RWMutex{},
+	}
+}
+
+// memStore the part of a MemoryRepository used by transactions
+type memStore interface {
+	conflicts(writes map[string]*memWrite, start uint64) bool
+	commitWrites(writes map[string]*memWrite)
+}
+
+type memWrite struct {
+	// model the written model. nil when the model was deleted
+	model proto.Message
+}
+
+type memTxn struct {
+	// start the last commit when the transaction started
+	start    uint64
+	readOnly bool
+	finished bool
+	pending  map[memStore]map[string]*memWrite
+	lock     *sync.Mutex
+}
+
+func (t *memTxn) write(s memStore, id string) (*memWrite, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	w, ok := t.pending[s][id]
+
+	return w, ok
+}
+
+func (t *memTxn) writes(s memStore) map[string]*memWrite {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	writes := make(map[string]*memWrite, len(t.pending[s]))
+
+	for id, w := range t.pending[s] {
+		writes[id] = w
+	}
+
+	return writes
+}
+
+func (t *memTxn) set(s memStore, id string, m proto.Message) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.finished {
+		return ErrFinished
+	}
+
+	if t.readOnly {
+		return ErrReadOnly
+	}
+
+	if _, ok := t.pending[s]; !ok {
+		t.pending[s] = map[string]*memWrite{}
+	}
+
+	t.pending[s][id] = &memWrite{model: m}
+
+	return nil
+}
+
+func (t *memTxn) commit() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.readOnly {
+		return ErrReadOnly
+	}
+
+	if t.finished {
+		return ErrFinished
+	}
+
+	t.finished = true
+
+	commitLock.Lock()
+	defer commitLock.Unlock()
+
+	for s, writes := range t.pending {
+		if s.conflicts(writes, t.start) {
+			return ErrAborted
+		}
+	}
+
+	for s, writes := range t.pending {
+		s.commitWrites(writes)
+	}
+
+	return nil
+}
+
+func (t *memTxn) discard() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.finished = true
+	t.pending = map[memStore]map[string]*memWrite{}
+}
+
+func newMemTxn(readOnly bool) *memTxn {
+	// no commit is in progress when the snapshot is taken
+	commitLock.Lock()
+	defer commitLock.Unlock()
+
+	return &memTxn{
+		start:    lastCommit.Load(),
+		readOnly: readOnly,
+		pending:  map[memStore]map[string]*memWrite{},
+		lock:     &sync.Mutex{},
+	}
+}
+
+// getMemTxn returns the context's memory transaction. returns ErrFinished when it has been committed or discarded
+func getMemTxn(ctx context.Context) (*memTxn, error) {
+	txn, _ := ctx.Value(memTxnCtxKey).(*memTxn)
+
+	if txn == nil {
+		return nil, nil
+	}
+
+	txn.lock.Lock()
+	defer txn.lock.Unlock()
+
+	if txn.finished {
+		return nil, ErrFinished
+	}
+
+	return txn, nil
+}
+
+// increment adds delta to the numeric field of m
+func increment(m proto.Message, field string, delta int64) error {
+	pm := m.ProtoReflect()
+	fd := pm.Descriptor().Fields().ByName(protoreflect.Name(field))
+
+	if fd == nil || fd.IsList() || fd.IsMap() {
+		return fmt.Errorf("unknown field %s", field)
+	}
+
+	v := pm.Get(fd)
+
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v = protoreflect.ValueOfInt32(int32(v.Int() + delta))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v = protoreflect.ValueOfInt64(v.Int() + delta)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v = protoreflect.ValueOfUint32(uint32(int64(v.Uint()) + delta))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v = protoreflect.ValueOfUint64(uint64(int64(v.Uint()) + delta))
+	case protoreflect.FloatKind:
+		v = protoreflect.ValueOfFloat32(float32(v.Float() + float64(delta)))
+	case protoreflect.DoubleKind:
+		v = protoreflect.ValueOfFloat64(v.Float() + float64(delta))
+	default:
+		return fmt.Errorf("field %s is not a number", field)
+	}
+
+	pm.Set(fd, v)
+
+	return nil
+}
+
+// fieldEquals compares the value of a scalar field to value. values are compared as strings, the same way they are
+// passed to Dgraph queries
+func fieldEquals(v protoreflect.Value, fd protoreflect.FieldDescriptor, value any) bool {
+	if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return false
+	}
+
+	if e, ok := value.(protoreflect.Enum); ok {
+		value = e.Number()
+	}
+
+	return fmt.Sprint(v.Interface()) == fmt.Sprint(value)
+}
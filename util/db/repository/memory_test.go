@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/smoxy-io/goSDK/pkg/proto/smoxy/util/db/dgraph"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+func Test_MemoryRepository(t *testing.T) {
+	var repo Repository[*pb.Migration] = NewMemoryRepository[*pb.Migration]()
+
+	ctx := context.Background()
+
+	m := &pb.Migration{FromVersion: "v1", ToVersion: "v2"}
+
+	if err := repo.Set(ctx, m); err != nil {
+		t.Fatalf("Set() returned an error: %v", err)
+	}
+
+	if m.GetId() == "" {
+		t.Fatalf("Set() did not set the id of a new model")
+	}
+
+	// changes are only visible after the model is set
+	m.ToVersion = "v3"
+
+	got, gErr := repo.Get(ctx, m.GetId())
+
+	if gErr != nil {
+		t.Fatalf("Get() returned an error: %v", gErr)
+	}
+
+	if got.GetToVersion() != "v2" {
+		t.Errorf("Get() toVersion = %s, expected v2", got.GetToVersion())
+	}
+
+	found, fErr := repo.GetByField(ctx, "fromVersion", "v1")
+
+	if fErr != nil || len(found) != 1 || found[0].GetId() != m.GetId() {
+		t.Errorf("GetByField() = %v, %v, expected the model", found, fErr)
+	}
+
+	for _, field := range []string{"missing", "id", "fromVersion, $value) OR has(Migration.id"} {
+		if _, err := repo.GetByField(ctx, field, "v1"); err == nil {
+			t.Errorf("GetByField(%q) should return an error", field)
+		}
+	}
+
+	if err := repo.Increment(ctx, m.GetId(), "fromVersion", 1); err == nil {
+		t.Errorf("Increment() of a string field should return an error")
+	}
+
+	if err := repo.Increment(ctx, "0xmissing", "fromVersion", 1); err != nil {
+		t.Errorf("Increment() of a missing model should do nothing, got error: %v", err)
+	}
+
+	if err := repo.Delete(ctx, m.GetId()); err != nil {
+		t.Fatalf("Delete() returned an error: %v", err)
+	}
+
+	if _, err := repo.Get(ctx, m.GetId()); !errors.ErrNotFound.SameAs(err) {
+		t.Errorf("Get() after Delete() returned %v, expected not found", err)
+	}
+}
+
+func Test_MemoryRepository_Txn(t *testing.T) {
+	repo := NewMemoryRepository[*pb.Migration]()
+	results := NewMemoryRepository[*pb.MigrationResult]()
+
+	ctx := context.Background()
+
+	existing := &pb.Migration{FromVersion: "v1"}
+	_ = repo.Set(ctx, existing)
+
+	t.Run("commit", func(t *testing.T) {
+		txnCtx, _ := repo.StartTxn(ctx)
+
+		m := &pb.Migration{FromVersion: "v2"}
+		r := &pb.MigrationResult{StartTime: "now"}
+
+		_ = repo.Set(txnCtx, m)
+		_ = results.Set(txnCtx, r)
+		_ = repo.Delete(txnCtx, existing.GetId())
+
+		// the transaction sees its own writes
+		if _, err := repo.Get(txnCtx, m.GetId()); err != nil {
+			t.Errorf("Get() in the transaction returned an error: %v", err)
+		}
+
+		if _, err := repo.Get(txnCtx, existing.GetId()); !errors.ErrNotFound.SameAs(err) {
+			t.Errorf("Get() of a model deleted in the transaction returned %v, expected not found", err)
+		}
+
+		// others do not
+		if _, err := repo.Get(ctx, m.GetId()); !errors.ErrNotFound.SameAs(err) {
+			t.Errorf("Get() outside the transaction returned %v, expected not found", err)
+		}
+
+		// nested transactions are committed by the outermost commit
+		nestedCtx, _ := repo.StartTxn(txnCtx)
+
+		if err := repo.Commit(nestedCtx); err != nil {
+			t.Fatalf("nested Commit() returned an error: %v", err)
+		}
+
+		if _, err := results.Get(ctx, r.GetId()); !errors.ErrNotFound.SameAs(err) {
+			t.Errorf("nested Commit() should not commit the transaction")
+		}
+
+		if err := results.Commit(txnCtx); err != nil {
+			t.Fatalf("Commit() returned an error: %v", err)
+		}
+
+		if _, err := repo.Get(ctx, m.GetId()); err != nil {
+			t.Errorf("Get() after Commit() returned an error: %v", err)
+		}
+
+		if _, err := results.Get(ctx, r.GetId()); err != nil {
+			t.Errorf("Get() of a model of another repository after Commit() returned an error: %v", err)
+		}
+
+		if _, err := repo.Get(ctx, existing.GetId()); !errors.ErrNotFound.SameAs(err) {
+			t.Errorf("model deleted in the transaction still exists after Commit()")
+		}
+
+		if err := repo.Set(txnCtx, &pb.Migration{}); !ErrFinished.SameAs(err) {
+			t.Errorf("Set() after Commit() returned %v, expected %v", err, ErrFinished)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		txnCtx, _ := repo.StartTxn(ctx)
+		nestedCtx, _ := repo.StartTxn(txnCtx)
+
+		m := &pb.Migration{FromVersion: "rolled back"}
+		_ = repo.Set(nestedCtx, m)
+
+		_ = repo.Rollback(nestedCtx)
+
+		if err := repo.Commit(txnCtx); !ErrFinished.SameAs(err) {
+			t.Errorf("Commit() after Rollback() returned %v, expected %v", err, ErrFinished)
+		}
+
+		if _, err := repo.Get(ctx, m.GetId()); !errors.ErrNotFound.SameAs(err) {
+			t.Errorf("rolled back model exists")
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		m := &pb.Migration{FromVersion: "v1"}
+		_ = repo.Set(ctx, m)
+
+		txnCtx, _ := repo.StartTxn(ctx)
+
+		_ = repo.Set(txnCtx, &pb.Migration{Id: m.GetId(), FromVersion: "txn"})
+		_ = repo.Set(ctx, &pb.Migration{Id: m.GetId(), FromVersion: "other"})
+
+		if err := repo.Commit(txnCtx); !ErrAborted.SameAs(err) {
+			t.Errorf("Commit() of a conflicting transaction returned %v, expected %v", err, ErrAborted)
+		}
+
+		got, _ := repo.Get(ctx, m.GetId())
+
+		if got.GetFromVersion() != "other" {
+			t.Errorf("aborted transaction was written, fromVersion = %s", got.GetFromVersion())
+		}
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		m := &pb.Migration{FromVersion: "v1"}
+		_ = repo.Set(ctx, m)
+
+		txnCtx, _ := repo.StartTxn(ctx)
+
+		// read-modify-write of a model that is changed by another commit
+		got, gErr := repo.Get(txnCtx, m.GetId())
+
+		if gErr != nil {
+			t.Fatalf("Get() in the transaction returned an error: %v", gErr)
+		}
+
+		_ = repo.Set(ctx, &pb.Migration{Id: m.GetId(), FromVersion: "other"})
+
+		got.FromVersion = got.GetFromVersion() + "-txn"
+
+		_ = repo.Set(txnCtx, got)
+
+		if err := repo.Commit(txnCtx); !ErrAborted.SameAs(err) {
+			t.Errorf("Commit() of a stale read-modify-write returned %v, expected %v", err, ErrAborted)
+		}
+
+		// reads of models changed after the transaction started
+		txnCtx, _ = repo.StartTxn(ctx)
+
+		_ = repo.Set(ctx, &pb.Migration{Id: m.GetId(), FromVersion: "newer"})
+
+		if _, err := repo.Get(txnCtx, m.GetId()); !ErrAborted.SameAs(err) {
+			t.Errorf("Get() of a model changed after the snapshot returned %v, expected %v", err, ErrAborted)
+		}
+
+		if _, err := repo.GetByField(txnCtx, "fromVersion", "newer"); !ErrAborted.SameAs(err) {
+			t.Errorf("GetByField() after a change to the snapshot returned %v, expected %v", err, ErrAborted)
+		}
+
+		if err := repo.Increment(txnCtx, m.GetId(), "fromVersion", 1); !ErrAborted.SameAs(err) {
+			t.Errorf("Increment() of a model changed after the snapshot returned %v, expected %v", err, ErrAborted)
+		}
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		txnCtx, _ := repo.StartTxn(ctx, true)
+
+		if err := repo.Set(txnCtx, &pb.Migration{}); !ErrReadOnly.SameAs(err) {
+			t.Errorf("Set() in a read only transaction returned %v, expected %v", err, ErrReadOnly)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// IdField the name of the field that holds a model's id
+	IdField = "id"
+)
+
+var (
+	ErrAborted  = errors.New("transaction has been aborted. please retry")
+	ErrFinished = errors.New("transaction has already been committed or discarded")
+	ErrReadOnly = errors.New("readonly transaction cannot run mutations or be committed")
+)
+
+// Repository stores models of type T, independent of the database backing it
+//
+// Get MUST return errors.ErrNotFound when the model does not exist. Set MUST set the id of new models (models without
+// an id). incrementing a model that does not exist does nothing
+//
+// when the context has a transaction started by StartTxn, reads see the transaction's own writes and writes are only
+// visible to others after the outermost Commit. a transaction that conflicts with one committed after it started fails
+// to commit with ErrAborted. Rollback discards the whole transaction, including its nested transactions
+type Repository[T proto.Message] interface {
+	Get(ctx context.Context, id string) (T, error)
+	Set(ctx context.Context, m T) error
+	Delete(ctx context.Context, id string) error
+	// GetByField returns the models that have value in field
+	GetByField(ctx context.Context, field string, value any) ([]T, error)
+	Increment(ctx context.Context, id string, field string, delta int64) error
+
+	StartTxn(ctx context.Context, readOnly ...bool) (context.Context, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// newModel returns a new, empty model of type T
+func newModel[T proto.Message]() T {
+	var m T
+
+	return m.ProtoReflect().Type().New().Interface().(T)
+}
+
+// idField returns the descriptor of the id field of m. returns nil when m does not have a string id field
+func idField(m protoreflect.Message) protoreflect.FieldDescriptor {
+	fd := m.Descriptor().Fields().ByName(IdField)
+
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return nil
+	}
+
+	return fd
+}
+
+func getId(m proto.Message) string {
+	pm := m.ProtoReflect()
+	fd := idField(pm)
+
+	if fd == nil {
+		return ""
+	}
+
+	return pm.Get(fd).String()
+}
+
+func setId(m proto.Message, id string) {
+	pm := m.ProtoReflect()
+	fd := idField(pm)
+
+	if fd == nil {
+		return
+	}
+
+	pm.Set(fd, protoreflect.ValueOfString(id))
+}
@@ -58,6 +58,11 @@ func (er *EventRouter) Start() {
 	er.eventWg.Add(1)
 }
 
+// IsRunning returns true when the router is started and not stopped
+func (er *EventRouter) IsRunning() bool {
+	return er.eventChan != nil
+}
+
 func (er *EventRouter) Stop() {
 	if er.eventChan == nil {
 		return
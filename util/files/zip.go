@@ -2,27 +2,83 @@ package files
 
 import (
 	"archive/zip"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/smoxy-io/goSDK/util/crypto"
 )
 
 func Zip(path string) (string, error) {
 	zipFile := strings.TrimSuffix(path, PathSeparator) + ".zip"
 
+	if err := writeZip(path, zipFile, nil); err != nil {
+		return "", err
+	}
+
+	_ = os.RemoveAll(path)
+
+	return zipFile, nil
+}
+
+// ZipEncrypted zips path like Zip but encrypts the archive while it is written, so the unencrypted archive never
+// touches the disk. the archive is written to path + ".zip.enc"
+func ZipEncrypted(path string, key crypto.StreamEncrypter) (string, error) {
+	zipFile := strings.TrimSuffix(path, PathSeparator) + ".zip.enc"
+
+	if err := writeZip(path, zipFile, key); err != nil {
+		return "", err
+	}
+
+	_ = os.RemoveAll(path)
+
+	return zipFile, nil
+}
+
+func writeZip(path string, zipFile string, key crypto.StreamEncrypter) error {
 	zFile, zfErr := os.Create(zipFile)
 
 	if zfErr != nil {
-		return "", zfErr
+		return zfErr
 	}
 
 	defer zFile.Close()
 
-	zw := zip.NewWriter(zFile)
+	if key == nil {
+		if err := zipTo(path, zFile); err != nil {
+			_ = os.Remove(zipFile)
+			return err
+		}
 
-	defer zw.Close()
+		return nil
+	}
+
+	ew, ewErr := key.EncryptWriter(zFile)
+
+	if ewErr != nil {
+		_ = os.Remove(zipFile)
+		return ewErr
+	}
+
+	if err := zipTo(path, ew); err != nil {
+		_ = os.Remove(zipFile)
+		return err
+	}
+
+	// writes the final encrypted chunk
+	if err := ew.Close(); err != nil {
+		_ = os.Remove(zipFile)
+		return err
+	}
+
+	return nil
+}
+
+func zipTo(path string, w io.Writer) error {
+	zw := zip.NewWriter(w)
 
 	if err := filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -59,12 +115,11 @@ func Zip(path string) (string, error) {
 
 		return nil
 	}); err != nil {
-		return "", err
+		_ = zw.Close()
+		return err
 	}
 
-	_ = os.RemoveAll(path)
-
-	return zipFile, nil
+	return zw.Close()
 }
 
 func Unzip(zipFile string, dest string) error {
@@ -76,7 +131,73 @@ func Unzip(zipFile string, dest string) error {
 
 	defer z.Close()
 
+	if err := extract(&z.Reader, dest); err != nil {
+		return err
+	}
+
+	_ = z.Close()
+	_ = os.Remove(zipFile)
+
+	return nil
+}
+
+// UnzipEncrypted decrypts and extracts an archive written by ZipEncrypted. the whole archive is authenticated before
+// anything is extracted. zip archives need random access, so the files are then extracted from a reader that
+// decrypts one chunk at a time: the unencrypted archive never touches the disk and does not have to fit in memory
+func UnzipEncrypted(zipFile string, dest string, key crypto.StreamDecrypter) error {
+	src, sErr := os.Open(zipFile)
+
+	if sErr != nil {
+		return sErr
+	}
+
+	defer src.Close()
+
+	info, iErr := src.Stat()
+
+	if iErr != nil {
+		return iErr
+	}
+
+	r, rErr := key.DecryptReader(src)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	ra, size, raErr := key.DecryptReaderAt(src, info.Size())
+
+	if raErr != nil {
+		return raErr
+	}
+
+	z, zErr := zip.NewReader(ra, size)
+
+	if zErr != nil {
+		return zErr
+	}
+
+	if err := extract(z, dest); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	_ = os.Remove(zipFile)
+
+	return nil
+}
+
+func extract(z *zip.Reader, dest string) error {
 	for _, f := range z.File {
+		if !filepath.IsLocal(filepath.FromSlash(f.Name)) {
+			// the file would be written outside of dest
+			return fmt.Errorf("invalid file name in archive: %s", f.Name)
+		}
+
 		fPath := filepath.Join(dest, f.Name)
 
 		if f.FileInfo().IsDir() {
@@ -87,6 +208,10 @@ func Unzip(zipFile string, dest string) error {
 			continue
 		}
 
+		if err := os.MkdirAll(filepath.Dir(fPath), 0750); err != nil {
+			return err
+		}
+
 		fDest, fdErr := os.Create(fPath)
 
 		if fdErr != nil {
@@ -110,7 +235,5 @@ func Unzip(zipFile string, dest string) error {
 		_ = fDest.Close()
 	}
 
-	_ = os.Remove(zipFile)
-
 	return nil
 }
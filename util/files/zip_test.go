@@ -0,0 +1,151 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+)
+
+func testDir(t *testing.T) (string, map[string]string) {
+	dir := filepath.Join(t.TempDir(), "archive")
+
+	contents := map[string]string{
+		"a.txt":                          "secret a",
+		filepath.Join("sub", "b.txt"):    "secret b",
+		filepath.Join("sub", "c", "d.x"): "secret d",
+		// spans several encrypted chunks once compressed
+		"large.txt": "secret " + hex.EncodeToString(random(t, 200*1024)),
+	}
+
+	for name, data := range contents {
+		p := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatalf("error creating test dir: %v", err)
+		}
+
+		if err := os.WriteFile(p, []byte(data), 0640); err != nil {
+			t.Fatalf("error writing test file: %v", err)
+		}
+	}
+
+	return dir, contents
+}
+
+func Test_ZipEncrypted_RoundTrip(t *testing.T) {
+	key := gcm256.NewKey(nil)
+	dir, contents := testDir(t)
+
+	zipFile, zErr := ZipEncrypted(dir, key)
+
+	if zErr != nil {
+		t.Fatalf("ZipEncrypted() returned an error: %v", zErr)
+	}
+
+	enc, _ := os.ReadFile(zipFile)
+
+	if bytes.Contains(enc, []byte("secret")) || bytes.Contains(enc, []byte("a.txt")) {
+		t.Errorf("encrypted archive contains plaintext")
+	}
+
+	dest := t.TempDir()
+
+	if err := UnzipEncrypted(zipFile, dest, key); err != nil {
+		t.Fatalf("UnzipEncrypted() returned an error: %v", err)
+	}
+
+	for name, data := range contents {
+		got, err := os.ReadFile(filepath.Join(dest, name))
+
+		if err != nil || string(got) != data {
+			t.Errorf("extracted %s = %q, %v, expected %q", name, got, err, data)
+		}
+	}
+
+	if _, err := os.Stat(zipFile); !os.IsNotExist(err) {
+		t.Errorf("UnzipEncrypted() did not remove the archive")
+	}
+}
+
+func Test_UnzipEncrypted_Tampered(t *testing.T) {
+	key := gcm256.NewKey(nil)
+	dir, _ := testDir(t)
+
+	zipFile, zErr := ZipEncrypted(dir, key)
+
+	if zErr != nil {
+		t.Fatalf("ZipEncrypted() returned an error: %v", zErr)
+	}
+
+	enc, _ := os.ReadFile(zipFile)
+	enc[len(enc)/2] ^= 0xff
+
+	if err := os.WriteFile(zipFile, enc, 0640); err != nil {
+		t.Fatalf("error writing tampered archive: %v", err)
+	}
+
+	dest := t.TempDir()
+
+	if err := UnzipEncrypted(zipFile, dest, key); err == nil {
+		t.Fatalf("UnzipEncrypted() of a tampered archive should return an error")
+	}
+
+	if extracted, _ := os.ReadDir(dest); len(extracted) != 0 {
+		t.Errorf("UnzipEncrypted() of a tampered archive extracted %d files", len(extracted))
+	}
+
+	// nothing is decrypted to disk
+	if left, _ := os.ReadDir(filepath.Dir(zipFile)); len(left) != 1 {
+		t.Errorf("UnzipEncrypted() left %d files next to the archive, expected only the archive", len(left))
+	}
+}
+
+func random(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("error reading random bytes: %v", err)
+	}
+
+	return b
+}
+
+func Test_Unzip_OutsideDest(t *testing.T) {
+	for _, name := range []string{"../evil.txt", "sub/../../evil.txt", "/evil.txt"} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			zipFile := filepath.Join(root, "evil.zip")
+
+			buf := &bytes.Buffer{}
+			zw := zip.NewWriter(buf)
+
+			if w, err := zw.Create(name); err != nil {
+				t.Fatalf("error creating zip entry: %v", err)
+			} else {
+				_, _ = w.Write([]byte("evil"))
+			}
+
+			_ = zw.Close()
+
+			if err := os.WriteFile(zipFile, buf.Bytes(), 0640); err != nil {
+				t.Fatalf("error writing archive: %v", err)
+			}
+
+			dest := filepath.Join(root, "dest")
+
+			if err := Unzip(zipFile, dest); err == nil {
+				t.Errorf("Unzip() of %s should return an error", name)
+			}
+
+			if _, err := os.Stat(filepath.Join(root, "evil.txt")); !os.IsNotExist(err) {
+				t.Errorf("Unzip() wrote %s outside of the destination", name)
+			}
+		})
+	}
+}
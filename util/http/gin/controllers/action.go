@@ -7,6 +7,17 @@ import (
 	"slices"
 )
 
+const (
+	// ActionSettingRequestType reflect.Type of the action's request. bound from the body for POST, PUT and PATCH
+	// requests and from the query string otherwise
+	ActionSettingRequestType = "requestType"
+	// ActionSettingResponseType reflect.Type of the action's successful response body
+	ActionSettingResponseType = "responseType"
+	// ActionSettingSummary and ActionSettingDescription document the action
+	ActionSettingSummary     = "summary"
+	ActionSettingDescription = "description"
+)
+
 type ActionFunc func(c *gin.Context)
 type ActionAuthFunc func(c *gin.Context) bool
 
@@ -18,14 +29,20 @@ type IAction interface {
 	WithAuthRoles(roles ...auth.Role) IAction
 	WithVanityPath(path string) IAction
 	VanityPath() string
+	WithMfaRequired() IAction
+	MfaRequired() bool
+	WithSetting(key string, value any) IAction
+	Setting(key string) (any, bool)
 }
 
 type Action struct {
-	Verbs      []string
-	Fn         ActionFunc
-	AuthRoles  []auth.Role
-	name       string
-	vanityPath string
+	Verbs       []string
+	Fn          ActionFunc
+	AuthRoles   []auth.Role
+	name        string
+	vanityPath  string
+	mfaRequired bool
+	settings    map[string]any
 }
 
 func (a *Action) HasVerb(verb string) bool {
@@ -56,6 +73,35 @@ func (a *Action) WithVanityPath(path string) IAction {
 	return a
 }
 
+// WithMfaRequired requires the caller's claims to have completed multi-factor authentication
+func (a *Action) WithMfaRequired() IAction {
+	a.mfaRequired = true
+
+	return a
+}
+
+func (a *Action) MfaRequired() bool {
+	return a.mfaRequired
+}
+
+// WithSetting attaches an arbitrary setting to the action. middleware uses settings for per-action configuration
+// (see GetRoute)
+func (a *Action) WithSetting(key string, value any) IAction {
+	if a.settings == nil {
+		a.settings = make(map[string]any)
+	}
+
+	a.settings[key] = value
+
+	return a
+}
+
+func (a *Action) Setting(key string) (any, bool) {
+	v, ok := a.settings[key]
+
+	return v, ok
+}
+
 func (a *Action) Name() string {
 	return a.name
 }
@@ -71,8 +117,10 @@ func NewAction(name string, fn ActionFunc, verbs ...string) *Action {
 		Fn:    fn,
 		// default a new action to allow anyone to access it
 		// requires authorization to be specified explicitly
-		AuthRoles:  []auth.Role{auth.RoleAnonymous},
-		vanityPath: "",
+		AuthRoles:   []auth.Role{auth.RoleAnonymous},
+		vanityPath:  "",
+		mfaRequired: false,
+		settings:    make(map[string]any),
 	}
 
 	return a
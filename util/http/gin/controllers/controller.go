@@ -1,9 +1,18 @@
 package controllers
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/smoxy-io/goSDK/util/auth"
 	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// ContextControllerKey and ContextActionKey hold the names of the controller and action handling the request
+	ContextControllerKey = "controllerName"
+	ContextActionKey     = "actionName"
 )
 
 // HandlerOption if code != 0 or err != nil, the controller will abort the request
@@ -23,12 +32,29 @@ func (c *Controller) AddAction(action IAction) {
 	c.actions[action.Name()] = action
 }
 
+// Actions returns the controller's actions sorted by name
+func (c *Controller) Actions() []IAction {
+	actions := make([]IAction, 0, len(c.actions))
+
+	for _, a := range c.actions {
+		actions = append(actions, a)
+	}
+
+	slices.SortFunc(actions, func(a, b IAction) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return actions
+}
+
 func (c *Controller) RegisterActions(r gin.IRouter, opts ...HandlerOption) {
 	for _, action := range c.actions {
 		// register the handler with the router
 		r.Any(c.ActionPath(action), c.Handler(action, opts...))
 		// register the permissions with the auth middleware
 		auth.SetAllowedRoles(c.ActionPath(action), action.AuthorizedRoles()...)
+		// register the route so middleware can find the action's settings
+		registerRoute(fullPath(r, c.ActionPath(action)), c, action, len(opts) > 0)
 	}
 }
 
@@ -36,7 +62,7 @@ func (c *Controller) ActionPath(action IAction) string {
 	if v := action.VanityPath(); v != "" {
 		return v
 	}
-	
+
 	return "/" + c.Name() + "/" + action.Name()
 }
 
@@ -49,6 +75,9 @@ func (c *Controller) Handler(action IAction, opts ...HandlerOption) gin.HandlerF
 	}
 
 	return func(ctx *gin.Context) {
+		ctx.Set(ContextControllerKey, c.Name())
+		ctx.Set(ContextActionKey, action.Name())
+
 		if !action.HasVerb(ctx.Request.Method) {
 			ctx.AbortWithStatus(http.StatusNotFound)
 			return
@@ -59,6 +88,11 @@ func (c *Controller) Handler(action IAction, opts ...HandlerOption) gin.HandlerF
 			return
 		}
 
+		if action.MfaRequired() && !auth.GetMfaFromCtx(ctx) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "multi-factor authentication required"})
+			return
+		}
+
 		ctx.Set(auth.IsAuthorizedContextKey, true)
 
 		for _, o := range opts {
@@ -77,6 +111,15 @@ func (c *Controller) Handler(action IAction, opts ...HandlerOption) gin.HandlerF
 	}
 }
 
+// GetActionFromCtx returns the names of the controller and action handling the request. both are empty when the
+// request was not routed to an action
+func GetActionFromCtx(ctx context.Context) (controller string, action string) {
+	controller, _ = ctx.Value(ContextControllerKey).(string)
+	action, _ = ctx.Value(ContextActionKey).(string)
+
+	return controller, action
+}
+
 var (
 	controllers map[string][]*Controller = make(map[string][]*Controller)
 )
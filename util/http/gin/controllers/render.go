@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"github.com/smoxy-io/goSDK/util/http/gin/util"
+	"google.golang.org/protobuf/proto"
+)
+
+// response formats
+const (
+	FormatJson     = "json"
+	FormatProtobuf = "protobuf"
+	FormatMsgPack  = "msgpack"
+
+	MIMEPROTOBUF2 = "application/protobuf"
+)
+
+// Render writes obj with status in the format preferred by the request's Accept header: json, protobuf (when obj
+// is a proto.Message) or msgpack. json is used when the request has no Accept header. responds with 406 when
+// none of the acceptable formats can be used
+func Render(c *gin.Context, status int, obj any) {
+	format := NegotiateFormat(c, obj)
+
+	if format == "" {
+		c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"error": "none of the accepted content types are supported"})
+		return
+	}
+
+	renderFormat(c, status, format, obj)
+}
+
+// NegotiateFormat returns the format preferred by the request's Accept header that obj can be written in. returns
+// an empty string when none of the acceptable formats can be used
+func NegotiateFormat(c *gin.Context, obj any) string {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	accept := util.ParseAccept(c.GetHeader("Accept"))
+
+	if len(accept) == 0 {
+		return FormatJson
+	}
+
+	for _, mime := range accept {
+		switch mime {
+		case binding.MIMEJSON, "application/*", "*/*":
+			return FormatJson
+		case binding.MIMEPROTOBUF, MIMEPROTOBUF2:
+			if _, ok := obj.(proto.Message); ok {
+				return FormatProtobuf
+			}
+		case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+			return FormatMsgPack
+		}
+	}
+
+	return ""
+}
+
+// renderError writes an error in the negotiated format. errors are written as json when they cannot be written in
+// the negotiated format
+func renderError(c *gin.Context, status int, obj any) {
+	format := NegotiateFormat(c, obj)
+
+	if format == "" {
+		format = FormatJson
+	}
+
+	c.Abort()
+
+	renderFormat(c, status, format, obj)
+}
+
+func renderFormat(c *gin.Context, status int, format string, obj any) {
+	switch format {
+	case FormatProtobuf:
+		c.Render(status, render.ProtoBuf{Data: obj})
+	case FormatMsgPack:
+		c.Render(status, render.MsgPack{Data: obj})
+	default:
+		c.JSON(status, obj)
+	}
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testGreeting struct {
+	Name string `json:"name" codec:"name" binding:"required"`
+}
+
+func Test_Render(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := NewController("render")
+
+	ctrl.AddAction(NewTypedAction("greet", func(c *gin.Context, req *testGreeting) (testGreeting, error) {
+		return testGreeting{Name: "hello " + req.Name}, nil
+	}, http.MethodPost))
+
+	ctrl.AddAction(NewTypedAction("proto", func(c *gin.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("hello " + req.GetValue()), nil
+	}, http.MethodPost))
+
+	r := gin.New()
+	ctrl.RegisterActions(r)
+
+	msgpack := func(v any) []byte {
+		out := []byte{}
+		_ = codec.NewEncoderBytes(&out, &codec.MsgpackHandle{}).Encode(v)
+
+		return out
+	}
+
+	protobuf := func(v string) []byte {
+		out, _ := proto.Marshal(wrapperspb.String(v))
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		accept      string
+		body        []byte
+		code        int
+		respType    string
+		expect      []byte
+	}{
+		{name: "json", path: "/render/greet", contentType: binding.MIMEJSON, body: []byte(`{"name":"bob"}`), code: http.StatusOK, respType: binding.MIMEJSON, expect: []byte(`{"name":"hello bob"}`)},
+		{name: "msgpack", path: "/render/greet", contentType: binding.MIMEMSGPACK2, accept: "application/msgpack, application/json;q=0.5", body: msgpack(testGreeting{Name: "bob"}), code: http.StatusOK, respType: binding.MIMEMSGPACK2, expect: msgpack(testGreeting{Name: "hello bob"})},
+		{name: "msgpack validation", path: "/render/greet", contentType: binding.MIMEMSGPACK, accept: "application/msgpack", body: msgpack(testGreeting{}), code: http.StatusBadRequest, respType: binding.MIMEMSGPACK2},
+		{name: "not acceptable", path: "/render/greet", contentType: binding.MIMEJSON, accept: binding.MIMEPROTOBUF, body: []byte(`{"name":"bob"}`), code: http.StatusNotAcceptable, respType: binding.MIMEJSON},
+		{name: "protobuf", path: "/render/proto", contentType: binding.MIMEPROTOBUF, accept: MIMEPROTOBUF2, body: protobuf("bob"), code: http.StatusOK, respType: binding.MIMEPROTOBUF, expect: protobuf("hello bob")},
+		{name: "protobuf not supported", path: "/render/greet", contentType: binding.MIMEPROTOBUF, body: protobuf("bob"), code: http.StatusUnsupportedMediaType, respType: binding.MIMEJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, expected %d: %s", w.Code, tt.code, w.Body.String())
+			}
+
+			if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.respType) {
+				t.Errorf("Content-Type = %s, expected %s", w.Header().Get("Content-Type"), tt.respType)
+			}
+
+			if tt.expect != nil && !bytes.Equal(w.Body.Bytes(), tt.expect) {
+				t.Errorf("body = %q, expected %q", w.Body.Bytes(), tt.expect)
+			}
+		})
+	}
+}
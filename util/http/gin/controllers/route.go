@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"path"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route the controller and action registered for a route
+type Route struct {
+	Controller *Controller
+	Action     IAction
+	// options the handler runs HandlerOptions before the action
+	options bool
+}
+
+// PerRequestChecks returns true when the handler checks more than the client's roles before the action runs:
+// multi-factor authentication, the controller's authorization function or HandlerOptions. middleware that answers
+// a request before the handler runs, like a response cache, would skip these checks
+func (r *Route) PerRequestChecks() bool {
+	return r.Action.MfaRequired() || r.Controller.actionAuthFn != nil || r.options
+}
+
+// Setting returns the action's setting for key
+func (r *Route) Setting(key string) (any, bool) {
+	return r.Action.Setting(key)
+}
+
+var (
+	routes    = make(map[string]*Route)
+	routeLock = &sync.RWMutex{}
+)
+
+func registerRoute(fullPath string, c *Controller, action IAction, options bool) {
+	routeLock.Lock()
+	defer routeLock.Unlock()
+
+	routes[fullPath] = &Route{Controller: c, Action: action, options: options}
+}
+
+// GetRoute returns the controller and action registered for fullPath (gin's Context.FullPath()). middleware that
+// runs before the action handler uses it to read per-action settings
+func GetRoute(fullPath string) (*Route, bool) {
+	routeLock.RLock()
+	defer routeLock.RUnlock()
+
+	r, ok := routes[fullPath]
+
+	return r, ok
+}
+
+// GetRouteFromCtx returns the controller and action that will handle the request
+func GetRouteFromCtx(c *gin.Context) (*Route, bool) {
+	return GetRoute(c.FullPath())
+}
+
+// GetAllRoutes returns all registered routes keyed by their full path
+func GetAllRoutes() map[string]*Route {
+	routeLock.RLock()
+	defer routeLock.RUnlock()
+
+	all := make(map[string]*Route, len(routes))
+
+	for p, r := range routes {
+		all[p] = r
+	}
+
+	return all
+}
+
+// GetActionSetting returns the setting for key of the action that will handle the request
+func GetActionSetting(c *gin.Context, key string) (any, bool) {
+	r, ok := GetRouteFromCtx(c)
+
+	if !ok {
+		return nil, false
+	}
+
+	return r.Setting(key)
+}
+
+// fullPath mirrors how gin joins a group's base path with a relative path
+func fullPath(r gin.IRouter, relativePath string) string {
+	base := "/"
+
+	if g, ok := r.(interface{ BasePath() string }); ok {
+		base = g.BasePath()
+	}
+
+	if relativePath == "" {
+		return base
+	}
+
+	p := path.Join(base, relativePath)
+
+	if relativePath[len(relativePath)-1] == '/' && p[len(p)-1] != '/' {
+		return p + "/"
+	}
+
+	return p
+}
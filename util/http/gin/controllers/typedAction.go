@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	utilErrors "github.com/smoxy-io/goSDK/util/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// TypedActionFunc handles a request that has been bound into req and validated. the returned response is written
+// in the format negotiated by Render with the status set with c.Status (default 200). return an *HttpError to control the error response
+type TypedActionFunc[Req any, Resp any] func(c *gin.Context, req *Req) (Resp, error)
+
+// FieldError a validation error for a single request field. Field is the field's path using json names
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// HttpError an error with the http status and body to respond with
+type HttpError struct {
+	Status  int          `json:"-"`
+	Message string       `json:"error"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Err     error        `json:"-"`
+}
+
+func (e *HttpError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *HttpError) Unwrap() error {
+	return e.Err
+}
+
+func NewHttpError(status int, message string, fields ...FieldError) *HttpError {
+	return &HttpError{Status: status, Message: message, Fields: fields}
+}
+
+// sanitizer and validator are the methods of util.Post. request types can implement either or both
+type sanitizer interface {
+	Sanitize()
+}
+
+type isValider interface {
+	IsValid() bool
+}
+
+// NewTypedAction creates an action that binds the request into a Req, validates it and writes the Resp returned
+// by fn
+//
+// path parameters are bound from `uri` tags, the query string from `form` tags and POST, PUT and PATCH bodies
+// from `json` tags (or `form` tags for form posts). msgpack bodies are supported and protobuf bodies are supported
+// when *Req is a proto.Message. after binding, Req's Sanitize and IsValid methods are called when implemented and
+// the `binding` struct tags are validated. invalid requests get a 400 response that lists the invalid fields. use
+// struct{} for Req or Resp when the action has no request or response body
+//
+// the request and response types are recorded as action settings (see ActionSettingRequestType)
+func NewTypedAction[Req any, Resp any](name string, fn TypedActionFunc[Req, Resp], verbs ...string) *Action {
+	reqType := reflect.TypeFor[Req]()
+	respType := reflect.TypeFor[Resp]()
+
+	a := NewAction(name, func(c *gin.Context) {
+		req := new(Req)
+
+		if err := bindRequest(c, req); err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp, err := fn(c, req)
+
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		if c.Writer.Written() || c.IsAborted() {
+			// the handler wrote its own response
+			return
+		}
+
+		status := c.Writer.Status()
+
+		if isEmpty(respType) {
+			if status == http.StatusOK {
+				status = http.StatusNoContent
+			}
+
+			c.Status(status)
+			return
+		}
+
+		Render(c, status, resp)
+	}, verbs...)
+
+	if !isEmpty(reqType) {
+		a.WithSetting(ActionSettingRequestType, reqType)
+	}
+
+	if !isEmpty(respType) {
+		a.WithSetting(ActionSettingResponseType, respType)
+	}
+
+	return a
+}
+
+func bindRequest(c *gin.Context, req any) error {
+	if reflect.TypeOf(req).Elem().Kind() == reflect.Struct {
+		if err := binding.MapFormWithTag(req, c.Request.URL.Query(), "form"); err != nil {
+			return &HttpError{Status: http.StatusBadRequest, Message: "invalid query parameters", Err: err}
+		}
+
+		params := make(map[string][]string, len(c.Params))
+
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+
+		if err := binding.MapFormWithTag(req, params, "uri"); err != nil {
+			return &HttpError{Status: http.StatusBadRequest, Message: "invalid path parameters", Err: err}
+		}
+	}
+
+	if slices.Contains([]string{http.MethodPost, http.MethodPut, http.MethodPatch}, c.Request.Method) {
+		if err := bindBody(c, req); err != nil {
+			return err
+		}
+	}
+
+	if s, ok := req.(sanitizer); ok {
+		s.Sanitize()
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		var vErrs validator.ValidationErrors
+
+		if errors.As(err, &vErrs) {
+			return NewHttpError(http.StatusBadRequest, "invalid request", fieldErrors(reflect.TypeOf(req), vErrs)...)
+		}
+
+		return &HttpError{Status: http.StatusBadRequest, Message: "invalid request", Err: err}
+	}
+
+	if v, ok := req.(isValider); ok && !v.IsValid() {
+		return NewHttpError(http.StatusBadRequest, "invalid request")
+	}
+
+	return nil
+}
+
+func bindBody(c *gin.Context, req any) error {
+	switch c.ContentType() {
+	case binding.MIMEPOSTForm, binding.MIMEMultipartPOSTForm:
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return &HttpError{Status: http.StatusBadRequest, Message: "invalid form body", Err: err}
+		}
+
+		if err := binding.MapFormWithTag(req, c.Request.PostForm, "form"); err != nil {
+			return &HttpError{Status: http.StatusBadRequest, Message: "invalid form body", Err: err}
+		}
+	case binding.MIMEPROTOBUF, MIMEPROTOBUF2:
+		if _, ok := req.(proto.Message); !ok {
+			return NewHttpError(http.StatusUnsupportedMediaType, "protobuf request bodies are not supported")
+		}
+
+		if err := binding.ProtoBuf.Bind(c.Request, req); err != nil && !isValidationError(err) {
+			return bodyError("invalid protobuf body", err)
+		}
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		if err := binding.MsgPack.Bind(c.Request, req); err != nil && !isValidationError(err) {
+			return bodyError("invalid msgpack body", err)
+		}
+	default:
+		if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil && err != io.EOF {
+			return bodyError("invalid json body", err)
+		}
+	}
+
+	return nil
+}
+
+// bodyError a 400 error, or a 413 error when the body is larger than the limit set by middleware.BodyLimit
+func bodyError(message string, err error) *HttpError {
+	var maxErr *http.MaxBytesError
+
+	if errors.As(err, &maxErr) {
+		return &HttpError{Status: http.StatusRequestEntityTooLarge, Message: "request body too large", Err: err}
+	}
+
+	return &HttpError{Status: http.StatusBadRequest, Message: message, Err: err}
+}
+
+// isValidationError gin's body bindings validate the request. validation runs after the request is sanitized
+// instead
+func isValidationError(err error) bool {
+	var vErrs validator.ValidationErrors
+
+	return errors.As(err, &vErrs)
+}
+
+func writeError(c *gin.Context, err error) {
+	var httpErr *HttpError
+
+	if !errors.As(err, &httpErr) {
+		httpErr = &HttpError{Status: http.StatusInternalServerError, Message: "internal server error", Err: err}
+
+		switch {
+		case utilErrors.ErrNotFound.SameAs(err):
+			httpErr.Status = http.StatusNotFound
+			httpErr.Message = err.Error()
+		case utilErrors.ErrExists.SameAs(err):
+			httpErr.Status = http.StatusConflict
+			httpErr.Message = err.Error()
+		case utilErrors.ErrInvalid.SameAs(err):
+			httpErr.Status = http.StatusBadRequest
+			httpErr.Message = err.Error()
+		}
+	}
+
+	if httpErr.Status < 400 {
+		httpErr.Status = http.StatusInternalServerError
+	}
+
+	// recorded for logging middleware. the response only contains the message
+	_ = c.Error(err)
+
+	renderError(c, httpErr.Status, httpErr)
+}
+
+func fieldErrors(t reflect.Type, vErrs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(vErrs))
+
+	for _, fe := range vErrs {
+		field := jsonPath(t, fe.StructNamespace())
+
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: fieldMessage(field, fe),
+		})
+	}
+
+	return fields
+}
+
+func fieldMessage(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return field + " must be a valid email address"
+	}
+
+	if fe.Param() != "" {
+		return fmt.Sprintf("%s failed the %s=%s rule", field, fe.Tag(), fe.Param())
+	}
+
+	return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+}
+
+// jsonPath converts a validator struct namespace (Req.Address.City or Req.Items[0].Name) into the json names of
+// the fields (address.city or items[0].name)
+func jsonPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+
+	if len(parts) > 1 {
+		// the first part is the name of the top level type
+		parts = parts[1:]
+	}
+
+	out := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		name, index, _ := strings.Cut(part, "[")
+
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+
+		jsonName := name
+
+		if t.Kind() == reflect.Struct {
+			if f, ok := t.FieldByName(name); ok {
+				if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+					jsonName = tag
+				}
+
+				t = f.Type
+			}
+		}
+
+		if index != "" {
+			jsonName += "[" + index
+		}
+
+		out = append(out, jsonName)
+	}
+
+	return strings.Join(out, ".")
+}
+
+func isEmpty(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+type testAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type testUpdateUser struct {
+	Id      string       `uri:"id" json:"-"`
+	Notify  bool         `form:"notify" json:"-"`
+	Name    string       `json:"name" binding:"required,max=10"`
+	Role    string       `json:"role" binding:"omitempty,oneof=user admin"`
+	Address *testAddress `json:"address" binding:"omitempty"`
+}
+
+func (u *testUpdateUser) Sanitize() {
+	u.Name = strings.TrimSpace(u.Name)
+}
+
+func (u *testUpdateUser) IsValid() bool {
+	return u.Name != "invalid"
+}
+
+type testUserResponse struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Notify bool   `json:"notify"`
+}
+
+func newTypedTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := NewController("typed")
+
+	ctrl.AddAction(NewTypedAction("update", func(c *gin.Context, req *testUpdateUser) (testUserResponse, error) {
+		switch req.Id {
+		case "missing":
+			return testUserResponse{}, errors.ErrNotFound
+		case "teapot":
+			return testUserResponse{}, NewHttpError(http.StatusTeapot, "i'm a teapot")
+		}
+
+		return testUserResponse{Id: req.Id, Name: req.Name, Notify: req.Notify}, nil
+	}, http.MethodPost).WithVanityPath("/typed/:id"))
+
+	ctrl.AddAction(NewTypedAction("delete", func(c *gin.Context, req *struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, http.MethodDelete).WithAuthRoles(auth.RoleAdmin))
+
+	r := gin.New()
+	ctrl.RegisterActions(r)
+
+	return r
+}
+
+func Test_NewTypedAction(t *testing.T) {
+	r := newTypedTestRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		expect string
+	}{
+		{name: "valid", method: http.MethodPost, path: "/typed/u1?notify=true", body: `{"name":"  bob "}`, code: http.StatusOK, expect: `{"id":"u1","name":"bob","notify":true}`},
+		{name: "missing field", method: http.MethodPost, path: "/typed/u1", body: `{}`, code: http.StatusBadRequest, expect: `{"error":"invalid request","fields":[{"field":"name","rule":"required","message":"name is required"}]}`},
+		{name: "nested field", method: http.MethodPost, path: "/typed/u1", body: `{"name":"bob","role":"root","address":{}}`, code: http.StatusBadRequest, expect: `{"error":"invalid request","fields":[{"field":"role","rule":"oneof","message":"role must be one of: user, admin"},{"field":"address.city","rule":"required","message":"address.city is required"}]}`},
+		{name: "IsValid", method: http.MethodPost, path: "/typed/u1", body: `{"name":"invalid"}`, code: http.StatusBadRequest, expect: `{"error":"invalid request"}`},
+		{name: "bad json", method: http.MethodPost, path: "/typed/u1", body: `{"name":`, code: http.StatusBadRequest, expect: `{"error":"invalid json body"}`},
+		{name: "not found", method: http.MethodPost, path: "/typed/missing", body: `{"name":"bob"}`, code: http.StatusNotFound, expect: `{"error":"not found"}`},
+		{name: "http error", method: http.MethodPost, path: "/typed/teapot", body: `{"name":"bob"}`, code: http.StatusTeapot, expect: `{"error":"i'm a teapot"}`},
+		{name: "roles", method: http.MethodDelete, path: "/typed/delete", code: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, expected %d: %s", w.Code, tt.code, w.Body.String())
+			}
+
+			if tt.expect != "" && strings.TrimSpace(w.Body.String()) != tt.expect {
+				t.Errorf("body = %s, expected %s", w.Body.String(), tt.expect)
+			}
+		})
+	}
+}
+
+func Test_NewTypedAction_NoContent(t *testing.T) {
+	newTypedTestRouter()
+
+	route, ok := GetRoute("/typed/delete")
+
+	if !ok {
+		t.Fatalf("route not registered")
+	}
+
+	if _, ok := route.Setting(ActionSettingRequestType); ok {
+		t.Errorf("empty request type should not be recorded")
+	}
+
+	if rt, ok := GetRoute("/typed/:id"); !ok {
+		t.Errorf("typed route not registered")
+	} else if v, _ := rt.Setting(ActionSettingResponseType); v == nil {
+		t.Errorf("response type not recorded")
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.RoleContextKey, auth.RoleAdmin) })
+	r.DELETE("/typed/delete", route.Controller.Handler(route.Action))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/typed/delete", nil))
+
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("empty response returned %d: %s", w.Code, w.Body.String())
+	}
+}
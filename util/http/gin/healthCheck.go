@@ -1,10 +1,47 @@
 package gin
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sync"
+	"time"
 )
 
+const (
+	HealthStatusOk          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusDraining    = "draining"
+
+	DefaultHealthCheckTimeout = 5 * time.Second
+)
+
+// HealthChecker checks a dependency of the server. a non-nil error marks the dependency as unhealthy
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc adapts a function to a HealthChecker, e.g. HealthCheckFunc(dgraph.Ping)
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+type HealthStatus struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status string `json:"status"`
+	// Error the error of a failed check. it is logged instead of being sent in probe responses, which are usually
+	// public and must not expose internals (hosts, credentials in connection strings...)
+	Error    string `json:"-"`
+	Duration string `json:"duration"`
+}
+
 func HealthCheck() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.JSON(http.StatusOK, struct {
@@ -12,3 +49,100 @@ func HealthCheck() gin.HandlerFunc {
 		}{Status: "ok"})
 	}
 }
+
+// LivenessCheck reports whether the process is healthy. responds with 503 when any of the checks fail
+func LivenessCheck(checks map[string]HealthChecker, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := RunHealthChecks(c.Request.Context(), checks, timeout)
+
+		logHealthErrors(c, status)
+
+		c.JSON(healthStatusCode(status), status)
+	}
+}
+
+// ReadinessCheck reports whether the server can accept traffic. responds with 503 while the server is draining
+// or when any of the checks fail
+func ReadinessCheck(isDraining func() bool, checks map[string]HealthChecker, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isDraining != nil && isDraining() {
+			c.JSON(http.StatusServiceUnavailable, HealthStatus{Status: HealthStatusDraining})
+			return
+		}
+
+		status := RunHealthChecks(c.Request.Context(), checks, timeout)
+
+		logHealthErrors(c, status)
+
+		c.JSON(healthStatusCode(status), status)
+	}
+}
+
+// logHealthErrors adds the errors of the failed checks to the context's errors, which are logged with the request
+func logHealthErrors(c *gin.Context, status HealthStatus) {
+	for name, res := range status.Checks {
+		if res.Error != "" {
+			_ = c.Error(fmt.Errorf("%s health check failed: %s", name, res.Error))
+		}
+	}
+}
+
+// RunHealthChecks runs checks concurrently. each check is cancelled after timeout
+func RunHealthChecks(ctx context.Context, checks map[string]HealthChecker, timeout time.Duration) HealthStatus {
+	status := HealthStatus{Status: HealthStatusOk}
+
+	if len(checks) == 0 {
+		return status
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+
+	status.Checks = make(map[string]CheckResult, len(checks))
+
+	wg := &sync.WaitGroup{}
+	lock := &sync.Mutex{}
+
+	for name, checker := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.CheckHealth(cCtx)
+
+			res := CheckResult{Status: HealthStatusOk, Duration: time.Since(start).String()}
+
+			if err != nil {
+				res.Status = HealthStatusUnavailable
+				res.Error = err.Error()
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			status.Checks[name] = res
+
+			if err != nil {
+				status.Status = HealthStatusUnavailable
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return status
+}
+
+func healthStatusCode(status HealthStatus) int {
+	if status.Status != HealthStatusOk {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,128 @@
+package gin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Test_RunHealthChecks(t *testing.T) {
+	checks := map[string]HealthChecker{
+		"ok": HealthCheckFunc(func(ctx context.Context) error {
+			return nil
+		}),
+		"failing": HealthCheckFunc(func(ctx context.Context) error {
+			return errors.New("down")
+		}),
+		"slow": HealthCheckFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	}
+
+	status := RunHealthChecks(context.Background(), checks, 10*time.Millisecond)
+
+	if status.Status != HealthStatusUnavailable {
+		t.Errorf("status = %s, expected %s", status.Status, HealthStatusUnavailable)
+	}
+
+	if status.Checks["ok"].Status != HealthStatusOk {
+		t.Errorf("ok check = %+v", status.Checks["ok"])
+	}
+
+	if status.Checks["failing"].Error != "down" {
+		t.Errorf("failing check = %+v", status.Checks["failing"])
+	}
+
+	if status.Checks["slow"].Status != HealthStatusUnavailable {
+		t.Errorf("slow check should time out: %+v", status.Checks["slow"])
+	}
+
+	if s := RunHealthChecks(context.Background(), nil, 0); s.Status != HealthStatusOk || s.Checks != nil {
+		t.Errorf("no checks = %+v", s)
+	}
+}
+
+func Test_Server_Probes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ready := true
+
+	s := NewServer("").WithReadinessCheck("db", HealthCheckFunc(func(ctx context.Context) error {
+		if !ready {
+			return errors.New("not connected")
+		}
+
+		return nil
+	}))
+
+	if other := NewServer(""); other == s {
+		t.Fatalf("NewServer should return independent servers")
+	}
+
+	probe := func(route string) (int, HealthStatus) {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, route, nil))
+
+		var status HealthStatus
+
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatalf("invalid probe response: %s", w.Body.String())
+		}
+
+		return w.Code, status
+	}
+
+	if code, status := probe("/health/ready"); code != http.StatusOK || status.Checks["db"].Status != HealthStatusOk {
+		t.Errorf("ready probe = %d %+v", code, status)
+	}
+
+	ready = false
+
+	if code, status := probe("/health/ready"); code != http.StatusServiceUnavailable || status.Checks["db"].Status != HealthStatusUnavailable {
+		t.Errorf("failing ready probe = %d %+v", code, status)
+	}
+
+	// the error of the check is logged, not sent
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	if strings.Contains(w.Body.String(), "not connected") {
+		t.Errorf("ready probe exposes the error of the check: %s", w.Body.String())
+	}
+
+	ready = true
+	s.Drain()
+
+	if code, status := probe("/health/ready"); code != http.StatusServiceUnavailable || status.Status != HealthStatusDraining {
+		t.Errorf("draining ready probe = %d %+v", code, status)
+	}
+
+	// liveness is not affected by draining or readiness checks
+	if code, _ := probe("/health/live"); code != http.StatusOK {
+		t.Errorf("live probe = %d", code)
+	}
+
+	if code, _ := probe("/health"); code != http.StatusOK {
+		t.Errorf("health probe = %d", code)
+	}
+
+	// probes are not traced, whatever their routes
+	for _, route := range []string{"/health", "/health/live", "/health/ready"} {
+		if !slices.Contains(s.noTrace(), route) {
+			t.Errorf("%s is traced", route)
+		}
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Errorf("error stopping server: %v", err)
+	}
+}
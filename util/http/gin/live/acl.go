@@ -0,0 +1,90 @@
+package live
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/events"
+)
+
+type topicRule struct {
+	topic events.Topic
+	roles auth.Role
+	// public set when auth.RoleAnonymous is one of the roles
+	public bool
+}
+
+// TopicACL the topics clients may subscribe to by role. like action auth roles, auth.RoleAnonymous allows everyone
+// and auth.RoleSuperAdmin may subscribe to every topic in the ACL
+type TopicACL struct {
+	rules []topicRule
+	lock  *sync.RWMutex
+}
+
+// Allow allows clients with any of roles to subscribe to topic and the topics it matches. pass auth.RoleAnonymous
+// to make topic public. without roles nobody may subscribe to topic
+func (a *TopicACL) Allow(topic events.Topic, roles ...auth.Role) *TopicACL {
+	if len(roles) == 0 {
+		return a
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.rules = append(a.rules, topicRule{
+		topic:  topic,
+		roles:  auth.NewRole(roles...),
+		public: slices.Contains(roles, auth.RoleAnonymous),
+	})
+
+	return a
+}
+
+// CanSubscribe returns true if a client with role may subscribe to topic. topic must be a topic of the ACL or
+// matched by one
+func (a *TopicACL) CanSubscribe(role auth.Role, topic events.Topic) bool {
+	if !topic.IsValid() {
+		return false
+	}
+
+	return a.allowed(role, func(rule events.Topic) bool {
+		return rule == topic || rule.Matches(events.RoutingKey(topic))
+	})
+}
+
+// CanReceive returns true if a client with role may receive events with routingKey
+func (a *TopicACL) CanReceive(role auth.Role, routingKey events.RoutingKey) bool {
+	return a.allowed(role, func(rule events.Topic) bool {
+		return rule.Matches(routingKey)
+	})
+}
+
+// Covers returns true if any client may receive events with routingKey
+func (a *TopicACL) Covers(routingKey events.RoutingKey) bool {
+	return a.CanReceive(auth.RoleSuperAdmin, routingKey)
+}
+
+func (a *TopicACL) allowed(role auth.Role, matches func(rule events.Topic) bool) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	for _, rule := range a.rules {
+		if !matches(rule.topic) {
+			continue
+		}
+
+		if rule.public || role.Has(auth.RoleSuperAdmin) || role.Has(rule.roles) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewTopicACL() *TopicACL {
+	return &TopicACL{
+		rules: make([]topicRule, 0),
+		lock:  &sync.RWMutex{},
+	}
+}
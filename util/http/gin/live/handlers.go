@@ -0,0 +1,219 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// WriteTimeout the time a client has to accept a message before it is disconnected
+	WriteTimeout = 10 * time.Second
+
+	// AllowAllOrigins passed to WebSocketHandler accepts websocket connections from every origin
+	AllowAllOrigins = "*"
+)
+
+// SSEHandler streams the events of the topics in the request's query to the client as server-sent events.
+// MUST be registered after the middleware that authenticates the client
+//
+// browsers' EventSource reconnects automatically and resumes with the Last-Event-ID header. heartbeats are sent
+// as comments
+func (h *Hub) SSEHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cl, missed, err := h.connect(c)
+
+		if err != nil {
+			abortWithConnectError(c, err)
+			return
+		}
+
+		defer h.unsubscribe(cl)
+
+		header := c.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		// disable proxy buffering (nginx)
+		header.Set("X-Accel-Buffering", "no")
+
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		rc := http.NewResponseController(c.Writer)
+
+		h.serve(c.Request.Context(), cl, missed, func(msg Message) error {
+			_ = rc.SetWriteDeadline(time.Now().Add(WriteTimeout))
+
+			if err := writeSSE(c.Writer, msg); err != nil {
+				return err
+			}
+
+			c.Writer.Flush()
+
+			return nil
+		})
+	}
+}
+
+// WebSocketHandler streams the events of the topics in the request's query to the client as json messages over a
+// websocket. MUST be registered after the middleware that authenticates the client
+//
+// clients resume with the lastEventId query parameter. browsers send cookies with cross-site websocket requests, so
+// by default only connections from the same origin (the Origin's host is the request's Host) are accepted. when
+// origins are set, only connections from those origins (e.g. https://app.example.com) are accepted. AllowAllOrigins
+// accepts every origin and MUST NOT be used when clients are authenticated with cookies. clients that do not send an
+// Origin header (browsers always do) are accepted
+func (h *Hub) WebSocketHandler(origins ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cl, missed, err := h.connect(c)
+
+		if err != nil {
+			abortWithConnectError(c, err)
+			return
+		}
+
+		defer h.unsubscribe(cl)
+
+		srv := websocket.Server{
+			Handshake: func(cfg *websocket.Config, req *http.Request) error {
+				origin, oErr := websocket.Origin(cfg, req)
+
+				if oErr != nil {
+					return oErr
+				}
+
+				cfg.Origin = origin
+
+				if !originAllowed(origin, req.Host, origins) {
+					return fmt.Errorf("origin not allowed")
+				}
+
+				return nil
+			},
+			Handler: func(ws *websocket.Conn) {
+				ctx, cancel := context.WithCancel(c.Request.Context())
+				defer cancel()
+
+				go func() {
+					// messages from clients are ignored. reading detects closed connections
+					defer cancel()
+
+					var discard []byte
+
+					for {
+						if err := websocket.Message.Receive(ws, &discard); err != nil {
+							return
+						}
+					}
+				}()
+
+				h.serve(ctx, cl, missed, func(msg Message) error {
+					_ = ws.SetWriteDeadline(time.Now().Add(WriteTimeout))
+
+					return websocket.JSON.Send(ws, msg)
+				})
+			},
+		}
+
+		srv.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// originAllowed returns true when a websocket connection from origin is accepted (see WebSocketHandler)
+func originAllowed(origin *url.URL, host string, origins []string) bool {
+	if origin == nil || slices.Contains(origins, AllowAllOrigins) {
+		return true
+	}
+
+	if len(origins) == 0 {
+		return strings.EqualFold(origin.Host, host)
+	}
+
+	return slices.Contains(origins, origin.Scheme+"://"+origin.Host)
+}
+
+// serve sends the missed messages, then new messages and heartbeats until ctx is done, sending fails or the client
+// is dropped
+func (h *Hub) serve(ctx context.Context, cl *client, missed []Message, send func(msg Message) error) {
+	for _, msg := range missed {
+		if err := send(msg); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-cl.messages:
+			if !ok {
+				_ = send(Message{Type: cl.reason})
+				return
+			}
+
+			if err := send(msg); err != nil {
+				return
+			}
+
+			ticker.Reset(h.cfg.HeartbeatInterval)
+		case <-ticker.C:
+			if err := send(Message{Type: MessageHeartbeat}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w gin.ResponseWriter, msg Message) error {
+	if msg.Type == MessageHeartbeat {
+		_, err := w.WriteString(": heartbeat\n\n")
+		return err
+	}
+
+	data, err := json.Marshal(msg)
+
+	if err != nil {
+		return err
+	}
+
+	frame := ""
+
+	if msg.Id > 0 {
+		frame += fmt.Sprintf("id: %d\n", msg.Id)
+	}
+
+	if msg.Type != MessageEvent {
+		frame += "event: " + msg.Type + "\n"
+	}
+
+	_, err = w.WriteString(frame + "data: " + string(data) + "\n\n")
+
+	return err
+}
+
+func abortWithConnectError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case ErrForbiddenTopic.SameAs(err):
+		status = http.StatusForbidden
+	case ErrNoTopics.SameAs(err):
+		status = http.StatusBadRequest
+	case ErrHubStopped.SameAs(err):
+		status = http.StatusServiceUnavailable
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,299 @@
+package live
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/modules/EventBus"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/errors"
+	"github.com/smoxy-io/goSDK/util/events"
+)
+
+const (
+	DefaultBufferSize        = 64
+	DefaultHistorySize       = 1024
+	DefaultHeartbeatInterval = 30 * time.Second
+
+	// TopicQueryParam the query parameter with the topics to subscribe to. may be repeated or comma separated
+	TopicQueryParam = "topic"
+	// LastEventIdQueryParam resumes after an event when the Last-Event-ID header cannot be set (e.g. websockets)
+	LastEventIdQueryParam = "lastEventId"
+	LastEventIdHeader     = "Last-Event-ID"
+)
+
+// message types
+const (
+	MessageEvent     = "event"
+	MessageHeartbeat = "heartbeat"
+	// MessageGap events after the client's last event id are no longer available and were skipped
+	MessageGap = "gap"
+	// MessageOverflow the client did not keep up and is disconnected. reconnect with the last event id to resume
+	MessageOverflow = "overflow"
+	// MessageClosed the hub is stopped and the client is disconnected
+	MessageClosed = "closed"
+)
+
+var (
+	ErrForbiddenTopic = errors.New("subscribing to topic %s is not allowed")
+	ErrNoTopics       = errors.New("at least one topic is required")
+	ErrHubStopped     = errors.New("hub is stopped")
+)
+
+// Message a message sent to clients
+type Message struct {
+	Id        uint64    `json:"id,omitempty"`
+	Type      string    `json:"type"`
+	Topic     string    `json:"topic,omitempty"`
+	Data      any       `json:"data,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+}
+
+type HubConfig struct {
+	// ACL the topics clients may subscribe to. no topics are allowed when nil
+	ACL *TopicACL
+	// BufferSize the number of messages buffered per client before the client is disconnected
+	BufferSize int
+	// HistorySize the number of recent events kept for clients that resume with a last event id
+	HistorySize int
+	// HeartbeatInterval how often a heartbeat is sent to idle clients
+	HeartbeatInterval time.Duration
+}
+
+// Hub delivers EventBus events to websocket and server-sent event clients
+//
+// the hub subscribes to all events on the EventBus and keeps the ones covered by the ACL. a client that does not
+// keep up with its events is disconnected instead of slowing down the EventBus or other clients
+type Hub struct {
+	cfg          HubConfig
+	clients      map[*client]struct{}
+	history      []Message
+	nextId       uint64
+	subscription events.Subscriber
+	lock         *sync.Mutex
+}
+
+type client struct {
+	role     auth.Role
+	topics   []events.Topic
+	messages chan Message
+	// reason the type of the message sent when the client is dropped
+	reason string
+}
+
+// NewHub creates a hub. the hub must be started before clients can connect
+func NewHub(cfg HubConfig) *Hub {
+	if cfg.ACL == nil {
+		cfg.ACL = NewTopicACL()
+	}
+
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = DefaultHistorySize
+	}
+
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	return &Hub{
+		cfg:     cfg,
+		clients: make(map[*client]struct{}),
+		history: make([]Message, 0, cfg.HistorySize),
+		lock:    &sync.Mutex{},
+	}
+}
+
+// Start subscribes the hub to the EventBus. the EventBus MUST be started
+func (h *Hub) Start() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.subscription != nil {
+		return nil
+	}
+
+	sub, err := EventBus.Subscribe(events.TopicMatchAll)
+
+	if err != nil {
+		return err
+	}
+
+	h.subscription = sub
+
+	go func() {
+		for event := range sub {
+			h.dispatch(event)
+		}
+	}()
+
+	return nil
+}
+
+// Stop unsubscribes the hub from the EventBus and disconnects all clients
+func (h *Hub) Stop() error {
+	h.lock.Lock()
+
+	if h.subscription == nil {
+		h.lock.Unlock()
+		return nil
+	}
+
+	sub := h.subscription
+	h.subscription = nil
+
+	for c := range h.clients {
+		h.drop(c, MessageClosed)
+	}
+
+	// unsubscribe without holding the lock. the EventBus waits for dispatch to receive pending events
+	h.lock.Unlock()
+
+	return EventBus.Unsubscribe(events.TopicMatchAll, sub)
+}
+
+func (h *Hub) dispatch(event events.Event) {
+	if !h.cfg.ACL.Covers(event.RoutingKey) {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.nextId++
+
+	msg := Message{
+		Id:        h.nextId,
+		Type:      MessageEvent,
+		Topic:     event.RoutingKey.String(),
+		Data:      event.Msg,
+		Timestamp: event.Timestamp,
+	}
+
+	if len(h.history) == h.cfg.HistorySize {
+		h.history = append(h.history[:0], h.history[1:]...)
+	}
+
+	h.history = append(h.history, msg)
+
+	for c := range h.clients {
+		if !c.receives(h.cfg.ACL, event.RoutingKey) {
+			continue
+		}
+
+		select {
+		case c.messages <- msg:
+			// intentionally blank
+		default:
+			// the client is not keeping up
+			h.drop(c, MessageOverflow)
+		}
+	}
+}
+
+// subscribe registers a client for topics. returns the client and the events after lastId that it missed
+func (h *Hub) subscribe(role auth.Role, topics []events.Topic, lastId uint64) (*client, []Message, error) {
+	if len(topics) == 0 {
+		return nil, nil, ErrNoTopics
+	}
+
+	for _, t := range topics {
+		if !h.cfg.ACL.CanSubscribe(role, t) {
+			return nil, nil, ErrForbiddenTopic.WithVars(t)
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.subscription == nil {
+		return nil, nil, ErrHubStopped
+	}
+
+	c := &client{
+		role:     role,
+		topics:   topics,
+		messages: make(chan Message, h.cfg.BufferSize),
+	}
+
+	h.clients[c] = struct{}{}
+
+	if lastId == 0 {
+		return c, nil, nil
+	}
+
+	missed := make([]Message, 0)
+
+	if lastId > h.nextId || (len(h.history) > 0 && h.history[0].Id > lastId+1) {
+		missed = append(missed, Message{Type: MessageGap})
+	}
+
+	for _, msg := range h.history {
+		if msg.Id > lastId && c.receives(h.cfg.ACL, events.RoutingKey(msg.Topic)) {
+			missed = append(missed, msg)
+		}
+	}
+
+	return c, missed, nil
+}
+
+func (h *Hub) unsubscribe(c *client) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+	}
+}
+
+// MUST be called while holding the lock
+func (h *Hub) drop(c *client, reason string) {
+	c.reason = reason
+
+	delete(h.clients, c)
+	close(c.messages)
+}
+
+// connect subscribes the client of a request using its roles, topics and last event id
+func (h *Hub) connect(c *gin.Context) (*client, []Message, error) {
+	topics := make([]events.Topic, 0)
+
+	for _, v := range c.QueryArray(TopicQueryParam) {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				topics = append(topics, events.Topic(t))
+			}
+		}
+	}
+
+	lastEventId := c.GetHeader(LastEventIdHeader)
+
+	if lastEventId == "" {
+		lastEventId = c.Query(LastEventIdQueryParam)
+	}
+
+	// an invalid last event id resumes from now
+	lastId, _ := strconv.ParseUint(lastEventId, 10, 64)
+
+	return h.subscribe(auth.GetRolesFromCtx(c), topics, lastId)
+}
+
+func (c *client) receives(acl *TopicACL, routingKey events.RoutingKey) bool {
+	if !acl.CanReceive(c.role, routingKey) {
+		return false
+	}
+
+	for _, t := range c.topics {
+		if t.Matches(routingKey) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,309 @@
+package live
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/modules/EventBus"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/events"
+	"golang.org/x/net/websocket"
+)
+
+func newTestHub(t *testing.T, cfg HubConfig) (*Hub, *httptest.Server) {
+	gin.SetMode(gin.TestMode)
+	EventBus.New()
+
+	if cfg.ACL == nil {
+		cfg.ACL = NewTopicACL().
+			Allow("orders.*", auth.RoleUser).
+			Allow("admin.*", auth.RoleAdmin).
+			Allow("public", auth.RoleAnonymous)
+	}
+
+	hub := NewHub(cfg)
+
+	if err := hub.Start(); err != nil {
+		t.Fatalf("error starting hub: %v", err)
+	}
+
+	t.Cleanup(func() { _ = hub.Stop() })
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if role := c.GetHeader("x-role"); role != "" {
+			c.Set(auth.RoleContextKey, auth.NewRoleFromString(role))
+		}
+	})
+	r.GET("/sse", hub.SSEHandler())
+	r.GET("/ws", hub.WebSocketHandler())
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	return hub, srv
+}
+
+func waitForClients(hub *Hub, n int) {
+	for i := 0; i < 100; i++ {
+		hub.lock.Lock()
+		count := len(hub.clients)
+		hub.lock.Unlock()
+
+		if count == n {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_TopicACL(t *testing.T) {
+	acl := NewTopicACL().Allow("orders.*", auth.RoleUser).Allow("public", auth.RoleAnonymous).Allow("forgotten")
+
+	tests := []struct {
+		name  string
+		role  auth.Role
+		topic events.Topic
+		allow bool
+	}{
+		{name: "exact rule", role: auth.RoleUser, topic: "orders.*", allow: true},
+		{name: "matched by rule", role: auth.RoleUser, topic: "orders.created", allow: true},
+		{name: "wider than rule", role: auth.RoleUser, topic: "*", allow: false},
+		{name: "wrong role", role: auth.RoleSales, topic: "orders.created", allow: false},
+		{name: "super admin", role: auth.RoleSuperAdmin, topic: "orders.created", allow: true},
+		{name: "anonymous", role: auth.RoleAnonymous, topic: "public", allow: true},
+		{name: "not in acl", role: auth.RoleSuperAdmin, topic: "private", allow: false},
+		{name: "no roles", role: auth.RoleAnonymous, topic: "forgotten", allow: false},
+		{name: "no roles user", role: auth.RoleUser, topic: "forgotten", allow: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if acl.CanSubscribe(tt.role, tt.topic) != tt.allow {
+				t.Errorf("CanSubscribe(%s, %s) = %v, expected %v", tt.role, tt.topic, !tt.allow, tt.allow)
+			}
+		})
+	}
+}
+
+func Test_Hub_Connect(t *testing.T) {
+	_, srv := newTestHub(t, HubConfig{})
+
+	tests := []struct {
+		name  string
+		query string
+		role  string
+		code  int
+	}{
+		{name: "no topics", query: "", role: "user", code: http.StatusBadRequest},
+		{name: "forbidden", query: "?topic=admin.*", role: "user", code: http.StatusForbidden},
+		{name: "anonymous", query: "?topic=orders.created", code: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/sse"+tt.query, nil)
+			req.Header.Set("x-role", tt.role)
+
+			resp, err := http.DefaultClient.Do(req)
+
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.code {
+				t.Errorf("status = %d, expected %d", resp.StatusCode, tt.code)
+			}
+		})
+	}
+}
+
+func Test_Hub_SSE(t *testing.T) {
+	hub, srv := newTestHub(t, HubConfig{})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/sse?topic=orders.*,public", nil)
+	req.Header.Set("x-role", "user")
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	waitForClients(hub, 1)
+
+	_ = EventBus.Publish("admin.deleted", "secret")
+	_ = EventBus.Publish("orders.created", map[string]string{"id": "o1"})
+	_ = EventBus.Publish("public", "hello")
+
+	reader := bufio.NewReader(resp.Body)
+	frames := make([]string, 0)
+
+	for len(frames) < 2 {
+		frame := ""
+
+		for {
+			line, rErr := reader.ReadString('\n')
+
+			if rErr != nil {
+				t.Fatalf("error reading stream: %v", rErr)
+			}
+
+			if line == "\n" {
+				break
+			}
+
+			frame += line
+		}
+
+		frames = append(frames, frame)
+	}
+
+	if !strings.Contains(frames[0], `"topic":"orders.created"`) || !strings.Contains(frames[0], `"data":{"id":"o1"}`) ||
+		!strings.HasPrefix(frames[0], "id: ") {
+		t.Errorf("unexpected first frame: %s", frames[0])
+	}
+
+	if !strings.Contains(frames[1], `"topic":"public"`) {
+		t.Errorf("unexpected second frame: %s", frames[1])
+	}
+}
+
+func Test_Hub_Resume(t *testing.T) {
+	hub, _ := newTestHub(t, HubConfig{HistorySize: 2})
+
+	for _, rk := range []string{"orders.a", "orders.b", "admin.c", "orders.d"} {
+		hub.dispatch(events.NewEvent(events.RoutingKey(rk), rk))
+	}
+
+	// history holds admin.c (3) and orders.d (4). orders.b (2) is gone
+	_, missed, err := hub.subscribe(auth.RoleUser, []events.Topic{"orders.*"}, 1)
+
+	if err != nil {
+		t.Fatalf("error subscribing: %v", err)
+	}
+
+	if len(missed) != 2 || missed[0].Type != MessageGap || missed[1].Id != 4 {
+		t.Errorf("unexpected missed messages: %+v", missed)
+	}
+
+	_, missed, _ = hub.subscribe(auth.RoleUser, []events.Topic{"orders.*"}, 3)
+
+	if len(missed) != 1 || missed[0].Topic != "orders.d" {
+		t.Errorf("unexpected missed messages: %+v", missed)
+	}
+}
+
+func Test_Hub_Overflow(t *testing.T) {
+	hub, _ := newTestHub(t, HubConfig{BufferSize: 1})
+
+	slow, _, _ := hub.subscribe(auth.RoleUser, []events.Topic{"orders.*"}, 0)
+
+	hub.dispatch(events.NewEvent("orders.a", "a"))
+	hub.dispatch(events.NewEvent("orders.b", "b"))
+
+	if msg := <-slow.messages; msg.Topic != "orders.a" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	if _, ok := <-slow.messages; ok {
+		t.Errorf("slow client should have been dropped")
+	}
+
+	sent := make([]Message, 0)
+
+	hub.serve(t.Context(), slow, nil, func(msg Message) error {
+		sent = append(sent, msg)
+		return nil
+	})
+
+	if len(sent) != 1 || sent[0].Type != MessageOverflow {
+		t.Errorf("dropped client should be told about the overflow: %+v", sent)
+	}
+}
+
+func Test_Hub_WebSocket(t *testing.T) {
+	hub, srv := newTestHub(t, HubConfig{HeartbeatInterval: 20 * time.Millisecond})
+
+	cfg, _ := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?topic=admin.*", srv.URL)
+	cfg.Header.Set("x-role", "admin")
+
+	ws, err := websocket.DialConfig(cfg)
+
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+
+	defer func() { _ = ws.Close() }()
+
+	waitForClients(hub, 1)
+
+	_ = EventBus.Publish("admin.deleted", "d1")
+
+	var msg Message
+
+	if err := websocket.JSON.Receive(ws, &msg); err != nil || msg.Topic != "admin.deleted" || msg.Data != "d1" {
+		t.Errorf("unexpected message %+v: %v", msg, err)
+	}
+
+	if err := websocket.JSON.Receive(ws, &msg); err != nil || msg.Type != MessageHeartbeat {
+		t.Errorf("expected a heartbeat, got %+v: %v", msg, err)
+	}
+}
+
+func Test_Hub_WebSocket_Origin(t *testing.T) {
+	_, srv := newTestHub(t, HubConfig{HeartbeatInterval: time.Second})
+
+	cfg, _ := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?topic=admin.*", "https://evil.example.com")
+	cfg.Header.Set("x-role", "admin")
+
+	if ws, err := websocket.DialConfig(cfg); err == nil {
+		_ = ws.Close()
+		t.Errorf("connection from a foreign origin was accepted")
+	}
+}
+
+func Test_originAllowed(t *testing.T) {
+	parse := func(s string) *url.URL {
+		u, _ := url.Parse(s)
+
+		return u
+	}
+
+	tests := []struct {
+		name    string
+		origin  *url.URL
+		origins []string
+		expect  bool
+	}{
+		{name: "same origin", origin: parse("https://api.example.com"), expect: true},
+		{name: "foreign origin", origin: parse("https://evil.example.com"), expect: false},
+		{name: "no origin", origin: nil, expect: true},
+		{name: "allowed origin", origin: parse("https://app.example.com"), origins: []string{"https://app.example.com"}, expect: true},
+		{name: "same origin not listed", origin: parse("https://api.example.com"), origins: []string{"https://app.example.com"}, expect: false},
+		{name: "allow all", origin: parse("https://evil.example.com"), origins: []string{AllowAllOrigins}, expect: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := originAllowed(tt.origin, "api.example.com", tt.origins); got != tt.expect {
+				t.Errorf("originAllowed() = %v, expected %v", got, tt.expect)
+			}
+		})
+	}
+}
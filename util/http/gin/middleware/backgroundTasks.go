@@ -3,15 +3,30 @@ package middleware
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/metrics"
 	"sync"
 )
 
-// BackgroundTasks middleware that provides support for clean shutdowns when background tasks are needed
-func BackgroundTasks(wg *sync.WaitGroup) gin.HandlerFunc {
+// BackgroundTasks middleware that provides support for clean shutdowns when background tasks are needed. when reg
+// is provided, tasks started with RunBackgroundTask are counted
+func BackgroundTasks(wg *sync.WaitGroup, reg ...*metrics.Registry) gin.HandlerFunc {
+	var tasks *backgroundTaskMetrics
+
+	if len(reg) > 0 && reg[0] != nil {
+		tasks = &backgroundTaskMetrics{
+			total:  reg[0].Counter(MetricBackgroundTasks, "Total number of background tasks started.", "task"),
+			active: reg[0].Gauge(MetricBackgroundActive, "Number of background tasks currently running.", "task"),
+		}
+	}
+
 	return func(c *gin.Context) {
 		// add the server's wait group to the context so it's available downstream
 		c.Set(ContextBackgroundTasksWg, wg)
 
+		if tasks != nil {
+			c.Set(ContextBackgroundTaskMetrics, tasks)
+		}
+
 		c.Next()
 	}
 }
@@ -25,3 +40,39 @@ func GetBackgroundTasksWg(ctx context.Context) *sync.WaitGroup {
 
 	return wg
 }
+
+// RunBackgroundTask runs fn in a goroutine that the server waits for before shutting down. name labels the task
+// in the background task metrics. fn is run without the wait group when the BackgroundTasks middleware is not
+// registered
+func RunBackgroundTask(ctx context.Context, name string, fn func()) {
+	wg := GetBackgroundTasksWg(ctx)
+	tasks, _ := ctx.Value(ContextBackgroundTaskMetrics).(*backgroundTaskMetrics)
+
+	if wg != nil {
+		wg.Add(1)
+	}
+
+	if tasks != nil {
+		tasks.total.Inc(name)
+		tasks.active.Inc(name)
+	}
+
+	go func() {
+		defer func() {
+			if tasks != nil {
+				tasks.active.Dec(name)
+			}
+
+			if wg != nil {
+				wg.Done()
+			}
+		}()
+
+		fn()
+	}()
+}
+
+type backgroundTaskMetrics struct {
+	total  *metrics.Counter
+	active *metrics.Gauge
+}
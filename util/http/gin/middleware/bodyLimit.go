@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+const (
+	// ActionSettingMaxBodySize controllers.Action setting that overrides the max request body size for an action
+	// (see WithActionMaxBodySize)
+	ActionSettingMaxBodySize = "maxBodySize"
+)
+
+// BodyLimit middleware that limits the size of request bodies to maxSize bytes (unlimited when <= 0) unless the
+// action overrides it. requests that declare a larger Content-Length get a 413 response. reading more than the
+// limit from the body fails with an *http.MaxBytesError
+//
+// register after Decompress so the limit applies to the decompressed body
+func BodyLimit(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := maxSize
+
+		if v, ok := controllers.GetActionSetting(c, ActionSettingMaxBodySize); ok {
+			if l, lOk := v.(int64); lOk {
+				limit = l
+			}
+		}
+
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		c.Next()
+	}
+}
+
+// WithActionMaxBodySize overrides the max request body size for action. a size <= 0 removes the limit
+func WithActionMaxBodySize(action controllers.IAction, size int64) controllers.IAction {
+	return action.WithSetting(ActionSettingMaxBodySize, size)
+}
@@ -0,0 +1,322 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/modules/EventBus"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/data/structures"
+	"github.com/smoxy-io/goSDK/util/events"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+const (
+	CacheHeader   = "X-Cache"
+	CacheHit      = "HIT"
+	CacheMiss     = "MISS"
+	CacheBypassed = "BYPASS"
+
+	// ActionSettingCacheTtl controllers.Action setting that overrides the cache ttl for an action (see
+	// WithActionCache)
+	ActionSettingCacheTtl = "cacheTtl"
+	// ActionSettingCacheTags controllers.Action setting with the tags of the cached responses of an action
+	ActionSettingCacheTags = "cacheTags"
+
+	ContextCacheTags = "cacheTags"
+
+	// CacheInvalidationRoutingKey the EventBus routing key of cache invalidation events
+	CacheInvalidationRoutingKey = "cache.invalidate"
+)
+
+// CacheInvalidation an EventBus event that removes all cached responses with any of the tags
+type CacheInvalidation struct {
+	Tags []string `json:"tags"`
+}
+
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	tags   []string
+	// vary set on the entries that only record the request headers the responses of a key vary on (see variantKey)
+	vary []string
+}
+
+// ResponseCache an in-memory LRU cache of responses
+type ResponseCache struct {
+	entries      *structures.LRU[string, *cachedResponse]
+	ttl          time.Duration
+	subscription events.Subscriber
+	lock         *sync.Mutex
+}
+
+// NewResponseCache creates a cache that holds up to capacity responses. responses are cached for ttl unless an
+// action overrides it (see WithActionCache). when ttl is 0, only the responses of actions with a ttl are cached
+func NewResponseCache(capacity int, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		entries: structures.NewLRU[string, *cachedResponse](capacity),
+		ttl:     ttl,
+		lock:    &sync.Mutex{},
+	}
+}
+
+// Invalidate removes all cached responses with any of the tags. returns the number of removed responses
+func (rc *ResponseCache) Invalidate(tags ...string) int {
+	if len(tags) == 0 {
+		return 0
+	}
+
+	return rc.entries.DeleteFunc(func(_ string, resp *cachedResponse) bool {
+		for _, tag := range tags {
+			if slices.Contains(resp.tags, tag) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Purge removes all cached responses
+func (rc *ResponseCache) Purge() {
+	rc.entries.Purge()
+}
+
+// ListenForInvalidations invalidates cached responses when CacheInvalidation events are published on the EventBus
+// (see PublishCacheInvalidation). the EventBus MUST be started
+func (rc *ResponseCache) ListenForInvalidations() error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if rc.subscription != nil {
+		return nil
+	}
+
+	sub, err := EventBus.Subscribe(CacheInvalidationRoutingKey)
+
+	if err != nil {
+		return err
+	}
+
+	rc.subscription = sub
+
+	go func() {
+		for event := range sub {
+			if inv, ok := event.Msg.(CacheInvalidation); ok {
+				rc.Invalidate(inv.Tags...)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// StopListening stops invalidating cached responses on EventBus events
+func (rc *ResponseCache) StopListening() error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if rc.subscription == nil {
+		return nil
+	}
+
+	sub := rc.subscription
+	rc.subscription = nil
+
+	return EventBus.Unsubscribe(CacheInvalidationRoutingKey, sub)
+}
+
+// PublishCacheInvalidation publishes a CacheInvalidation event on the EventBus. every ResponseCache that is
+// listening for invalidations removes the responses with any of the tags
+func PublishCacheInvalidation(tags ...string) error {
+	if len(tags) == 0 {
+		return errors.New("at least one tag is required")
+	}
+
+	return EventBus.Publish(CacheInvalidationRoutingKey, CacheInvalidation{Tags: tags})
+}
+
+// WithActionCache sets the cache ttl and tags of an action. a negative ttl disables caching for the action
+func WithActionCache(action controllers.IAction, ttl time.Duration, tags ...string) controllers.IAction {
+	action.WithSetting(ActionSettingCacheTtl, ttl)
+
+	if len(tags) > 0 {
+		action.WithSetting(ActionSettingCacheTags, tags)
+	}
+
+	return action
+}
+
+// AddCacheTags adds tags to the response of the request if it is cached, e.g. the id of the returned record
+func AddCacheTags(c *gin.Context, tags ...string) {
+	c.Set(ContextCacheTags, append(c.GetStringSlice(ContextCacheTags), tags...))
+}
+
+// Cache middleware that serves successful GET and HEAD responses from cache. MUST be registered after the
+// middleware that authenticates the client
+//
+// responses are cached by path, query, the roles of the client and the request headers listed in the response's
+// Vary header, so only actions whose response does not depend on anything else (like the user id) should be cached.
+// actions that require multi-factor authentication, or that are authorized by more than the client's roles (see
+// controllers.Route.PerRequestChecks), are never cached. responses that set cookies, Cache-Control: no-store or
+// Vary: * are not cached. register ETag before Cache to also answer conditional requests for cached responses
+func Cache(cache *ResponseCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		ttl := cache.ttl
+
+		if v, ok := controllers.GetActionSetting(c, ActionSettingCacheTtl); ok {
+			if d, dOk := v.(time.Duration); dOk {
+				ttl = d
+			}
+		}
+
+		if ttl <= 0 {
+			c.Next()
+			return
+		}
+
+		if r, ok := controllers.GetRouteFromCtx(c); ok && r.PerRequestChecks() {
+			// a cached response would be served without the checks of the action's handler
+			c.Header(CacheHeader, CacheBypassed)
+			c.Next()
+
+			return
+		}
+
+		key := cacheKey(c)
+		resp, ok := cache.entries.Get(key)
+
+		if ok && resp.vary != nil {
+			resp, ok = cache.entries.Get(variantKey(key, c.Request, resp.vary))
+		}
+
+		if ok {
+			header := c.Writer.Header()
+
+			for k, v := range resp.header {
+				header[k] = slices.Clone(v)
+			}
+
+			header.Set(CacheHeader, CacheHit)
+
+			c.Writer.WriteHeader(resp.status)
+			_, _ = c.Writer.Write(resp.body)
+
+			c.Abort()
+
+			return
+		}
+
+		if c.Request.Method == http.MethodHead {
+			// HEAD responses have no body to cache
+			c.Header(CacheHeader, CacheBypassed)
+			c.Next()
+
+			return
+		}
+
+		c.Header(CacheHeader, CacheMiss)
+
+		buf := newResponseBuffer(c.Writer)
+		c.Writer = buf
+
+		c.Next()
+
+		c.Writer = buf.ResponseWriter
+
+		body := buf.body.Bytes()
+
+		if buf.Status() == http.StatusOK && cacheable(c.Writer.Header()) {
+			header := c.Writer.Header().Clone()
+			header.Del(CacheHeader)
+
+			var tags []string
+
+			if v, ok := controllers.GetActionSetting(c, ActionSettingCacheTags); ok {
+				tags, _ = v.([]string)
+			}
+
+			if vary := varyHeaders(header); len(vary) > 0 {
+				// responses that vary on request headers (e.g. the negotiated format) are cached per variant
+				cache.entries.Set(key, &cachedResponse{vary: vary}, ttl)
+				key = variantKey(key, c.Request, vary)
+			}
+
+			cache.entries.Set(key, &cachedResponse{
+				status: http.StatusOK,
+				header: header,
+				body:   slices.Clone(body),
+				tags:   append(slices.Clone(tags), c.GetStringSlice(ContextCacheTags)...),
+			}, ttl)
+		}
+
+		buf.flush(body)
+	}
+}
+
+// cacheKey path, sorted query and roles of the request
+func cacheKey(c *gin.Context) string {
+	return c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "#" + strconv.Itoa(int(auth.GetRolesFromCtx(c)))
+}
+
+func cacheable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	for _, v := range header.Values("Cache-Control") {
+		if strings.Contains(strings.ToLower(v), "no-store") {
+			return false
+		}
+	}
+
+	for _, v := range header.Values("Vary") {
+		if strings.TrimSpace(v) == "*" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// varyHeaders sorted, canonical names of the request headers in the Vary header
+func varyHeaders(header http.Header) []string {
+	var names []string
+
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// variantKey cache key of the response to a request that varies on the request headers
+func variantKey(key string, req *http.Request, vary []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(key)
+
+	for _, name := range vary {
+		sb.WriteString("\n" + name + ":" + strings.Join(req.Header.Values(name), ","))
+	}
+
+	return sb.String()
+}
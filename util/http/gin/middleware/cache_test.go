@@ -0,0 +1,323 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/smoxy-io/goSDK/modules/EventBus"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+func newCacheTestRouter(cache *ResponseCache, hits *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := func(c *gin.Context) {
+		*hits++
+
+		AddCacheTags(c, "item:"+c.Query("id"))
+
+		c.String(http.StatusOK, "%s:%d", auth.GetRolesFromCtx(c), *hits)
+	}
+
+	ctrl := controllers.NewController("cache")
+	ctrl.AddAction(WithActionCache(controllers.NewAction("items", handler), time.Minute, "items"))
+	ctrl.AddAction(controllers.NewAction("uncached", handler))
+	ctrl.AddAction(WithActionCache(controllers.NewAction("cookie", func(c *gin.Context) {
+		c.SetCookie("session", "x", 0, "/", "", false, true)
+		handler(c)
+	}), time.Minute))
+	ctrl.AddAction(WithActionCache(controllers.NewAction("mfa", handler).WithMfaRequired(), time.Minute))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if role := c.GetHeader("x-role"); role != "" {
+			c.Set(auth.RoleContextKey, auth.NewRoleFromString(role))
+		}
+
+		if c.GetHeader("x-mfa") != "" {
+			c.Set(auth.JwtContextKey, &auth.UserClaims{Amr: []string{auth.AmrMfa}})
+		}
+	})
+	r.Use(ETag(), Cache(cache))
+
+	ctrl.RegisterActions(r)
+
+	return r
+}
+
+func Test_Cache(t *testing.T) {
+	hits := 0
+	cache := NewResponseCache(10, 0)
+	r := newCacheTestRouter(cache, &hits)
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header []string
+		cache  string
+		body   string
+	}{
+		{name: "miss", path: "/cache/items?id=1&b=2", cache: CacheMiss, body: "anonymous:1"},
+		{name: "hit", path: "/cache/items?b=2&id=1", cache: CacheHit, body: "anonymous:1"},
+		{name: "role miss", path: "/cache/items?id=1&b=2", header: []string{"x-role", "admin"}, cache: CacheMiss, body: "admin:2"},
+		{name: "role hit", path: "/cache/items?id=1&b=2", header: []string{"x-role", "admin"}, cache: CacheHit, body: "admin:2"},
+		{name: "no ttl", path: "/cache/uncached", cache: "", body: "anonymous:3"},
+		{name: "no ttl again", path: "/cache/uncached", cache: "", body: "anonymous:4"},
+		{name: "cookie", path: "/cache/cookie", cache: CacheMiss, body: "anonymous:5"},
+		{name: "cookie not cached", path: "/cache/cookie", cache: CacheMiss, body: "anonymous:6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.path, tt.header...)
+
+			if w.Header().Get(CacheHeader) != tt.cache {
+				t.Errorf("%s = %q, expected %q", CacheHeader, w.Header().Get(CacheHeader), tt.cache)
+			}
+
+			if w.Body.String() != tt.body {
+				t.Errorf("body = %s, expected %s", w.Body.String(), tt.body)
+			}
+		})
+	}
+
+	if n := cache.Invalidate("item:1"); n != 2 {
+		t.Errorf("invalidated %d responses, expected 2", n)
+	}
+
+	if w := get("/cache/items?id=1&b=2"); w.Header().Get(CacheHeader) != CacheMiss {
+		t.Errorf("invalidated response served from cache")
+	}
+}
+
+func Test_Cache_PerRequestChecks(t *testing.T) {
+	hits := 0
+	r := newCacheTestRouter(NewResponseCache(10, 0), &hits)
+
+	get := func(header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/cache/mfa", nil)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	if w := get("x-mfa", "1"); w.Code != http.StatusOK || w.Header().Get(CacheHeader) != CacheBypassed {
+		t.Fatalf("mfa request = %d (%s: %q), expected 200 and %s", w.Code, CacheHeader, w.Header().Get(CacheHeader), CacheBypassed)
+	}
+
+	// the same path and roles without mfa must not be answered from the cache
+	if w := get(); w.Code != http.StatusForbidden {
+		t.Errorf("request without mfa = %d (%s), expected 403", w.Code, w.Body.String())
+	}
+
+	if hits != 1 {
+		t.Errorf("handler ran %d times, expected 1", hits)
+	}
+}
+
+func Test_Cache_EventBusInvalidation(t *testing.T) {
+	EventBus.New()
+
+	hits := 0
+	cache := NewResponseCache(10, 0)
+	r := newCacheTestRouter(cache, &hits)
+
+	if err := cache.ListenForInvalidations(); err != nil {
+		t.Fatalf("error listening for invalidations: %v", err)
+	}
+
+	defer func() { _ = cache.StopListening() }()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cache/items?id=2", nil))
+
+	if err := PublishCacheInvalidation("items"); err != nil {
+		t.Fatalf("error publishing invalidation: %v", err)
+	}
+
+	for i := 0; i < 100 && cache.entries.Len() > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if cache.entries.Len() != 0 {
+		t.Errorf("cache was not invalidated by the event bus")
+	}
+}
+
+func Test_ETag(t *testing.T) {
+	hits := 0
+	r := newCacheTestRouter(NewResponseCache(10, 0), &hits)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cache/uncached", nil))
+
+	etag := w.Header().Get(ETagHeader)
+
+	if etag == "" || w.Code != http.StatusOK {
+		t.Fatalf("expected an etag, got %d %v", w.Code, w.Header())
+	}
+
+	// the body changes with every hit, so only the cached action returns the same etag
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cache/items?id=3", nil))
+
+	etag = w.Header().Get(ETagHeader)
+
+	req := httptest.NewRequest(http.MethodGet, "/cache/items?id=3", nil)
+	req.Header.Set(IfNoneMatchHeader, `"other", W/`+etag)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get(CacheHeader) != CacheHit {
+		t.Errorf("expected an empty 304 from cache, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/cache/items?id=3", nil)
+	req.Header.Set(IfNoneMatchHeader, `"other"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "anonymous:"+strconv.Itoa(hits) {
+		t.Errorf("expected a 200 response, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func Test_ETag_TypedAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type accepted struct {
+		Id int `json:"id"`
+	}
+
+	ctrl := controllers.NewController("etagTyped")
+	ctrl.AddAction(controllers.NewTypedAction("accepted", func(c *gin.Context, req *struct{}) (accepted, error) {
+		// a status set by the handler must not keep the response from being rendered
+		c.Status(http.StatusAccepted)
+
+		return accepted{Id: 1}, nil
+	}))
+
+	r := gin.New()
+	r.Use(ETag())
+
+	ctrl.RegisterActions(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/etagTyped/accepted", nil))
+
+	if w.Code != http.StatusAccepted || w.Body.String() != `{"id":1}` {
+		t.Errorf("typed action behind ETag = %d %q, expected 202 {\"id\":1}", w.Code, w.Body.String())
+	}
+}
+
+func Test_Cache_Vary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type item struct {
+		Id int `json:"id"`
+	}
+
+	hits := 0
+
+	ctrl := controllers.NewController("cacheVary")
+	ctrl.AddAction(WithActionCache(controllers.NewTypedAction("item", func(c *gin.Context, req *struct{}) (item, error) {
+		hits++
+
+		return item{Id: 1}, nil
+	}), time.Minute))
+
+	r := gin.New()
+	r.Use(Cache(NewResponseCache(10, 0)))
+
+	ctrl.RegisterActions(r)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		cache       string
+		hits        int
+	}{
+		{name: "msgpack miss", accept: binding.MIMEMSGPACK, contentType: binding.MIMEMSGPACK2, cache: CacheMiss, hits: 1},
+		{name: "json miss", accept: binding.MIMEJSON, contentType: binding.MIMEJSON, cache: CacheMiss, hits: 2},
+		{name: "msgpack hit", accept: binding.MIMEMSGPACK, contentType: binding.MIMEMSGPACK2, cache: CacheHit, hits: 2},
+		{name: "json hit", accept: binding.MIMEJSON, contentType: binding.MIMEJSON, cache: CacheHit, hits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/cacheVary/item", nil)
+			req.Header.Set("Accept", tt.accept)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+				t.Errorf("Content-Type = %q, expected %q", ct, tt.contentType)
+			}
+
+			if w.Header().Get(CacheHeader) != tt.cache {
+				t.Errorf("%s = %q, expected %q", CacheHeader, w.Header().Get(CacheHeader), tt.cache)
+			}
+
+			if hits != tt.hits {
+				t.Errorf("handler ran %d times, expected %d", hits, tt.hits)
+			}
+		})
+	}
+}
+
+func Test_notModified(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set(LastModifiedHeader, modified.Format(http.TimeFormat))
+
+	tests := []struct {
+		name  string
+		since time.Time
+		match bool
+	}{
+		{name: "same", since: modified, match: true},
+		{name: "later", since: modified.Add(time.Hour), match: true},
+		{name: "earlier", since: modified.Add(-time.Hour), match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(IfModifiedSinceHeader, tt.since.Format(http.TimeFormat))
+
+			if notModified(req, header) != tt.match {
+				t.Errorf("notModified = %v, expected %v", !tt.match, tt.match)
+			}
+		})
+	}
+}
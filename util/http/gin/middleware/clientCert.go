@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// ClientCertMapper converts a verified client certificate into the claims used by this SDK
+type ClientCertMapper func(cert *x509.Certificate) (*auth.UserClaims, error)
+
+// DefaultClientCertMapper maps the subject common name to UserClaims.UserId and the subject organizational units
+// in roleMap to roles. when roleMap is nil, every client gets auth.RoleUser: whoever can get a certificate from the
+// CA chooses its organizational units, so they are never trusted as roles without an explicit map
+func DefaultClientCertMapper(roleMap map[string]auth.Role) ClientCertMapper {
+	return func(cert *x509.Certificate) (*auth.UserClaims, error) {
+		if cert.Subject.CommonName == "" {
+			return nil, errors.New("client certificate is missing the subject common name")
+		}
+
+		role := auth.RoleAnonymous
+
+		if roleMap == nil {
+			role = auth.RoleUser
+		}
+
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if r, ok := roleMap[ou]; ok {
+				role = role.Add(r)
+			}
+		}
+
+		return &auth.UserClaims{
+			UserId:    cert.Subject.CommonName,
+			Roles:     role.Names(),
+			Expires:   cert.NotAfter.UTC().Unix(),
+			NotBefore: cert.NotBefore.UTC().Unix(),
+			Issuer:    cert.Issuer.CommonName,
+		}, nil
+	}
+}
+
+// ClientCertAuth authenticates requests with the client certificate verified during the TLS handshake. the claims
+// returned by mapper (DefaultClientCertMapper(nil) when nil) and their roles are added to the context. requests
+// without a client certificate are rejected when required is true, otherwise they continue unauthenticated
+func ClientCertAuth(mapper ClientCertMapper, required bool) gin.HandlerFunc {
+	if mapper == nil {
+		mapper = DefaultClientCertMapper(nil)
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 || len(c.Request.TLS.VerifiedChains[0]) == 0 {
+			if required {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing client certificate"})
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		cert := c.Request.TLS.VerifiedChains[0][0]
+
+		claims, err := mapper(cert)
+
+		if err != nil {
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid client certificate"})
+			return
+		}
+
+		trace.SpanFromContext(c).SetAttributes(attribute.String("user.id", claims.UserId))
+
+		// add the claims and roles to the context for down stream handlers to reference
+		c.Set(auth.JwtContextKey, claims)
+		c.Set(auth.RoleContextKey, auth.NewRoleFromString(claims.Roles...))
+		c.Set(ContextClientCert, cert)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/crypto/certs"
+)
+
+func Test_ClientCertAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ca, cErr := certs.NewCA("test ca")
+
+	if cErr != nil {
+		t.Fatalf("error creating CA: %v", cErr)
+	}
+
+	client, _ := ca.IssueClient("user-1", "admin", "support", "unknown")
+	anonymous, _ := ca.IssueClient("")
+
+	newRouter := func(required bool) *gin.Engine {
+		r := gin.New()
+		r.Use(ClientCertAuth(nil, required))
+		r.GET("/", func(c *gin.Context) {
+			claims := auth.GetUserClaimsFromCtx(c)
+
+			if claims == nil {
+				c.String(http.StatusOK, "anonymous")
+				return
+			}
+
+			c.String(http.StatusOK, claims.UserId+":"+strings.Join(claims.Roles, ",")+":"+auth.GetRolesFromCtx(c).String())
+		})
+
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		required bool
+		code     int
+		expect   string
+	}{
+		{name: "organizational units are not roles", cert: client.Cert, code: http.StatusOK, expect: "user-1:user:user"},
+		{name: "optional", code: http.StatusOK, expect: "anonymous"},
+		{name: "required", required: true, code: http.StatusUnauthorized},
+		{name: "no common name", cert: anonymous.Cert, code: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			if tt.cert != nil {
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tt.cert, ca.Cert}}}
+			}
+
+			w := httptest.NewRecorder()
+			newRouter(tt.required).ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, expected %d", w.Code, tt.code)
+			}
+
+			if tt.expect != "" && w.Body.String() != tt.expect {
+				t.Errorf("body = %s, expected %s", w.Body.String(), tt.expect)
+			}
+		})
+	}
+}
+
+func Test_DefaultClientCertMapper_RoleMap(t *testing.T) {
+	ca, _ := certs.NewCA("test ca")
+	client, _ := ca.IssueClient("svc", "ops", "admin")
+
+	claims, err := DefaultClientCertMapper(map[string]auth.Role{"ops": auth.RoleDeveloper})(client.Cert)
+
+	if err != nil {
+		t.Fatalf("error mapping cert: %v", err)
+	}
+
+	if strings.Join(claims.Roles, ",") != "developer" || claims.Issuer != "test ca" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
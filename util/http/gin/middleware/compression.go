@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+	"github.com/smoxy-io/goSDK/util/http/gin/util"
+)
+
+const (
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
+	EncodingIdentity = "identity"
+
+	DefaultCompressionMinSize = 1024
+	// DefaultMaxDecompressedSize the max size of a decompressed request body when no BodyLimit applies
+	DefaultMaxDecompressedSize = 32 << 20
+)
+
+var (
+	// DefaultCompressibleTypes content types that are compressed by default. other types (images, archives, ...)
+	// are usually compressed already
+	DefaultCompressibleTypes = []string{
+		"text/",
+		"application/json",
+		"application/javascript",
+		"application/xml",
+		"application/x-protobuf",
+		"application/protobuf",
+		"application/msgpack",
+		"application/x-msgpack",
+		"image/svg+xml",
+	}
+
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+
+	gzipWriters = sync.Pool{
+		New: func() any {
+			w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+			return w
+		},
+	}
+)
+
+type CompressionConfig struct {
+	// MinSize responses smaller than MinSize bytes are not compressed. defaults to DefaultCompressionMinSize
+	MinSize int
+	// Encodings the supported encodings in order of preference when the client has no preference. defaults to
+	// zstd, gzip
+	Encodings []string
+	// ContentTypes prefixes of the content types to compress. defaults to DefaultCompressibleTypes
+	ContentTypes []string
+}
+
+// Compress middleware that compresses responses with the encoding preferred by the client's Accept-Encoding header
+//
+// responses are buffered. streaming responses (server-sent events and websockets) are not compressed
+func Compress(cfg CompressionConfig) gin.HandlerFunc {
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = DefaultCompressionMinSize
+	}
+
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = []string{EncodingZstd, EncodingGzip}
+	}
+
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressibleTypes
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Encodings)
+
+		if encoding == "" || c.Request.Method == http.MethodHead || isStreamRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		buf := newResponseBuffer(c.Writer)
+		c.Writer = buf
+
+		c.Next()
+
+		c.Writer = buf.ResponseWriter
+
+		body := buf.body.Bytes()
+		header := c.Writer.Header()
+
+		status := buf.Status()
+
+		if len(body) < cfg.MinSize || status == http.StatusNoContent || status == http.StatusNotModified ||
+			header.Get("Content-Encoding") != "" || !compressible(header.Get("Content-Type"), cfg.ContentTypes) {
+			buf.flush(body)
+			return
+		}
+
+		compressed, err := compress(encoding, body)
+
+		if err != nil {
+			_ = c.Error(err)
+			buf.flush(body)
+
+			return
+		}
+
+		header.Set("Content-Encoding", encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+
+		if etag := header.Get(ETagHeader); etag != "" && !strings.HasPrefix(etag, "W/") {
+			// the compressed body is a different representation of the resource
+			header.Set(ETagHeader, "W/"+etag)
+		}
+
+		buf.flush(compressed)
+	}
+}
+
+// Decompress middleware that decompresses gzip and zstd request bodies. requests with other content encodings get
+// a 415 response
+//
+// register before BodyLimit so the limit applies to the decompressed body. decompressed bodies are limited to the
+// action's max body size (see WithActionMaxBodySize) or, when the action has no limit, to DefaultMaxDecompressedSize
+// to protect against decompression bombs
+func Decompress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+
+		if encoding == "" || encoding == EncodingIdentity || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := int64(DefaultMaxDecompressedSize)
+
+		if v, ok := controllers.GetActionSetting(c, ActionSettingMaxBodySize); ok {
+			if l, lOk := v.(int64); lOk && l > 0 {
+				limit = l
+			}
+		}
+
+		var reader io.ReadCloser
+
+		switch encoding {
+		case EncodingGzip:
+			gz, err := gzip.NewReader(c.Request.Body)
+
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid gzip body"})
+				return
+			}
+
+			reader = gz
+		case EncodingZstd:
+			zr, err := zstd.NewReader(c.Request.Body, zstd.WithDecoderMaxMemory(uint64(limit)))
+
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid zstd body"})
+				return
+			}
+
+			reader = zr.IOReadCloser()
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content encoding " + encoding})
+			return
+		}
+
+		body := c.Request.Body
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, &decompressedBody{
+			Reader: reader,
+			close: func() error {
+				_ = reader.Close()
+				return body.Close()
+			},
+		}, limit)
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+
+		c.Next()
+	}
+}
+
+type decompressedBody struct {
+	io.Reader
+	close func() error
+}
+
+func (b *decompressedBody) Close() error {
+	return b.close()
+}
+
+// negotiateEncoding returns the supported encoding preferred by the client. returns an empty string when the
+// response should not be compressed
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	rejected := util.ParseAcceptRejected(acceptEncoding)
+
+	for _, enc := range util.ParseAccept(acceptEncoding) {
+		if enc == "*" {
+			// any encoding the client did not reject
+			for _, s := range supported {
+				if !slices.Contains(rejected, s) {
+					return s
+				}
+			}
+
+			return ""
+		}
+
+		if enc == EncodingIdentity {
+			return ""
+		}
+
+		if slices.Contains(supported, enc) {
+			return enc
+		}
+	}
+
+	return ""
+}
+
+func compress(encoding string, body []byte) ([]byte, error) {
+	if encoding == EncodingZstd {
+		return zstdEncoder.EncodeAll(body, make([]byte, 0, len(body)/2)), nil
+	}
+
+	out := &bytes.Buffer{}
+
+	gz := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(gz)
+
+	gz.Reset(out)
+
+	if _, err := gz.Write(body); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func compressible(contentType string, types []string) bool {
+	contentType = strings.ToLower(contentType)
+
+	for _, t := range types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isStreamRequest(req *http.Request) bool {
+	return req.Header.Get("Upgrade") != "" || strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
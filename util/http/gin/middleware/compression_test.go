@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+func Test_Compress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("compress me ", 200)
+
+	r := gin.New()
+	r.Use(Compress(CompressionConfig{}), ETag())
+	r.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	r.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "small") })
+	r.GET("/image", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(large)) })
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+	}{
+		{name: "zstd preferred", path: "/large", accept: "gzip;q=0.5, zstd", encoding: EncodingZstd},
+		{name: "gzip", path: "/large", accept: "gzip, deflate", encoding: EncodingGzip},
+		{name: "any", path: "/large", accept: "*", encoding: EncodingZstd},
+		{name: "any but zstd", path: "/large", accept: "*, zstd;q=0", encoding: EncodingGzip},
+		{name: "any but refused", path: "/large", accept: "zstd;q=0, gzip;q=0, *", encoding: ""},
+		{name: "not accepted", path: "/large", accept: "br", encoding: ""},
+		{name: "gzip refused", path: "/large", accept: "gzip;q=0", encoding: ""},
+		{name: "too small", path: "/small", accept: "gzip", encoding: ""},
+		{name: "not compressible", path: "/image", accept: "gzip", encoding: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if enc := w.Header().Get("Content-Encoding"); enc != tt.encoding {
+				t.Fatalf("Content-Encoding = %q, expected %q", enc, tt.encoding)
+			}
+
+			var body []byte
+
+			switch tt.encoding {
+			case EncodingGzip:
+				gz, err := gzip.NewReader(w.Body)
+
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+
+				body, _ = io.ReadAll(gz)
+			case EncodingZstd:
+				zr, _ := zstd.NewReader(w.Body)
+				defer zr.Close()
+
+				body, _ = io.ReadAll(zr)
+			default:
+				return
+			}
+
+			if string(body) != large {
+				t.Errorf("decompressed body does not match")
+			}
+
+			if !strings.HasPrefix(w.Header().Get(ETagHeader), "W/") {
+				t.Errorf("compressed responses should have a weak etag, got %s", w.Header().Get(ETagHeader))
+			}
+		})
+	}
+}
+
+func Test_Compress_TypedAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type created struct {
+		Id int `json:"id"`
+	}
+
+	ctrl := controllers.NewController("compressTyped")
+	ctrl.AddAction(controllers.NewTypedAction("create", func(c *gin.Context, req *struct{}) (created, error) {
+		// a status set by the handler must not keep the response from being rendered
+		c.Status(http.StatusCreated)
+
+		return created{Id: 1}, nil
+	}, http.MethodPost))
+
+	r := gin.New()
+	r.Use(Compress(CompressionConfig{MinSize: 1}))
+
+	ctrl.RegisterActions(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/compressTyped/create", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", EncodingGzip)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != EncodingGzip {
+		t.Fatalf("typed action behind Compress = %d (Content-Encoding: %q), expected 201 gzip", w.Code, w.Header().Get("Content-Encoding"))
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+
+	body, _ := io.ReadAll(gz)
+
+	if string(body) != `{"id":1}` {
+		t.Errorf("body = %q, expected {\"id\":1}", body)
+	}
+}
+
+func Test_Decompress_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+
+		if err != nil {
+			c.String(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+
+		c.String(http.StatusOK, string(body))
+	}
+
+	ctrl := controllers.NewController("limits")
+	ctrl.AddAction(controllers.NewAction("echo", echo, http.MethodPost))
+	ctrl.AddAction(WithActionMaxBodySize(controllers.NewAction("large", echo, http.MethodPost), 1024))
+
+	ctrl.AddAction(WithActionMaxBodySize(controllers.NewAction("small", echo, http.MethodPost), 16))
+
+	r := gin.New()
+	r.Use(Decompress(), BodyLimit(16))
+
+	ctrl.RegisterActions(r)
+
+	// without BodyLimit the action's limit still applies to the decompressed body
+	unlimited := gin.New()
+	unlimited.Use(Decompress())
+
+	ctrl.RegisterActions(unlimited)
+
+	gzipped := &bytes.Buffer{}
+	gz := gzip.NewWriter(gzipped)
+	_, _ = gz.Write([]byte(strings.Repeat("a", 100)))
+	_ = gz.Close()
+
+	zstdBody := (&bytes.Buffer{})
+	zw, _ := zstd.NewWriter(zstdBody)
+	_, _ = zw.Write([]byte("zstd"))
+	_ = zw.Close()
+
+	tests := []struct {
+		name     string
+		router   *gin.Engine
+		path     string
+		body     []byte
+		encoding string
+		code     int
+		expect   string
+	}{
+		{name: "plain", path: "/limits/echo", body: []byte("hello"), code: http.StatusOK, expect: "hello"},
+		{name: "content length", path: "/limits/echo", body: []byte(strings.Repeat("a", 17)), code: http.StatusRequestEntityTooLarge},
+		{name: "zstd", path: "/limits/echo", body: zstdBody.Bytes(), encoding: EncodingZstd, code: http.StatusOK, expect: "zstd"},
+		{name: "decompressed too large", path: "/limits/echo", body: gzipped.Bytes(), encoding: EncodingGzip, code: http.StatusRequestEntityTooLarge},
+		{name: "action limit", path: "/limits/large", body: gzipped.Bytes(), encoding: EncodingGzip, code: http.StatusOK, expect: strings.Repeat("a", 100)},
+		{name: "decompress action limit", router: unlimited, path: "/limits/small", body: gzipped.Bytes(), encoding: EncodingGzip, code: http.StatusRequestEntityTooLarge},
+		{name: "decompress default limit", router: unlimited, path: "/limits/echo", body: gzipped.Bytes(), encoding: EncodingGzip, code: http.StatusOK, expect: strings.Repeat("a", 100)},
+		{name: "unsupported encoding", path: "/limits/echo", body: []byte("x"), encoding: "br", code: http.StatusUnsupportedMediaType},
+		{name: "invalid gzip", path: "/limits/echo", body: []byte("not gzip"), encoding: EncodingGzip, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(tt.body))
+
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			router := r
+
+			if tt.router != nil {
+				router = tt.router
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, expected %d: %s", w.Code, tt.code, w.Body.String())
+			}
+
+			if tt.expect != "" && w.Body.String() != tt.expect {
+				t.Errorf("body = %s, expected %s", w.Body.String(), tt.expect)
+			}
+		})
+	}
+}
@@ -1,12 +1,31 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/metrics"
+)
 
-func MaxConns(n int) gin.HandlerFunc {
+// MaxConns limits the number of requests that are handled concurrently. requests over the limit wait for a slot.
+// when reg is provided the limit and the number of waiting requests are exported as metrics
+func MaxConns(n int, reg ...*metrics.Registry) gin.HandlerFunc {
 	conns := make(chan struct{}, n)
 
+	if len(reg) < 1 || reg[0] == nil {
+		return func(c *gin.Context) {
+			acquire(conns)       // before request
+			defer release(conns) // after request
+			c.Next()
+		}
+	}
+
+	reg[0].Gauge(MetricConnLimit, "Maximum number of HTTP requests handled concurrently.").Set(float64(n))
+	waiting := reg[0].Gauge(MetricConnsWaiting, "Number of HTTP requests waiting for a connection slot.")
+
 	return func(c *gin.Context) {
-		acquire(conns)       // before request
+		waiting.Inc()
+		acquire(conns) // before request
+		waiting.Dec()
+
 		defer release(conns) // after request
 		c.Next()
 	}
@@ -1,9 +1,11 @@
 package middleware
 
 const (
-	AuthHeader               = "x-api-key"
-	AuthQueryParam           = "apiKey"
-	ContextUserKey           = "user"
-	ContextApiKeyId          = "apiKeyId"
-	ContextBackgroundTasksWg = "backgroundTaskWg"
+	AuthHeader                   = "x-api-key"
+	AuthQueryParam               = "apiKey"
+	ContextUserKey               = "user"
+	ContextApiKeyId              = "apiKeyId"
+	ContextBackgroundTasksWg     = "backgroundTaskWg"
+	ContextBackgroundTaskMetrics = "backgroundTaskMetrics"
+	ContextClientCert            = "clientCert"
 )
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+const (
+	ETagHeader            = "ETag"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+	LastModifiedHeader    = "Last-Modified"
+)
+
+// ETag middleware that adds an ETag header to successful GET and HEAD responses and responds with 304 Not Modified
+// when the request's If-None-Match (or If-Modified-Since when the handler sets Last-Modified) shows the client
+// already has the response. the ETag is a hash of the body unless the handler sets its own
+//
+// responses are buffered, so ETag should not be used for streaming responses
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		buf := newResponseBuffer(c.Writer)
+		c.Writer = buf
+
+		c.Next()
+
+		c.Writer = buf.ResponseWriter
+
+		body := buf.body.Bytes()
+
+		if buf.Status() != http.StatusOK {
+			buf.flush(body)
+			return
+		}
+
+		header := c.Writer.Header()
+
+		if header.Get(ETagHeader) == "" {
+			header.Set(ETagHeader, computeETag(body))
+		}
+
+		if notModified(c.Request, header) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+
+			return
+		}
+
+		buf.flush(body)
+	}
+}
+
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates the conditional request headers as described in RFC 9110 section 13.2.2
+func notModified(req *http.Request, header http.Header) bool {
+	if inm := req.Header.Get(IfNoneMatchHeader); inm != "" {
+		return etagMatches(inm, header.Get(ETagHeader))
+	}
+
+	ims := req.Header.Get(IfModifiedSinceHeader)
+	lm := header.Get(LastModifiedHeader)
+
+	if ims == "" || lm == "" {
+		return false
+	}
+
+	imsTime, iErr := http.ParseTime(ims)
+	lmTime, lErr := http.ParseTime(lm)
+
+	if iErr != nil || lErr != nil {
+		return false
+	}
+
+	return !lmTime.After(imsTime)
+}
+
+// etagMatches weak comparison of the etags in an If-None-Match header against etag
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
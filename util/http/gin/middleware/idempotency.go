@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	DefaultIdempotencyWindow = 24 * time.Hour
+	// DefaultIdempotencyClaimTtl how long a key stays claimed by a request that never finished, e.g. because its
+	// instance died
+	DefaultIdempotencyClaimTtl = 5 * time.Minute
+	MaxIdempotencyKeyLength    = 255
+)
+
+// IdempotentResponse the stored first response to a request with an idempotency key
+type IdempotentResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// RequestHash a hash of the request the response belongs to. used to detect reuse of a key for a different request
+	RequestHash string `json:"requestHash"`
+}
+
+// IdempotencyStore stores responses by key. Get returns nil when the key has no response
+//
+// a request claims its key while it is handled, so a store shared by several instances detects concurrent
+// duplicates across them. Claim MUST be atomic (like SET NX) and return false when the key has a response or is
+// claimed. Set replaces the claim with the response and Release removes the claim of a request without a response
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (*IdempotentResponse, error)
+	Set(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyScopeFunc returns the scope of idempotency keys so clients cannot replay each other's responses
+type IdempotencyScopeFunc func(c *gin.Context) string
+
+type IdempotencyConfig struct {
+	// Window how long responses are replayed for. defaults to DefaultIdempotencyWindow
+	Window time.Duration
+	// ClaimTtl how long a request can hold its key. defaults to DefaultIdempotencyClaimTtl
+	ClaimTtl time.Duration
+	// Store defaults to a new MemoryIdempotencyStore
+	Store IdempotencyStore
+	// ScopeFunc defaults to RateLimitByIdentity (api key, then user, then ip address)
+	ScopeFunc IdempotencyScopeFunc
+	// Required rejects unsafe requests without an idempotency key
+	Required bool
+}
+
+// Idempotency middleware that makes POST, PUT, PATCH and DELETE requests with an Idempotency-Key header safe to
+// retry. MUST be registered after the middleware that authenticates the client
+//
+// the first response (except 5xx responses, which can be retried) is stored per key and client and replayed for
+// retries with an Idempotent-Replayed header. a retry while the first request is still being handled gets a 409
+// response and reusing a key for a different request gets a 422 response. concurrent duplicates are detected by all
+// instances that share the Store
+func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultIdempotencyWindow
+	}
+
+	if cfg.ClaimTtl <= 0 {
+		cfg.ClaimTtl = DefaultIdempotencyClaimTtl
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+
+	if cfg.ScopeFunc == nil {
+		cfg.ScopeFunc = IdempotencyScopeFunc(RateLimitByIdentity)
+	}
+
+	return func(c *gin.Context) {
+		if !slices.Contains([]string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		idemKey := c.GetHeader(IdempotencyKeyHeader)
+
+		if idemKey == "" {
+			if cfg.Required {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "missing " + IdempotencyKeyHeader + " header"})
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if len(idemKey) > MaxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + IdempotencyKeyHeader + " header"})
+			return
+		}
+
+		reqHash, hErr := requestHash(c)
+
+		if hErr != nil {
+			_ = c.Error(hErr)
+
+			var maxErr *http.MaxBytesError
+
+			if errors.As(hErr, &maxErr) {
+				// the body is larger than the BodyLimit
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "error reading request body"})
+			return
+		}
+
+		key := cfg.ScopeFunc(c) + "|" + idemKey
+
+		stored, sErr := cfg.Store.Get(c, key)
+
+		if sErr != nil {
+			// fail closed. handling the request could create a duplicate
+			_ = c.Error(sErr)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "idempotency store unavailable"})
+			return
+		}
+
+		if stored != nil {
+			if stored.RequestHash != reqHash {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": IdempotencyKeyHeader + " was used for a different request"})
+				return
+			}
+
+			header := c.Writer.Header()
+
+			for k, v := range stored.Header {
+				header[k] = slices.Clone(v)
+			}
+
+			header.Set(IdempotencyReplayedHeader, "true")
+
+			c.Writer.WriteHeader(stored.Status)
+			_, _ = c.Writer.Write(stored.Body)
+
+			c.Abort()
+
+			return
+		}
+
+		claimed, cErr := cfg.Store.Claim(c, key, cfg.ClaimTtl)
+
+		if cErr != nil {
+			_ = c.Error(cErr)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "idempotency store unavailable"})
+			return
+		}
+
+		if !claimed {
+			// a retry gets the response once the request holding the key finished
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with the same idempotency key is in progress"})
+			return
+		}
+
+		responded := false
+
+		defer func() {
+			if !responded {
+				// the request can be retried
+				_ = cfg.Store.Release(context.WithoutCancel(c), key)
+			}
+		}()
+
+		buf := newResponseBuffer(c.Writer)
+		c.Writer = buf
+
+		c.Next()
+
+		c.Writer = buf.ResponseWriter
+
+		body := buf.body.Bytes()
+
+		if buf.Status() < http.StatusInternalServerError {
+			if err := cfg.Store.Set(c, key, &IdempotentResponse{
+				Status:      buf.Status(),
+				Header:      c.Writer.Header().Clone(),
+				Body:        slices.Clone(body),
+				RequestHash: reqHash,
+			}, cfg.Window); err != nil {
+				_ = c.Error(err)
+			} else {
+				responded = true
+			}
+		}
+
+		buf.flush(body)
+	}
+}
+
+// requestHash hashes the method, path and body of the request. the body is restored so handlers can read it
+func requestHash(c *gin.Context) (string, error) {
+	h := sha256.New()
+
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(c.Request.Body)
+
+		if err != nil {
+			return "", err
+		}
+
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// MemoryIdempotencyStore an in-memory IdempotencyStore. only suitable for single instance deployments
+type MemoryIdempotencyStore struct {
+	responses map[string]*memoryIdempotentResponse
+	lock      *sync.Mutex
+}
+
+type memoryIdempotentResponse struct {
+	// resp nil while the key is claimed
+	resp    *IdempotentResponse
+	expires time.Time
+}
+
+func (m *MemoryIdempotencyStore) Get(ctx context.Context, key string) (*IdempotentResponse, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	r, ok := m.responses[key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	if time.Now().After(r.expires) {
+		delete(m.responses, key)
+		return nil, nil
+	}
+
+	return r.resp, nil
+}
+
+func (m *MemoryIdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if r, ok := m.responses[key]; ok && !time.Now().After(r.expires) {
+		return false, nil
+	}
+
+	m.responses[key] = &memoryIdempotentResponse{expires: time.Now().Add(ttl)}
+
+	return true, nil
+}
+
+func (m *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if r, ok := m.responses[key]; ok && r.resp == nil {
+		delete(m.responses, key)
+	}
+
+	return nil
+}
+
+func (m *MemoryIdempotencyStore) Set(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.responses[key] = &memoryIdempotentResponse{resp: resp, expires: time.Now().Add(ttl)}
+
+	return nil
+}
+
+// Cleanup removes expired responses
+func (m *MemoryIdempotencyStore) Cleanup() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+
+	for k, r := range m.responses {
+		if now.After(r.expires) {
+			delete(m.responses, k)
+		}
+	}
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		responses: map[string]*memoryIdempotentResponse{},
+		lock:      &sync.Mutex{},
+	}
+}
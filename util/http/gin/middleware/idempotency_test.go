@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+func Test_Idempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var created atomic.Int32
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("x-user"); user != "" {
+			c.Set(auth.JwtContextKey, &auth.UserClaims{UserId: user})
+		}
+	})
+	r.Use(Idempotency(IdempotencyConfig{Window: time.Minute}))
+	r.POST("/records", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+
+		if string(body) == "slow" {
+			started <- struct{}{}
+			<-block
+		}
+
+		id := created.Add(1)
+
+		c.Header("Location", "/records/"+strconv.Itoa(int(id)))
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+	r.POST("/fail", func(c *gin.Context) {
+		created.Add(1)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+	})
+
+	post := func(path string, key string, user string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		req.Header.Set("x-user", user)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	first := post("/records", "k1", "u1", "a")
+
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Fatalf("unexpected first response %d %v", first.Code, first.Header())
+	}
+
+	retry := post("/records", "k1", "u1", "a")
+
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+		retry.Header().Get("Location") != first.Header().Get("Location") || retry.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf("retry was not replayed: %d %s %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+
+	if created.Load() != 1 {
+		t.Errorf("created %d records, expected 1", created.Load())
+	}
+
+	if w := post("/records", "k1", "u1", "b"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key for a different body = %d, expected 422", w.Code)
+	}
+
+	if w := post("/records", "k1", "u2", "a"); w.Code != http.StatusCreated || w.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Errorf("keys should be scoped per user, got %d %v", w.Code, w.Header())
+	}
+
+	if w := post("/records", "", "u1", "a"); w.Code != http.StatusCreated || created.Load() != 3 {
+		t.Errorf("request without a key should not be deduplicated")
+	}
+
+	// server errors are not stored so they can be retried
+	post("/fail", "k2", "u1", "")
+	post("/fail", "k2", "u1", "")
+
+	if created.Load() != 5 {
+		t.Errorf("server errors should not be replayed")
+	}
+
+	// concurrent duplicates are rejected
+	done := make(chan *httptest.ResponseRecorder)
+
+	go func() {
+		done <- post("/records", "k3", "u1", "slow")
+	}()
+
+	<-started
+
+	if w := post("/records", "k3", "u1", "slow"); w.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate = %d, expected 409", w.Code)
+	}
+
+	close(block)
+
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("slow request = %d, expected 201", w.Code)
+	}
+}
+
+func Test_Idempotency_SharedStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var handled atomic.Int32
+
+	store := NewMemoryIdempotencyStore()
+
+	newInstance := func() *gin.Engine {
+		r := gin.New()
+		r.Use(Idempotency(IdempotencyConfig{Store: store, ScopeFunc: func(c *gin.Context) string { return "client" }}))
+		r.POST("/", func(c *gin.Context) {
+			handled.Add(1)
+			c.Status(http.StatusNoContent)
+		})
+
+		return r
+	}
+
+	post := func(r *gin.Engine) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	a, b := newInstance(), newInstance()
+
+	// another instance is handling the request
+	if ok, _ := store.Claim(context.Background(), "client|k1", time.Minute); !ok {
+		t.Fatalf("Claim() of a new key returned false")
+	}
+
+	if code := post(b); code != http.StatusConflict || handled.Load() != 0 {
+		t.Errorf("request claimed by another instance = %d, expected 409", code)
+	}
+
+	_ = store.Release(context.Background(), "client|k1")
+
+	if code := post(a); code != http.StatusNoContent {
+		t.Errorf("released request = %d, expected 204", code)
+	}
+
+	if code := post(b); code != http.StatusNoContent || handled.Load() != 1 {
+		t.Errorf("retry on another instance = %d (handled %d times), expected a replayed 204", code, handled.Load())
+	}
+}
+
+func Test_Idempotency_Required(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Idempotency(IdempotencyConfig{Required: true}))
+	r.POST("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST without a key = %d, expected 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("GET without a key = %d, expected 204", w.Code)
+	}
+}
+
+func Test_Idempotency_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(BodyLimit(4), Idempotency(IdempotencyConfig{}))
+	r.POST("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("larger than the limit"))
+	req.Header.Set(IdempotencyKeyHeader, "key")
+	// an unknown length is only caught while the body is read
+	req.ContentLength = -1
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST over the body limit = %d, expected 413", w.Code)
+	}
+}
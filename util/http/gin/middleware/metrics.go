@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+	"github.com/smoxy-io/goSDK/util/metrics"
+)
+
+const (
+	MetricRequestsTotal    = "http_requests_total"
+	MetricRequestErrors    = "http_request_errors_total"
+	MetricRequestDuration  = "http_request_duration_seconds"
+	MetricRequestsInFlight = "http_requests_in_flight"
+	MetricConnLimit        = "http_conn_limit"
+	MetricConnsWaiting     = "http_conns_waiting"
+	MetricBackgroundTasks  = "background_tasks_total"
+	MetricBackgroundActive = "background_tasks_active"
+
+	// metricsNoAction label value for requests that were not handled by a controller action (404s, health checks...)
+	metricsNoAction = "none"
+	// metricsOtherMethod label value for requests with a non-standard method. clients choose the method, so using it
+	// as is would let them create any number of series
+	metricsOtherMethod = "OTHER"
+)
+
+// Metrics middleware that records request rate, errors (5xx responses) and duration labelled by the controller
+// and action that handled the request
+func Metrics(reg *metrics.Registry) gin.HandlerFunc {
+	requests := reg.Counter(MetricRequestsTotal, "Total number of HTTP requests.", "controller", "action", "method", "code")
+	errs := reg.Counter(MetricRequestErrors, "Total number of HTTP requests that failed with a 5xx response.", "controller", "action", "method")
+	duration := reg.Histogram(MetricRequestDuration, "HTTP request duration in seconds.", nil, "controller", "action", "method")
+	inFlight := reg.Gauge(MetricRequestsInFlight, "Number of HTTP requests currently being handled.")
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		controller, action := controllers.GetActionFromCtx(c)
+
+		if controller == "" {
+			controller = metricsNoAction
+			action = metricsNoAction
+		}
+
+		method := metricsMethod(c.Request.Method)
+		status := c.Writer.Status()
+
+		requests.Inc(controller, action, method, strconv.Itoa(status))
+		duration.Observe(time.Since(start).Seconds(), controller, action, method)
+
+		if status >= http.StatusInternalServerError {
+			errs.Inc(controller, action, method)
+		}
+	}
+}
+
+// metricsMethod returns the method label of a request method
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return metricsOtherMethod
+}
+
+// MetricsHandler serves the metrics in reg in the Prometheus text format
+func MetricsHandler(reg *metrics.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", metrics.ContentType)
+
+		if err := reg.WriteText(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/metrics"
+)
+
+func Test_Metrics_Method(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reg := metrics.NewRegistry()
+
+	r := gin.New()
+	r.Use(Metrics(reg))
+
+	for _, method := range []string{http.MethodGet, "FOOBAR", "BAZ"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/", nil))
+	}
+
+	out := &strings.Builder{}
+
+	if err := reg.WriteText(out); err != nil {
+		t.Fatalf("WriteText() returned an error: %v", err)
+	}
+
+	text := out.String()
+
+	if !strings.Contains(text, `method="GET"`) {
+		t.Errorf("standard method is not a label value:\n%s", text)
+	}
+
+	if strings.Contains(text, "FOOBAR") || strings.Contains(text, "BAZ") {
+		t.Errorf("non-standard methods are label values:\n%s", text)
+	}
+
+	if !strings.Contains(text, `method="OTHER",code="404"} 2`) {
+		t.Errorf("non-standard methods are not counted as OTHER:\n%s", text)
+	}
+}
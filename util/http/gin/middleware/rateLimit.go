@@ -0,0 +1,362 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+
+	// ActionSettingRateLimit controllers.Action setting that overrides the rate limit for an action (see
+	// WithActionRateLimit)
+	ActionSettingRateLimit = "rateLimit"
+)
+
+// Rate allows Limit requests per Period. Burst is the size of the token bucket (defaults to Limit) and is ignored
+// by the sliding window algorithm. a zero Rate means unlimited
+type Rate struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+func (r Rate) unlimited() bool {
+	return r.Limit <= 0 || r.Period <= 0
+}
+
+func (r Rate) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+
+	return r.Limit
+}
+
+// RateLimitState the per-key state kept by rate limit algorithms
+type RateLimitState struct {
+	// token bucket
+	Tokens  float64   `json:"tokens,omitempty"`
+	Updated time.Time `json:"updated,omitempty"`
+	// sliding window
+	WindowStart time.Time `json:"windowStart,omitempty"`
+	Count       int       `json:"count,omitempty"`
+	PrevCount   int       `json:"prevCount,omitempty"`
+}
+
+// RateLimitResult the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset time until the allowance is fully restored
+	Reset time.Duration
+	// RetryAfter time until the next request will be allowed. only set when the request is not allowed
+	RetryAfter time.Duration
+	// Ttl how long the state must be kept for
+	Ttl time.Duration
+}
+
+// RateLimitAlgorithm takes a request from the allowance in state and updates state
+type RateLimitAlgorithm func(state *RateLimitState, rate Rate, now time.Time) RateLimitResult
+
+// RateLimitStore stores rate limit state. Update MUST apply fn atomically for a key. state is the zero value when
+// the key has no state. the state is kept for the ttl returned by fn
+type RateLimitStore interface {
+	Update(ctx context.Context, key string, fn func(state *RateLimitState) (ttl time.Duration)) error
+}
+
+// RateLimitKeyFunc returns the key requests are limited by. requests with an empty key are not limited
+type RateLimitKeyFunc func(c *gin.Context) string
+
+type RateLimitConfig struct {
+	// Rate the default rate for all requests. actions can override it with WithActionRateLimit
+	Rate Rate
+	// Algorithm defaults to TokenBucket
+	Algorithm RateLimitAlgorithm
+	// Store defaults to a new MemoryRateLimitStore
+	Store RateLimitStore
+	// KeyFunc defaults to RateLimitByIdentity
+	KeyFunc RateLimitKeyFunc
+}
+
+// RateLimit middleware that limits the request rate per client. MUST be registered after the middleware that
+// authenticates the client when limiting by api key or user
+//
+// requests to actions with a rate override (see WithActionRateLimit) are limited separately from other requests.
+// allowed requests get RateLimit-* headers, rejected requests get a 429 response with a Retry-After header. the
+// request is allowed when the store fails
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	if cfg.Algorithm == nil {
+		cfg.Algorithm = TokenBucket
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore()
+	}
+
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RateLimitByIdentity
+	}
+
+	return func(c *gin.Context) {
+		rate := cfg.Rate
+		key := cfg.KeyFunc(c)
+
+		if override, ok := controllers.GetActionSetting(c, ActionSettingRateLimit); ok {
+			if r, rOk := override.(Rate); rOk {
+				rate = r
+				key += "|" + c.FullPath()
+			}
+		}
+
+		if key == "" || rate.unlimited() {
+			c.Next()
+			return
+		}
+
+		var res RateLimitResult
+
+		if err := cfg.Store.Update(c, key, func(state *RateLimitState) time.Duration {
+			res = cfg.Algorithm(state, rate, time.Now())
+			return res.Ttl
+		}); err != nil {
+			// fail open. a broken store should not take the service down
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(res.Reset)))
+		c.Header(RateLimitPolicyHeader, strconv.Itoa(rate.Limit)+";w="+strconv.Itoa(ceilSeconds(rate.Period)))
+
+		if !res.Allowed {
+			c.Header(RetryAfterHeader, strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// WithActionRateLimit overrides the rate limit for action. requests to the action are counted separately from
+// other requests. a zero Rate disables rate limiting for the action
+func WithActionRateLimit(action controllers.IAction, rate Rate) controllers.IAction {
+	return action.WithSetting(ActionSettingRateLimit, rate)
+}
+
+// TokenBucket allows bursts of up to rate.Burst requests and refills the bucket at rate.Limit tokens per
+// rate.Period
+func TokenBucket(state *RateLimitState, rate Rate, now time.Time) RateLimitResult {
+	capacity := float64(rate.burst())
+	perSecond := float64(rate.Limit) / rate.Period.Seconds()
+
+	if state.Updated.IsZero() {
+		state.Tokens = capacity
+	} else if elapsed := now.Sub(state.Updated).Seconds(); elapsed > 0 {
+		state.Tokens = min(capacity, state.Tokens+elapsed*perSecond)
+	}
+
+	state.Updated = now
+
+	res := RateLimitResult{Limit: rate.burst()}
+
+	if state.Tokens >= 1 {
+		state.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - state.Tokens) / perSecond)
+	}
+
+	res.Remaining = int(math.Floor(state.Tokens))
+	res.Reset = secondsToDuration((capacity - state.Tokens) / perSecond)
+	res.Ttl = secondsToDuration(capacity/perSecond) + time.Second
+
+	return res
+}
+
+// SlidingWindow allows rate.Limit requests in any rate.Period. the count for the sliding window is estimated from
+// the counts of the current and previous fixed windows
+func SlidingWindow(state *RateLimitState, rate Rate, now time.Time) RateLimitResult {
+	window := now.Truncate(rate.Period)
+
+	if !state.WindowStart.Equal(window) {
+		if state.WindowStart.Equal(window.Add(-rate.Period)) {
+			state.PrevCount = state.Count
+		} else {
+			state.PrevCount = 0
+		}
+
+		state.Count = 0
+		state.WindowStart = window
+	}
+
+	elapsed := now.Sub(window)
+	weight := 1 - float64(elapsed)/float64(rate.Period)
+	estimated := float64(state.PrevCount)*weight + float64(state.Count)
+
+	res := RateLimitResult{
+		Limit: rate.Limit,
+		Reset: rate.Period - elapsed,
+		Ttl:   2 * rate.Period,
+	}
+
+	if estimated+1 <= float64(rate.Limit) {
+		state.Count++
+		estimated++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = slidingWindowRetryAfter(state, rate, elapsed)
+	}
+
+	res.Remaining = max(0, rate.Limit-int(math.Ceil(estimated)))
+
+	return res
+}
+
+// slidingWindowRetryAfter the time until the estimated count drops far enough to allow another request
+func slidingWindowRetryAfter(state *RateLimitState, rate Rate, elapsed time.Duration) time.Duration {
+	room := float64(rate.Limit - 1)
+
+	if float64(state.Count) > room {
+		// the current window is full. wait for the next window, where the current count becomes the previous count
+		return rate.Period - elapsed + time.Duration(float64(rate.Period)*(1-room/float64(state.Count)))
+	}
+
+	// wait for the previous window's weight to drop
+	at := time.Duration(float64(rate.Period) * (1 - (room-float64(state.Count))/float64(state.PrevCount)))
+
+	return max(0, at-elapsed)
+}
+
+// RateLimitByApiKey limits requests by the id of the api key set by ApiKeyAuthRequired
+func RateLimitByApiKey(c *gin.Context) string {
+	if id := c.GetString(ContextApiKeyId); id != "" {
+		return "key:" + id
+	}
+
+	return ""
+}
+
+// RateLimitByUser limits requests by the user id of the caller's auth.UserClaims
+func RateLimitByUser(c *gin.Context) string {
+	if id := auth.GetUserIdFromCtx(c); id != "" {
+		return "user:" + id
+	}
+
+	return ""
+}
+
+// RateLimitByIp limits requests by the client's ip address
+func RateLimitByIp(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByIdentity limits requests by api key, then user and falls back to the client's ip address
+func RateLimitByIdentity(c *gin.Context) string {
+	if k := RateLimitByApiKey(c); k != "" {
+		return k
+	}
+
+	if k := RateLimitByUser(c); k != "" {
+		return k
+	}
+
+	return RateLimitByIp(c)
+}
+
+const (
+	// memoryRateLimitCleanupInterval how often Update removes the expired state of a MemoryRateLimitStore
+	memoryRateLimitCleanupInterval = time.Minute
+	// memoryRateLimitMinCleanupSize the number of keys below which expired state is only removed every interval
+	memoryRateLimitMinCleanupSize = 1024
+)
+
+// MemoryRateLimitStore an in-memory RateLimitStore. only suitable for single instance deployments. expired state is
+// removed by Update every minute, or sooner when the number of keys doubles, so clients cannot grow the store without
+// bound by rotating their keys (e.g. their ip address)
+type MemoryRateLimitStore struct {
+	states map[string]*memoryRateLimitState
+	lock   *sync.Mutex
+	// nextCleanup and cleanupSize when Update removes expired state next
+	nextCleanup time.Time
+	cleanupSize int
+}
+
+type memoryRateLimitState struct {
+	state   RateLimitState
+	expires time.Time
+}
+
+func (m *MemoryRateLimitStore) Update(ctx context.Context, key string, fn func(state *RateLimitState) time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+
+	if now.After(m.nextCleanup) || len(m.states) >= m.cleanupSize {
+		m.cleanup(now)
+	}
+
+	s, ok := m.states[key]
+
+	if !ok || now.After(s.expires) {
+		s = &memoryRateLimitState{}
+		m.states[key] = s
+	}
+
+	s.expires = now.Add(fn(&s.state))
+
+	return nil
+}
+
+// Cleanup removes expired state
+func (m *MemoryRateLimitStore) Cleanup() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cleanup(time.Now())
+}
+
+func (m *MemoryRateLimitStore) cleanup(now time.Time) {
+	for k, s := range m.states {
+		if now.After(s.expires) {
+			delete(m.states, k)
+		}
+	}
+
+	m.nextCleanup = now.Add(memoryRateLimitCleanupInterval)
+	m.cleanupSize = max(2*len(m.states), memoryRateLimitMinCleanupSize)
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		states:      map[string]*memoryRateLimitState{},
+		lock:        &sync.Mutex{},
+		nextCleanup: time.Now().Add(memoryRateLimitCleanupInterval),
+		cleanupSize: memoryRateLimitMinCleanupSize,
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
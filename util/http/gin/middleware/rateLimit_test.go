@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+func Test_TokenBucket(t *testing.T) {
+	rate := Rate{Limit: 1, Period: time.Second, Burst: 3}
+	state := &RateLimitState{}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if res := TokenBucket(state, rate, now); !res.Allowed {
+			t.Fatalf("request %d within burst was not allowed", i)
+		}
+	}
+
+	res := TokenBucket(state, rate, now)
+
+	if res.Allowed || res.RetryAfter != time.Second {
+		t.Errorf("expected request over burst to be denied with retry after 1s, got %+v", res)
+	}
+
+	if res = TokenBucket(state, rate, now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected request after refill to be allowed, got %+v", res)
+	}
+}
+
+func Test_SlidingWindow(t *testing.T) {
+	rate := Rate{Limit: 4, Period: time.Minute}
+	state := &RateLimitState{}
+	start := time.Now().Truncate(time.Minute)
+
+	for i := 0; i < 4; i++ {
+		if res := SlidingWindow(state, rate, start.Add(50*time.Second)); !res.Allowed {
+			t.Fatalf("request %d within limit was not allowed", i)
+		}
+	}
+
+	res := SlidingWindow(state, rate, start.Add(50*time.Second))
+
+	if res.Allowed {
+		t.Fatalf("expected request over limit to be denied")
+	}
+
+	// the 4 requests in the previous window weigh 3 at 15s into the next window
+	if res.RetryAfter != 25*time.Second {
+		t.Errorf("RetryAfter = %v, expected 25s", res.RetryAfter)
+	}
+
+	if res = SlidingWindow(state, rate, start.Add(70*time.Second)); res.Allowed {
+		t.Errorf("expected request at 10s into the next window to be denied, got %+v", res)
+	}
+
+	if res = SlidingWindow(state, rate, start.Add(75*time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected request at 15s into the next window to be allowed, got %+v", res)
+	}
+}
+
+func Test_MemoryRateLimitStore_Eviction(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	update := func(key string, ttl time.Duration) {
+		_ = store.Update(ctx, key, func(*RateLimitState) time.Duration { return ttl })
+	}
+
+	// rotating keys faster than the cleanup interval only grows the store until the number of keys doubles
+	for i := 0; i < 10*memoryRateLimitMinCleanupSize; i++ {
+		update("ip:"+strconv.Itoa(i), -time.Second)
+	}
+
+	if n := len(store.states); n > memoryRateLimitMinCleanupSize {
+		t.Errorf("store holds %d keys, expected expired keys to be removed", n)
+	}
+
+	update("live", time.Minute)
+
+	// expired keys are removed once the interval passes, live keys are kept
+	store.nextCleanup = time.Now().Add(-time.Second)
+
+	update("ip:new", time.Minute)
+
+	if _, ok := store.states["live"]; !ok || len(store.states) != 2 {
+		t.Errorf("store holds %d keys after the cleanup interval, expected only the 2 live keys", len(store.states))
+	}
+}
+
+func Test_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimit(RateLimitConfig{Rate: Rate{Limit: 2, Period: time.Minute}}))
+
+	ctrl := controllers.NewController("rateLimit")
+	ctrl.AddAction(controllers.NewAction("default", func(c *gin.Context) { c.Status(http.StatusOK) }))
+	ctrl.AddAction(WithActionRateLimit(controllers.NewAction("strict", func(c *gin.Context) { c.Status(http.StatusOK) }), Rate{Limit: 1, Period: time.Minute}))
+	ctrl.AddAction(WithActionRateLimit(controllers.NewAction("unlimited", func(c *gin.Context) { c.Status(http.StatusOK) }), Rate{}))
+	ctrl.RegisterActions(r)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := do("/rateLimit/default"); w.Code != http.StatusOK {
+			t.Fatalf("request %d returned %d", i, w.Code)
+		}
+	}
+
+	w := do("/rateLimit/default")
+
+	if w.Code != http.StatusTooManyRequests || w.Header().Get(RetryAfterHeader) == "" {
+		t.Errorf("expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+
+	if w.Header().Get(RateLimitRemainingHeader) != "0" || w.Header().Get(RateLimitLimitHeader) != "2" {
+		t.Errorf("unexpected rate limit headers: %v", w.Header())
+	}
+
+	// actions with an override are limited separately
+	if w = do("/rateLimit/strict"); w.Code != http.StatusOK {
+		t.Errorf("first request to strict action returned %d", w.Code)
+	}
+
+	if w = do("/rateLimit/strict"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request to strict action returned %d", w.Code)
+	}
+
+	for i := 0; i < 5; i++ {
+		if w = do("/rateLimit/unlimited"); w.Code != http.StatusOK {
+			t.Errorf("request %d to unlimited action returned %d", i, w.Code)
+		}
+	}
+}
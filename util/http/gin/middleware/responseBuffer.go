@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// responseBuffer holds the response of the handlers that run after a middleware so it can be inspected and
+// changed before it is sent. headers are not buffered
+type responseBuffer struct {
+	gin.ResponseWriter
+	body   *bytes.Buffer
+	status int
+	// written set when the headers or the body are written. a status set by WriteHeader is only recorded, like
+	// gin's writer does, so handlers can still write the body
+	written bool
+}
+
+func newResponseBuffer(w gin.ResponseWriter) *responseBuffer {
+	return &responseBuffer{
+		ResponseWriter: w,
+		body:           &bytes.Buffer{},
+		status:         0,
+	}
+}
+
+func (b *responseBuffer) WriteHeader(code int) {
+	if code > 0 {
+		b.status = code
+	}
+}
+
+func (b *responseBuffer) WriteHeaderNow() {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	b.written = true
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	b.WriteHeaderNow()
+
+	return b.body.Write(data)
+}
+
+func (b *responseBuffer) WriteString(s string) (int, error) {
+	b.WriteHeaderNow()
+
+	return b.body.WriteString(s)
+}
+
+func (b *responseBuffer) Status() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+
+	return b.status
+}
+
+func (b *responseBuffer) Size() int {
+	if !b.written {
+		return -1
+	}
+
+	return b.body.Len()
+}
+
+func (b *responseBuffer) Written() bool {
+	return b.written
+}
+
+// Flush is a no-op. the response is sent by flush
+func (b *responseBuffer) Flush() {}
+
+// flush sends the buffered response with the given body
+func (b *responseBuffer) flush(body []byte) {
+	b.ResponseWriter.WriteHeader(b.Status())
+
+	if len(body) > 0 {
+		_, _ = b.ResponseWriter.Write(body)
+	} else {
+		b.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,127 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+const (
+	DefaultRoleClaim = "roles"
+)
+
+// ClaimsMapper converts the verified claims of an ID token into the claims used by this SDK
+type ClaimsMapper func(idClaims map[string]any) (*auth.UserClaims, error)
+
+// DefaultClaimsMapper maps the provider's `sub` claim to UserClaims.UserId and the values in roleClaim to roles
+//
+// roleClaim may be a dot separated path to a nested claim (ex: "realm_access.roles"). only provider roles in roleMap
+// are granted. when roleMap is nil, every user gets auth.RoleUser: provider role names are never trusted as SDK roles
+// without an explicit map. defaultRole is granted when no roles are mapped
+func DefaultClaimsMapper(roleClaim string, roleMap map[string]auth.Role, defaultRole auth.Role) ClaimsMapper {
+	if roleClaim == "" {
+		roleClaim = DefaultRoleClaim
+	}
+
+	return func(idClaims map[string]any) (*auth.UserClaims, error) {
+		sub, _ := idClaims["sub"].(string)
+
+		if sub == "" {
+			return nil, fmt.Errorf("id token is missing the sub claim")
+		}
+
+		var roles []auth.Role
+
+		if roleMap == nil {
+			roles = append(roles, auth.RoleUser)
+		}
+
+		for _, name := range claimStrings(lookupClaim(idClaims, roleClaim)) {
+			if r, ok := roleMap[name]; ok {
+				roles = append(roles, r)
+			}
+		}
+
+		if len(roles) == 0 && defaultRole != auth.RoleAnonymous {
+			roles = append(roles, defaultRole)
+		}
+
+		claims := &auth.UserClaims{
+			UserId: sub,
+			Roles:  RoleNames(roles...),
+			Issuer: auth.GetJwtIssuer(),
+		}
+
+		if exp, ok := idClaims["exp"].(float64); ok {
+			claims.Expires = int64(exp)
+		}
+
+		if iat, ok := idClaims["iat"].(float64); ok {
+			claims.Issued = int64(iat)
+		}
+
+		if amr := claimStrings(idClaims["amr"]); len(amr) > 0 {
+			claims.Amr = amr
+		}
+
+		return claims, nil
+	}
+}
+
+// RoleNames converts roles into the list of role names used in UserClaims.Roles. combined roles are expanded
+// into the names of the individual roles
+func RoleNames(roles ...auth.Role) []string {
+	names := make([]string, 0, len(roles))
+	seen := map[string]bool{}
+
+	for _, role := range roles {
+		for _, name := range role.Names() {
+			if seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func lookupClaim(claims map[string]any, path string) any {
+	var cur any = claims
+
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+
+		if !ok {
+			return nil
+		}
+
+		cur = m[part]
+	}
+
+	return cur
+}
+
+func claimStrings(v any) []string {
+	switch c := v.(type) {
+	case string:
+		return strings.Fields(c)
+	case []string:
+		return c
+	case []any:
+		s := make([]string, 0, len(c))
+
+		for _, i := range c {
+			if str, ok := i.(string); ok {
+				s = append(s, str)
+			}
+		}
+
+		return s
+	default:
+		return nil
+	}
+}
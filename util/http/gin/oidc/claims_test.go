@@ -0,0 +1,46 @@
+package oidc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+func Test_DefaultClaimsMapper(t *testing.T) {
+	idClaims := map[string]any{
+		"sub":          "user-1",
+		"realm_access": map[string]any{"roles": []any{"admin", "superAdmin", "idp-support"}},
+	}
+
+	tests := []struct {
+		name        string
+		roleMap     map[string]auth.Role
+		defaultRole auth.Role
+		expect      []string
+	}{
+		{name: "no role map", expect: []string{auth.RoleUser.String()}},
+		{name: "no role map with default", defaultRole: auth.RoleAdmin, expect: []string{auth.RoleUser.String()}},
+		{name: "mapped", roleMap: map[string]auth.Role{"idp-support": auth.RoleSupport}, expect: []string{auth.RoleSupport.String()}},
+		{name: "not mapped", roleMap: map[string]auth.Role{"other": auth.RoleAdmin}, defaultRole: auth.RoleUser, expect: []string{auth.RoleUser.String()}},
+		{name: "not mapped without default", roleMap: map[string]auth.Role{}, expect: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := DefaultClaimsMapper("realm_access.roles", tt.roleMap, tt.defaultRole)(idClaims)
+
+			if err != nil {
+				t.Fatalf("DefaultClaimsMapper() returned an error: %v", err)
+			}
+
+			if claims.UserId != "user-1" {
+				t.Errorf("UserId = %q, expected user-1", claims.UserId)
+			}
+
+			if !reflect.DeepEqual(claims.Roles, tt.expect) {
+				t.Errorf("Roles = %v, expected %v", claims.Roles, tt.expect)
+			}
+		})
+	}
+}
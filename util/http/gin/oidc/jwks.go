@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+var ErrUnsupportedJwk = errors.New("unsupported json web key")
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey public key fields of a JSON Web Key (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (k jsonWebKey) PublicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, nErr := decodeBigInt(k.N)
+
+		if nErr != nil {
+			return nil, nErr
+		}
+
+		e, eErr := decodeBigInt(k.E)
+
+		if eErr != nil {
+			return nil, eErr
+		}
+
+		if !e.IsInt64() {
+			return nil, ErrUnsupportedJwk
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, ErrUnsupportedJwk
+		}
+
+		x, xErr := decodeBigInt(k.X)
+
+		if xErr != nil {
+			return nil, xErr
+		}
+
+		y, yErr := decodeBigInt(k.Y)
+
+		if yErr != nil {
+			return nil, yErr
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, ErrUnsupportedJwk
+		}
+
+		x, xErr := base64.RawURLEncoding.DecodeString(k.X)
+
+		if xErr != nil {
+			return nil, xErr
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedJwk
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, ErrUnsupportedJwk
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, ErrUnsupportedJwk
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,183 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+
+	// minimum time between jwks refreshes triggered by an unknown key id
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+// ProviderMetadata is the subset of the OpenID Provider metadata (OpenID Connect Discovery 1.0) used by the
+// relying party
+type ProviderMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint,omitempty"`
+	JwksUri               string   `json:"jwks_uri"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// Provider is an OpenID provider that has been discovered from its issuer url
+type Provider struct {
+	Metadata   ProviderMetadata
+	httpClient *http.Client
+	keys       map[string]any
+	keysLock   *sync.RWMutex
+	keysTime   time.Time
+}
+
+// Discover loads the provider metadata from the issuer's discovery document
+func Discover(ctx context.Context, issuer string, httpClient ...*http.Client) (*Provider, error) {
+	client := http.DefaultClient
+
+	if len(httpClient) > 0 && httpClient[0] != nil {
+		client = httpClient[0]
+	}
+
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	req, rErr := http.NewRequestWithContext(ctx, http.MethodGet, issuer+DiscoveryPath, nil)
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	resp, respErr := client.Do(req)
+
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery failed with status: %s", resp.Status)
+	}
+
+	var md ProviderMetadata
+
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return nil, fmt.Errorf("invalid oidc discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(md.Issuer, "/") != issuer {
+		// OpenID Connect Discovery 1.0 section 4.3
+		return nil, fmt.Errorf("oidc issuer mismatch: expected %s, got %s", issuer, md.Issuer)
+	}
+
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JwksUri == "" {
+		return nil, fmt.Errorf("oidc discovery document is missing required endpoints")
+	}
+
+	return &Provider{
+		Metadata:   md,
+		httpClient: client,
+		keys:       map[string]any{},
+		keysLock:   &sync.RWMutex{},
+	}, nil
+}
+
+// Key returns the public key with the key id kid from the provider's key set. the key set is refreshed when kid
+// is not known to support key rotation by the provider
+func (p *Provider) Key(ctx context.Context, kid string) (any, error) {
+	key, ok, loaded := p.cachedKey(kid)
+
+	if ok {
+		return key, nil
+	}
+
+	if !loaded.IsZero() && time.Since(loaded) < jwksMinRefreshInterval {
+		return nil, fmt.Errorf("unknown oidc signing key: %s", kid)
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok, _ = p.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown oidc signing key: %s", kid)
+}
+
+// cachedKey returns the key with the key id kid from the cached key set and when the key set was loaded
+func (p *Provider) cachedKey(kid string) (any, bool, time.Time) {
+	p.keysLock.RLock()
+	defer p.keysLock.RUnlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, true, p.keysTime
+	}
+
+	if kid == "" && len(p.keys) == 1 {
+		// tokens without a key id are allowed when the provider only has one key
+		for _, k := range p.keys {
+			return k, true, p.keysTime
+		}
+	}
+
+	return nil, false, p.keysTime
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	req, rErr := http.NewRequestWithContext(ctx, http.MethodGet, p.Metadata.JwksUri, nil)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	resp, respErr := p.httpClient.Do(req)
+
+	if respErr != nil {
+		return respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc jwks request failed with status: %s", resp.Status)
+	}
+
+	var set jsonWebKeySet
+
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("invalid oidc jwks document: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, kErr := jwk.PublicKey()
+
+		if kErr != nil {
+			// skip unsupported keys
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	p.keysLock.Lock()
+	p.keys = keys
+	p.keysTime = time.Now()
+	p.keysLock.Unlock()
+
+	return nil
+}
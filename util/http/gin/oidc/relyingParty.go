@@ -0,0 +1,380 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+const (
+	DefaultScopes      = "openid profile email"
+	DefaultClockSkew   = 30 * time.Second
+	RedirectQueryParam = "redirect"
+	// StateCookieName the cookie that binds a pending login to the browser that started it
+	StateCookieName = "oidc_state"
+)
+
+var (
+	defaultSigningAlgs = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "EdDSA"}
+)
+
+// LoginFunc is called by the callback handler after the provider's ID token is verified. it is responsible for
+// establishing the user's login (ex: issuing a JWT or starting a session) and writing the response
+//
+// redirectTo is the local path that was requested when the login was started (empty if none was requested)
+type LoginFunc func(c *gin.Context, claims *auth.UserClaims, tokens *Tokens, redirectTo string)
+
+type Config struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+	// RoleClaim, RoleMap and DefaultRole configure the DefaultClaimsMapper and are ignored if ClaimsMapper is set
+	RoleClaim    string
+	RoleMap      map[string]auth.Role
+	DefaultRole  auth.Role
+	ClaimsMapper ClaimsMapper
+	StateStore   StateStore
+	StateTtl     time.Duration
+	HttpClient   *http.Client
+	OnLogin      LoginFunc
+}
+
+// Tokens the token endpoint response (RFC 6749 section 5.1)
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	IdToken      string `json:"id_token"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// RelyingParty performs the OpenID Connect authorization code flow with PKCE against a single provider
+type RelyingParty struct {
+	cfg      Config
+	provider *Provider
+}
+
+func NewRelyingParty(ctx context.Context, cfg Config) (*RelyingParty, error) {
+	if cfg.Issuer == "" || cfg.ClientId == "" || cfg.RedirectUrl == "" {
+		return nil, fmt.Errorf("oidc issuer, client id and redirect url are required")
+	}
+
+	if cfg.OnLogin == nil {
+		return nil, fmt.Errorf("oidc login function is required")
+	}
+
+	if cfg.HttpClient == nil {
+		cfg.HttpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = strings.Fields(DefaultScopes)
+	}
+
+	if cfg.StateStore == nil {
+		cfg.StateStore = NewMemoryStateStore()
+	}
+
+	if cfg.StateTtl <= 0 {
+		cfg.StateTtl = DefaultStateTtl
+	}
+
+	if cfg.ClaimsMapper == nil {
+		cfg.ClaimsMapper = DefaultClaimsMapper(cfg.RoleClaim, cfg.RoleMap, cfg.DefaultRole)
+	}
+
+	provider, pErr := Discover(ctx, cfg.Issuer, cfg.HttpClient)
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	return &RelyingParty{
+		cfg:      cfg,
+		provider: provider,
+	}, nil
+}
+
+func (rp *RelyingParty) Provider() *Provider {
+	return rp.provider
+}
+
+// RegisterRoutes registers the login and callback handlers with r
+func (rp *RelyingParty) RegisterRoutes(r gin.IRouter, loginPath string, callbackPath string) {
+	r.GET(loginPath, rp.LoginHandler())
+	r.GET(callbackPath, rp.CallbackHandler())
+}
+
+// LoginHandler redirects the user to the provider to log in. a local path to return to after logging in can be
+// provided with the RedirectQueryParam query parameter
+func (rp *RelyingParty) LoginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authUrl, err := rp.StartLogin(c, c.Query(RedirectQueryParam))
+
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Redirect(http.StatusFound, authUrl)
+	}
+}
+
+// StartLogin creates a new pending login, binds it to the browser with a short-lived state cookie and returns the
+// provider url that the user must be sent to. CallbackHandler only completes logins that were started in the same
+// browser, so a login started by someone else cannot be completed in the user's browser (login CSRF)
+func (rp *RelyingParty) StartLogin(c *gin.Context, redirectTo string) (string, error) {
+	authUrl, state, err := rp.authCodeUrl(c, redirectTo)
+
+	if err != nil {
+		return "", err
+	}
+
+	rp.setStateCookie(c, stateHash(state), int(rp.cfg.StateTtl.Seconds()))
+
+	return authUrl, nil
+}
+
+// AuthCodeUrl creates a new pending login and returns the provider url that the user must be sent to. the login is
+// not bound to the browser, so CallbackHandler will not complete it. use StartLogin with CallbackHandler
+func (rp *RelyingParty) AuthCodeUrl(ctx context.Context, redirectTo string) (string, error) {
+	authUrl, _, err := rp.authCodeUrl(ctx, redirectTo)
+
+	return authUrl, err
+}
+
+func (rp *RelyingParty) authCodeUrl(ctx context.Context, redirectTo string) (string, string, error) {
+	state, sErr := randomValue()
+
+	if sErr != nil {
+		return "", "", sErr
+	}
+
+	nonce, nErr := randomValue()
+
+	if nErr != nil {
+		return "", "", nErr
+	}
+
+	verifier, vErr := randomValue()
+
+	if vErr != nil {
+		return "", "", vErr
+	}
+
+	if !isLocalPath(redirectTo) {
+		// prevent open redirects
+		redirectTo = ""
+	}
+
+	ls := &LoginState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectTo:   redirectTo,
+		Expires:      time.Now().Add(rp.cfg.StateTtl),
+	}
+
+	if err := rp.cfg.StateStore.Save(ctx, state, ls); err != nil {
+		return "", "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", rp.cfg.ClientId)
+	params.Set("redirect_uri", rp.cfg.RedirectUrl)
+	params.Set("scope", strings.Join(rp.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+
+	if strings.Contains(rp.provider.Metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return rp.provider.Metadata.AuthorizationEndpoint + sep + params.Encode(), state, nil
+}
+
+// setStateCookie sets (or with maxAge < 0 removes) the state cookie. the cookie is only sent to the callback
+func (rp *RelyingParty) setStateCookie(c *gin.Context, value string, maxAge int) {
+	cookiePath := "/"
+	secure := false
+
+	if u, err := url.Parse(rp.cfg.RedirectUrl); err == nil {
+		secure = u.Scheme == "https"
+
+		if u.Path != "" {
+			cookiePath = u.Path
+		}
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(StateCookieName, value, maxAge, cookiePath, "", secure, true)
+}
+
+// CallbackHandler completes the login when the provider redirects the user back to the RedirectUrl
+func (rp *RelyingParty) CallbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if e := c.Query("error"); e != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "login failed: " + e})
+			return
+		}
+
+		state := c.Query("state")
+		code := c.Query("code")
+
+		if state == "" || code == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "missing state or code"})
+			return
+		}
+
+		bound, cErr := c.Cookie(StateCookieName)
+
+		// the state cookie is only needed once
+		rp.setStateCookie(c, "", -1)
+
+		if cErr != nil || subtle.ConstantTimeCompare([]byte(bound), []byte(stateHash(state))) != 1 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "login was not started in this browser"})
+			return
+		}
+
+		ls, lsErr := rp.cfg.StateStore.Consume(c, state)
+
+		if lsErr != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid or expired login state"})
+			return
+		}
+
+		tokens, tErr := rp.Exchange(c, code, ls.CodeVerifier)
+
+		if tErr != nil {
+			_ = c.Error(tErr)
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "token exchange failed"})
+			return
+		}
+
+		idClaims, vErr := rp.VerifyIdToken(c, tokens.IdToken, ls.Nonce)
+
+		if vErr != nil {
+			_ = c.Error(vErr)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid id token"})
+			return
+		}
+
+		claims, mErr := rp.cfg.ClaimsMapper(idClaims)
+
+		if mErr != nil {
+			_ = c.Error(mErr)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid id token claims"})
+			return
+		}
+
+		rp.cfg.OnLogin(c, claims, tokens, ls.RedirectTo)
+	}
+}
+
+// Exchange exchanges an authorization code for tokens at the provider's token endpoint
+func (rp *RelyingParty) Exchange(ctx context.Context, code string, codeVerifier string) (*Tokens, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", rp.cfg.RedirectUrl)
+	form.Set("client_id", rp.cfg.ClientId)
+	form.Set("code_verifier", codeVerifier)
+
+	req, rErr := http.NewRequestWithContext(ctx, http.MethodPost, rp.provider.Metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if rp.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(rp.cfg.ClientId), url.QueryEscape(rp.cfg.ClientSecret))
+	}
+
+	resp, respErr := rp.cfg.HttpClient.Do(req)
+
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token request failed with status: %s", resp.Status)
+	}
+
+	var tokens Tokens
+
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("invalid oidc token response: %w", err)
+	}
+
+	if tokens.IdToken == "" {
+		return nil, fmt.Errorf("oidc token response is missing the id_token")
+	}
+
+	return &tokens, nil
+}
+
+// VerifyIdToken verifies the signature, issuer, audience, expiry and nonce of an ID token and returns its claims
+func (rp *RelyingParty) VerifyIdToken(ctx context.Context, idToken string, nonce string) (map[string]any, error) {
+	algs := rp.provider.Metadata.SigningAlgs
+
+	if len(algs) == 0 {
+		algs = defaultSigningAlgs
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return rp.provider.Key(ctx, kid)
+	},
+		jwt.WithValidMethods(algs),
+		jwt.WithIssuer(rp.provider.Metadata.Issuer),
+		jwt.WithAudience(rp.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(DefaultClockSkew),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if n, _ := claims["nonce"].(string); n == "" || n != nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	}
+
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		// OpenID Connect Core 1.0 section 3.1.3.7
+		if azp, _ := claims["azp"].(string); azp != rp.cfg.ClientId {
+			return nil, fmt.Errorf("id token authorized party mismatch")
+		}
+	}
+
+	return claims, nil
+}
+
+func isLocalPath(p string) bool {
+	return strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "//") && !strings.HasPrefix(p, "/\\")
+}
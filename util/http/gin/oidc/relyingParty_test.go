@@ -0,0 +1,323 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+const (
+	testClientId    = "test-client"
+	testRedirectUrl = "http://localhost/callback"
+)
+
+type fakeAuthRequest struct {
+	nonce     string
+	challenge string
+}
+
+// fakeProvider an in-process OpenID provider
+type fakeProvider struct {
+	srv   *httptest.Server
+	key   *rsa.PrivateKey
+	codes map[string]fakeAuthRequest
+	lock  sync.Mutex
+	roles []string
+	// jwksRequests the number of times the key set was fetched
+	jwksRequests int
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, kErr := rsa.GenerateKey(rand.Reader, 2048)
+
+	if kErr != nil {
+		t.Fatalf("error generating rsa key: %v", kErr)
+	}
+
+	p := &fakeProvider{
+		key:   key,
+		codes: map[string]fakeAuthRequest{},
+		roles: []string{"idp-admins"},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ProviderMetadata{
+			Issuer:                p.srv.URL,
+			AuthorizationEndpoint: p.srv.URL + "/authorize",
+			TokenEndpoint:         p.srv.URL + "/token",
+			JwksUri:               p.srv.URL + "/jwks",
+			SigningAlgs:           []string{"RS256"},
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.lock.Lock()
+		p.jwksRequests++
+		p.lock.Unlock()
+
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+			Kty: "RSA",
+			Kid: "key-1",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		p.lock.Lock()
+		ar, ok := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		p.lock.Unlock()
+
+		if !ok || codeChallenge(r.PostForm.Get("code_verifier")) != ar.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(Tokens{
+			AccessToken: "access-token",
+			TokenType:   "Bearer",
+			IdToken:     p.idToken(t, ar.nonce),
+		})
+	})
+
+	p.srv = httptest.NewServer(mux)
+
+	return p
+}
+
+// authorize simulates the user logging in at the provider and returns the callback query
+func (p *fakeProvider) authorize(t *testing.T, authUrl string) url.Values {
+	u, uErr := url.Parse(authUrl)
+
+	if uErr != nil {
+		t.Fatalf("invalid authorization url: %v", uErr)
+	}
+
+	q := u.Query()
+
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != testClientId || q.Get("redirect_uri") != testRedirectUrl {
+		t.Fatalf("unexpected authorization request: %v", q)
+	}
+
+	p.lock.Lock()
+	p.codes["code-"+q.Get("state")] = fakeAuthRequest{nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	p.lock.Unlock()
+
+	return url.Values{"code": {"code-" + q.Get("state")}, "state": {q.Get("state")}}
+}
+
+func (p *fakeProvider) idToken(t *testing.T, nonce string) string {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.srv.URL,
+		"sub":   "user-123",
+		"aud":   testClientId,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": nonce,
+		"roles": p.roles,
+	})
+
+	token.Header["kid"] = "key-1"
+
+	signed, err := token.SignedString(p.key)
+
+	if err != nil {
+		t.Fatalf("error signing id token: %v", err)
+	}
+
+	return signed
+}
+
+func newTestRelyingParty(t *testing.T, p *fakeProvider, onLogin LoginFunc) *RelyingParty {
+	rp, err := NewRelyingParty(context.Background(), Config{
+		Issuer:      p.srv.URL,
+		ClientId:    testClientId,
+		RedirectUrl: testRedirectUrl,
+		RoleMap:     map[string]auth.Role{"idp-admins": auth.RoleAdmin},
+		DefaultRole: auth.RoleUser,
+		OnLogin:     onLogin,
+	})
+
+	if err != nil {
+		t.Fatalf("error creating relying party: %v", err)
+	}
+
+	return rp
+}
+
+func Test_RelyingParty_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p := newFakeProvider(t)
+	defer p.srv.Close()
+
+	var loginClaims *auth.UserClaims
+	var loginRedirect string
+
+	rp := newTestRelyingParty(t, p, func(c *gin.Context, claims *auth.UserClaims, tokens *Tokens, redirectTo string) {
+		loginClaims = claims
+		loginRedirect = redirectTo
+		c.Status(http.StatusNoContent)
+	})
+
+	r := gin.New()
+	rp.RegisterRoutes(r, "/login", "/callback")
+
+	// start the login
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login?redirect=/dashboard", nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("login returned %d, expected %d", w.Code, http.StatusFound)
+	}
+
+	callbackQuery := p.authorize(t, w.Header().Get("Location"))
+	cookies := w.Result().Cookies()
+
+	if len(cookies) != 1 || cookies[0].Name != StateCookieName || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("login did not set an HttpOnly, SameSite=Lax state cookie: %v", cookies)
+	}
+
+	callback := func(withCookie bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/callback?"+callbackQuery.Encode(), nil)
+
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// a login started in another browser cannot be completed (login CSRF)
+	if w = callback(false); w.Code != http.StatusBadRequest {
+		t.Fatalf("callback without the state cookie returned %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	// complete the login
+	w = callback(true)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("callback returned %d, expected %d: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	if loginClaims == nil || loginClaims.UserId != "user-123" {
+		t.Fatalf("unexpected login claims: %v", loginClaims)
+	}
+
+	if len(loginClaims.Roles) != 1 || loginClaims.Roles[0] != auth.RoleAdmin.String() {
+		t.Errorf("unexpected login roles: %v", loginClaims.Roles)
+	}
+
+	if loginRedirect != "/dashboard" {
+		t.Errorf("unexpected login redirect: %s", loginRedirect)
+	}
+
+	// the state can only be used once
+	if w = callback(true); w.Code != http.StatusBadRequest {
+		t.Errorf("replayed callback returned %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func Test_RelyingParty_OpenRedirect(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.srv.Close()
+
+	rp := newTestRelyingParty(t, p, func(c *gin.Context, claims *auth.UserClaims, tokens *Tokens, redirectTo string) {})
+
+	for _, redirect := range []string{"https://evil.example.com", "//evil.example.com", "/\\evil.example.com"} {
+		authUrl, err := rp.AuthCodeUrl(context.Background(), redirect)
+
+		if err != nil {
+			t.Fatalf("error creating auth code url: %v", err)
+		}
+
+		u, _ := url.Parse(authUrl)
+
+		ls, lsErr := rp.cfg.StateStore.Consume(context.Background(), u.Query().Get("state"))
+
+		if lsErr != nil {
+			t.Fatalf("login state not saved: %v", lsErr)
+		}
+
+		if ls.RedirectTo != "" {
+			t.Errorf("non-local redirect %s was accepted", redirect)
+		}
+	}
+}
+
+func Test_RelyingParty_VerifyIdToken(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.srv.Close()
+
+	rp := newTestRelyingParty(t, p, func(c *gin.Context, claims *auth.UserClaims, tokens *Tokens, redirectTo string) {})
+
+	if _, err := rp.VerifyIdToken(context.Background(), p.idToken(t, "nonce-1"), "nonce-1"); err != nil {
+		t.Errorf("valid id token rejected: %v", err)
+	}
+
+	if _, err := rp.VerifyIdToken(context.Background(), p.idToken(t, "nonce-1"), "nonce-2"); err == nil {
+		t.Errorf("id token with wrong nonce accepted")
+	}
+
+	// token signed by a different key
+	other := &fakeProvider{srv: p.srv}
+	other.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+	if _, err := rp.VerifyIdToken(context.Background(), other.idToken(t, "nonce-1"), "nonce-1"); err == nil {
+		t.Errorf("id token with invalid signature accepted")
+	}
+}
+
+func Test_Provider_Key(t *testing.T) {
+	p := newFakeProvider(t)
+	defer p.srv.Close()
+
+	rp := newTestRelyingParty(t, p, func(c *gin.Context, claims *auth.UserClaims, tokens *Tokens, redirectTo string) {})
+
+	ctx := context.Background()
+
+	if _, err := rp.provider.Key(ctx, "key-1"); err != nil {
+		t.Fatalf("Key() returned an error: %v", err)
+	}
+
+	// tokens without a key id use the only cached key, even right after the key set was loaded
+	if _, err := rp.provider.Key(ctx, ""); err != nil {
+		t.Errorf("Key() without a key id returned an error: %v", err)
+	}
+
+	if _, err := rp.provider.Key(ctx, "key-2"); err == nil {
+		t.Errorf("Key() of an unknown key id should return an error")
+	}
+
+	if p.jwksRequests != 1 {
+		t.Errorf("the key set was fetched %d times, expected 1", p.jwksRequests)
+	}
+}
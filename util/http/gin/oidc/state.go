@@ -0,0 +1,111 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+const (
+	DefaultStateTtl = 10 * time.Minute
+
+	// 32 random bytes produce a 43 character PKCE code verifier (RFC 7636 section 4.1)
+	randomValueSize = 32
+)
+
+// LoginState is the data that is kept between redirecting a user to the provider and the provider redirecting
+// the user back to the callback
+type LoginState struct {
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	RedirectTo   string    `json:"redirectTo,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+// StateStore stores pending logins keyed by their state parameter
+//
+// Consume MUST remove the state so that it can only be used once and MUST return errors.ErrNotFound for unknown
+// or expired states
+type StateStore interface {
+	Save(ctx context.Context, state string, ls *LoginState) error
+	Consume(ctx context.Context, state string) (*LoginState, error)
+}
+
+// MemoryStateStore an in-memory StateStore. only suitable for single instance deployments
+type MemoryStateStore struct {
+	states map[string]*LoginState
+	lock   *sync.Mutex
+}
+
+func (s *MemoryStateStore) Save(ctx context.Context, state string, ls *LoginState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// clean up abandoned logins
+	now := time.Now()
+
+	for k, v := range s.states {
+		if now.After(v.Expires) {
+			delete(s.states, k)
+		}
+	}
+
+	s.states[state] = ls
+
+	return nil
+}
+
+func (s *MemoryStateStore) Consume(ctx context.Context, state string) (*LoginState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ls, ok := s.states[state]
+
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	delete(s.states, state)
+
+	if time.Now().After(ls.Expires) {
+		return nil, errors.ErrNotFound
+	}
+
+	return ls, nil
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		states: map[string]*LoginState{},
+		lock:   &sync.Mutex{},
+	}
+}
+
+func randomValue() (string, error) {
+	b := make([]byte, randomValueSize)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// stateHash the value of the state cookie of a pending login
+func stateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// codeChallenge creates the S256 PKCE code challenge for verifier (RFC 7636 section 4.2)
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,316 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+	"github.com/smoxy-io/goSDK/util/http/gin/middleware"
+)
+
+const (
+	SecurityApiKey = "apiKey"
+	SecurityBearer = "bearerAuth"
+
+	errorSchemaName = "Error"
+	jsonContentType = "application/json"
+)
+
+var (
+	// methods documented for each action, in document order
+	methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions}
+
+	bodyMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+)
+
+type Config struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []Server
+	// SecuritySchemes defaults to an api key (middleware.AuthHeader) and a bearer jwt. actions that are not
+	// anonymous accept any of the schemes
+	SecuritySchemes map[string]SecurityScheme
+}
+
+func (c Config) withDefaults() Config {
+	if c.Title == "" {
+		c.Title = "API"
+	}
+
+	if c.Version == "" {
+		c.Version = "1.0.0"
+	}
+
+	if c.SecuritySchemes == nil {
+		c.SecuritySchemes = map[string]SecurityScheme{
+			SecurityApiKey: {Type: "apiKey", Name: middleware.AuthHeader, In: "header"},
+			SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+
+	return c
+}
+
+// Generate builds a document from all routes registered with controllers. MUST be called after the controllers'
+// actions are registered with the router
+func Generate(cfg Config) *Document {
+	return Build(cfg, controllers.GetAllRoutes())
+}
+
+// Build builds a document from routes keyed by their full gin path
+func Build(cfg Config, routes map[string]*controllers.Route) *Document {
+	cfg = cfg.withDefaults()
+	g := newSchemaGenerator()
+
+	// registered first so a user type with the same name does not replace it
+	g.schemas[errorSchemaName] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error":  {Type: "string"},
+			"fields": {Type: "array", Items: g.schema(reflect.TypeFor[controllers.FieldError]())},
+		},
+		Required: []string{"error"},
+	}
+
+	doc := &Document{
+		OpenApi: Version,
+		Info: Info{
+			Title:       cfg.Title,
+			Version:     cfg.Version,
+			Description: cfg.Description,
+		},
+		Servers: cfg.Servers,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			SecuritySchemes: cfg.SecuritySchemes,
+		},
+	}
+
+	schemeNames := make([]string, 0, len(cfg.SecuritySchemes))
+
+	for name := range cfg.SecuritySchemes {
+		schemeNames = append(schemeNames, name)
+	}
+
+	slices.Sort(schemeNames)
+
+	paths := make([]string, 0, len(routes))
+
+	for p := range routes {
+		paths = append(paths, p)
+	}
+
+	slices.Sort(paths)
+
+	tags := map[string]bool{}
+
+	for _, p := range paths {
+		route := routes[p]
+		specPath, pathParams := convertPath(p)
+		item := PathItem{}
+
+		for _, method := range methods {
+			if !route.Action.HasVerb(method) {
+				continue
+			}
+
+			op := buildOperation(g, route, method, pathParams, schemeNames)
+			item[strings.ToLower(method)] = op
+
+			for _, t := range op.Tags {
+				tags[t] = true
+			}
+		}
+
+		if len(item) > 0 {
+			doc.Paths[specPath] = item
+		}
+	}
+
+	for t := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: t})
+	}
+
+	slices.SortFunc(doc.Tags, func(a, b Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	doc.Components.Schemas = g.schemas
+
+	return doc
+}
+
+func buildOperation(g *schemaGenerator, route *controllers.Route, method string, pathParams []string, schemeNames []string) *Operation {
+	action := route.Action
+
+	op := &Operation{
+		OperationId: route.Controller.Name() + "." + action.Name(),
+		Tags:        []string{route.Controller.Name()},
+		Responses:   map[string]Response{},
+	}
+
+	if countVerbs(action) > 1 {
+		op.OperationId += "." + strings.ToLower(method)
+	}
+
+	if v, ok := action.Setting(controllers.ActionSettingSummary); ok {
+		op.Summary, _ = v.(string)
+	}
+
+	if v, ok := action.Setting(controllers.ActionSettingDescription); ok {
+		op.Description, _ = v.(string)
+	}
+
+	reqType := settingType(action, controllers.ActionSettingRequestType)
+
+	// path parameters from the route, described by the request type's uri tags when available
+	var uriParams []Parameter
+
+	if reqType != nil {
+		uriParams = g.parameters(reqType, "uri", "path")
+	}
+
+	for _, name := range pathParams {
+		i := slices.IndexFunc(uriParams, func(p Parameter) bool { return p.Name == name })
+
+		if i != -1 {
+			op.Parameters = append(op.Parameters, uriParams[i])
+			continue
+		}
+
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	if reqType != nil {
+		if slices.Contains(bodyMethods, method) {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonContentType: {Schema: g.schema(reqType)}},
+			}
+		} else {
+			op.Parameters = append(op.Parameters, g.parameters(reqType, "form", "query")...)
+		}
+
+		op.Responses["400"] = errorResponse("Invalid request")
+	}
+
+	if respType := settingType(action, controllers.ActionSettingResponseType); respType != nil {
+		op.Responses["200"] = Response{
+			Description: "OK",
+			Content:     map[string]MediaType{jsonContentType: {Schema: g.schema(respType)}},
+		}
+	} else {
+		op.Responses["200"] = Response{Description: "OK"}
+	}
+
+	roles := auth.NewRole(action.AuthorizedRoles()...)
+
+	if slices.Contains(action.AuthorizedRoles(), auth.RoleAnonymous) {
+		// an empty list overrides the document's security and marks the operation as public
+		op.Security = []SecurityRequirement{}
+	} else {
+		op.Roles = roles.Names()
+		op.Security = make([]SecurityRequirement, 0, len(schemeNames))
+
+		for _, name := range schemeNames {
+			op.Security = append(op.Security, SecurityRequirement{name: {}})
+		}
+
+		op.Responses["403"] = errorResponse("Forbidden. requires one of the roles: " + strings.Join(op.Roles, ", "))
+	}
+
+	if action.MfaRequired() {
+		op.Description = strings.TrimSpace(op.Description + "\n\nRequires multi-factor authentication.")
+
+		if _, ok := op.Responses["403"]; !ok {
+			op.Responses["403"] = errorResponse("Forbidden. multi-factor authentication required")
+		}
+	}
+
+	return op
+}
+
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{jsonContentType: {Schema: &Schema{Ref: "#/components/schemas/" + errorSchemaName}}},
+	}
+}
+
+func settingType(action controllers.IAction, key string) reflect.Type {
+	v, ok := action.Setting(key)
+
+	if !ok {
+		return nil
+	}
+
+	switch t := v.(type) {
+	case reflect.Type:
+		return t
+	case nil:
+		return nil
+	default:
+		return reflect.TypeOf(v)
+	}
+}
+
+func countVerbs(action controllers.IAction) int {
+	n := 0
+
+	for _, m := range methods {
+		if action.HasVerb(m) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// convertPath converts gin path parameters (:id, *path) to OpenAPI path templates ({id}, {path})
+func convertPath(p string) (string, []string) {
+	segments := strings.Split(p, "/")
+	params := make([]string, 0)
+
+	for i, s := range segments {
+		if len(s) > 1 && (s[0] == ':' || s[0] == '*') {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// WithRequestType documents the type of the action's request. v is a value of the type or its reflect.Type
+func WithRequestType(action controllers.IAction, v any) controllers.IAction {
+	return action.WithSetting(controllers.ActionSettingRequestType, typeOf(v))
+}
+
+// WithResponseType documents the type of the action's successful response. v is a value of the type or its
+// reflect.Type
+func WithResponseType(action controllers.IAction, v any) controllers.IAction {
+	return action.WithSetting(controllers.ActionSettingResponseType, typeOf(v))
+}
+
+// WithSummary documents the action
+func WithSummary(action controllers.IAction, summary string, description ...string) controllers.IAction {
+	action.WithSetting(controllers.ActionSettingSummary, summary)
+
+	if len(description) > 0 {
+		action.WithSetting(controllers.ActionSettingDescription, strings.Join(description, "\n\n"))
+	}
+
+	return action
+}
+
+func typeOf(v any) reflect.Type {
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+
+	return reflect.TypeOf(v)
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
+)
+
+type testAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type testUser struct {
+	Id       string         `uri:"id" json:"id"`
+	Name     string         `json:"name" binding:"required" description:"display name"`
+	Email    string         `json:"email,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+	Created  time.Time      `json:"created"`
+	Address  *testAddress   `json:"address,omitempty"`
+	Friends  []*testUser    `json:"friends,omitempty"`
+	Meta     map[string]int `json:"meta,omitempty"`
+	Password string         `json:"-"`
+	internal string
+}
+
+type testListQuery struct {
+	Limit  int    `form:"limit" description:"page size"`
+	Cursor string `form:"cursor"`
+}
+
+func testRoutes(t *testing.T) map[string]*controllers.Route {
+	gin.SetMode(gin.TestMode)
+
+	noop := func(c *gin.Context) {}
+
+	ctrl := controllers.NewController("users")
+
+	ctrl.AddAction(WithResponseType(WithRequestType(controllers.NewAction("list", noop), testListQuery{}), []testUser{}))
+	ctrl.AddAction(WithSummary(WithResponseType(WithRequestType(
+		controllers.NewAction("update", noop, http.MethodPost).WithVanityPath("/users/:id").WithAuthRoles(auth.RoleAdmin, auth.RoleSupport),
+		&testUser{}), testUser{}), "update a user"))
+	ctrl.AddAction(controllers.NewAction("mfa", noop, http.MethodGet, http.MethodDelete).WithAuthRoles(auth.RoleUser).WithMfaRequired())
+
+	r := gin.New()
+	ctrl.RegisterActions(r.Group("/api"))
+
+	routes := map[string]*controllers.Route{}
+
+	for p, route := range controllers.GetAllRoutes() {
+		if strings.HasPrefix(p, "/api/users") {
+			routes[p] = route
+		}
+	}
+
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+
+	return routes
+}
+
+func Test_Build(t *testing.T) {
+	doc := Build(Config{Title: "test"}, testRoutes(t))
+
+	list := doc.Paths["/api/users/list"]["get"]
+
+	if list == nil {
+		t.Fatalf("missing GET /api/users/list, paths: %v", doc.Paths)
+	}
+
+	if len(list.Security) != 0 || list.Security == nil {
+		t.Errorf("anonymous action should have an empty security list, got %v", list.Security)
+	}
+
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "limit" || list.Parameters[0].In != "query" {
+		t.Errorf("unexpected list parameters: %+v", list.Parameters)
+	}
+
+	if s := list.Responses["200"].Content[jsonContentType].Schema; s.Type != "array" || s.Items.Ref != "#/components/schemas/testUser" {
+		t.Errorf("unexpected list response schema: %+v", s)
+	}
+
+	update := doc.Paths["/api/users/{id}"]["post"]
+
+	if update == nil {
+		t.Fatalf("missing POST /api/users/{id}, paths: %v", doc.Paths)
+	}
+
+	if update.Summary != "update a user" || update.OperationId != "users.update" {
+		t.Errorf("unexpected update operation: %+v", update)
+	}
+
+	if len(update.Parameters) != 1 || update.Parameters[0].In != "path" || !update.Parameters[0].Required {
+		t.Errorf("unexpected update parameters: %+v", update.Parameters)
+	}
+
+	if update.RequestBody == nil {
+		t.Errorf("update should have a request body")
+	}
+
+	if strings.Join(update.Roles, ",") != "support,admin" || len(update.Security) != 2 {
+		t.Errorf("unexpected update security: %v %v", update.Roles, update.Security)
+	}
+
+	if _, ok := update.Responses["403"]; !ok {
+		t.Errorf("update should document a 403 response")
+	}
+
+	mfa := doc.Paths["/api/users/mfa"]
+
+	if mfa["get"] == nil || mfa["delete"] == nil || mfa["get"].OperationId != "users.mfa.get" {
+		t.Errorf("unexpected mfa operations: %+v", mfa)
+	}
+
+	if !strings.Contains(mfa["get"].Description, "multi-factor") {
+		t.Errorf("mfa operation should mention multi-factor authentication")
+	}
+
+	user := doc.Components.Schemas["testUser"]
+
+	if user == nil {
+		t.Fatalf("missing testUser schema")
+	}
+
+	if strings.Join(user.Required, ",") != "name" {
+		t.Errorf("testUser required = %v, expected [name]", user.Required)
+	}
+
+	for _, name := range []string{"Password", "internal", "password"} {
+		if _, ok := user.Properties[name]; ok {
+			t.Errorf("testUser schema should not have property %s", name)
+		}
+	}
+
+	if user.Properties["created"].Format != "date-time" || user.Properties["name"].Description != "display name" {
+		t.Errorf("unexpected testUser properties: %+v", user.Properties)
+	}
+
+	if user.Properties["friends"].Items.Ref != "#/components/schemas/testUser" {
+		t.Errorf("recursive reference not resolved: %+v", user.Properties["friends"])
+	}
+
+	if doc.Components.Schemas["testAddress"] == nil {
+		t.Errorf("missing testAddress schema")
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("error marshalling document: %v", err)
+	}
+}
+
+func Test_RegisterRoutes(t *testing.T) {
+	testRoutes(t)
+
+	r := gin.New()
+	RegisterRoutes(r, "/docs", Config{Title: "test api"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+
+	var doc Document
+
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc.OpenApi != Version || doc.Info.Title != "test api" {
+		t.Errorf("unexpected spec response %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "openapi.json") {
+		t.Errorf("unexpected docs response %d", w.Code)
+	}
+}
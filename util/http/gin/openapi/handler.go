@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const SpecFile = "openapi.json"
+
+var (
+	//go:embed docs.html
+	docsHtml string
+
+	docsTmpl = template.Must(template.New("docs").Parse(docsHtml))
+)
+
+// SpecHandler serves the document generated from the registered routes as json. the document is generated on the
+// first request, after all routes have been registered
+func SpecHandler(cfg Config) gin.HandlerFunc {
+	var doc *Document
+	once := &sync.Once{}
+
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = Generate(cfg)
+		})
+
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// DocsHandler serves a self-contained html page that renders the document served at specUrl
+func DocsHandler(title string, specUrl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+
+		if err := docsTmpl.Execute(c.Writer, struct {
+			Title   string
+			SpecUrl string
+		}{Title: title, SpecUrl: specUrl}); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// RegisterRoutes serves the docs ui at route and the document at route + "/" + SpecFile
+func RegisterRoutes(r gin.IRouter, route string, cfg Config) {
+	route = "/" + strings.Trim(route, "/")
+	specRoute := strings.TrimSuffix(route, "/") + "/" + SpecFile
+
+	r.GET(specRoute, SpecHandler(cfg))
+	r.GET(route, DocsHandler(cfg.withDefaults().Title, specRoute))
+}
@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// schemaGenerator derives schemas from go types. named struct types are added to the components and referenced
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	if t.Kind() != reflect.Struct && t.Implements(jsonMarshalerType) {
+		// custom json encoding, the shape is unknown
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	// interfaces, funcs, chans...
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.objectSchema(t)
+	}
+
+	name, ok := g.names[t]
+
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+
+		// register the name before generating the properties so recursive types reference themselves
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.objectSchema(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+
+	// generic type names contain the type parameters, e.g. Page[github.com/foo/bar.User]
+	if i := strings.Index(name, "["); i != -1 {
+		name = name[:i]
+	}
+
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+
+	// prefix the package name to avoid collisions between types with the same name
+	pkg := t.PkgPath()
+
+	if i := strings.LastIndex(pkg, "/"); i != -1 {
+		pkg = pkg[i+1:]
+	}
+
+	candidate := pkg + "." + name
+
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[candidate]; !taken {
+			return candidate
+		}
+
+		candidate = pkg + "." + name + strconv.Itoa(i)
+	}
+}
+
+func (g *schemaGenerator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	g.addFields(s, t)
+
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous {
+			ft := f.Type
+
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+				// embedded struct fields are promoted
+				g.addFields(s, ft)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, skip := jsonName(f)
+
+		if skip {
+			continue
+		}
+
+		fs := g.schema(f.Type)
+
+		if desc := f.Tag.Get("description"); desc != "" {
+			if fs.Ref != "" {
+				// siblings of $ref are allowed in 3.1
+				fs = &Schema{Ref: fs.Ref, Description: desc}
+			} else {
+				fs.Description = desc
+			}
+		}
+
+		s.Properties[name] = fs
+
+		if isRequired(f) && !slices.Contains(s.Required, name) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func jsonName(f reflect.StructField) (name string, skip bool) {
+	tag := f.Tag.Get("json")
+
+	if tag == "-" {
+		return "", true
+	}
+
+	name = strings.Split(tag, ",")[0]
+
+	if name == "" {
+		name = f.Name
+	}
+
+	return name, false
+}
+
+// isRequired fields are required when they are validated as required. omitting a field is always valid otherwise
+func isRequired(f reflect.StructField) bool {
+	for _, tag := range []string{"binding", "validate"} {
+		if slices.Contains(strings.Split(f.Tag.Get(tag), ","), "required") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parameters returns the parameters bound from tag (form for the query string, uri for the path) of t's fields
+func (g *schemaGenerator) parameters(t reflect.Type, tag string, in string) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	params := make([]Parameter, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		params = append(params, Parameter{
+			Name:        name,
+			In:          in,
+			Description: f.Tag.Get("description"),
+			Required:    in == "path" || isRequired(f),
+			Schema:      g.schema(f.Type),
+		})
+	}
+
+	return params
+}
@@ -2,49 +2,97 @@ package gin
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/crypto/certs"
 	"github.com/smoxy-io/goSDK/util/http/gin/controllers"
 	"github.com/smoxy-io/goSDK/util/http/gin/middleware"
+	"github.com/smoxy-io/goSDK/util/http/gin/openapi"
+	"github.com/smoxy-io/goSDK/util/metrics"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type Server struct {
-	srv              *gin.Engine
-	httpSrv          *http.Server
-	telemId          string
-	backgroundWg     *sync.WaitGroup
-	healthCheckRoute string
-	connLimit        int
-	middleware       map[string][]gin.HandlerFunc
-	recoveryHandler  middleware.RecoveryHandlerFunc
-	noTraceEndpoints []string
-}
-
-var (
-	server *Server
+const (
+	DefaultShutdownTimeout = 20 * time.Second
 )
 
-// NewServer create a new HTTP server that uses the controller/action pattern
-func NewServer(telemId string) *Server {
-	if server != nil {
-		return server
-	}
+type Server struct {
+	srv                *gin.Engine
+	httpSrv            *http.Server
+	telemId            string
+	backgroundWg       *sync.WaitGroup
+	healthCheckRoute   string
+	livenessRoute      string
+	readinessRoute     string
+	livenessChecks     map[string]HealthChecker
+	readinessChecks    map[string]HealthChecker
+	healthCheckTimeout time.Duration
+	draining           *atomic.Bool
+	drainTimeout       time.Duration
+	shutdownTimeout    time.Duration
+	connLimit          int
+	middleware         map[string][]gin.HandlerFunc
+	groups             []string
+	recoveryHandler    middleware.RecoveryHandlerFunc
+	noTraceEndpoints   []string
+	metricsRoute       string
+	metrics            *metrics.Registry
+	openApiRoute       string
+	openApiConfig      openapi.Config
+	clientCAFile       string
+	clientAuth         tls.ClientAuthType
+	clientCertMapper   middleware.ClientCertMapper
+	certReloadInterval time.Duration
+	stopCertReload     context.CancelFunc
+	maxBodySize        int64
+	compression        *middleware.CompressionConfig
+	decompress         bool
+}
 
-	server = &Server{
-		srv:              nil,
-		httpSrv:          nil,
-		telemId:          strings.TrimSpace(telemId),
-		backgroundWg:     nil,
-		healthCheckRoute: "/health",
-		connLimit:        0,
-		middleware:       make(map[string][]gin.HandlerFunc),
-		recoveryHandler:  middleware.DefaultRecoveryHandler("json", nil, 500),
-		noTraceEndpoints: make([]string, 0),
+// NewServer create a new HTTP server that uses the controller/action pattern. each call returns a new, independent
+// server so one process can serve e.g. a public and an internal api on different addresses (see
+// WithControllerGroups)
+func NewServer(telemId string) *Server {
+	server := &Server{
+		srv:                nil,
+		httpSrv:            nil,
+		telemId:            strings.TrimSpace(telemId),
+		backgroundWg:       nil,
+		healthCheckRoute:   "/health",
+		livenessRoute:      "/health/live",
+		readinessRoute:     "/health/ready",
+		livenessChecks:     make(map[string]HealthChecker),
+		readinessChecks:    make(map[string]HealthChecker),
+		healthCheckTimeout: DefaultHealthCheckTimeout,
+		draining:           &atomic.Bool{},
+		drainTimeout:       0,
+		shutdownTimeout:    DefaultShutdownTimeout,
+		connLimit:          0,
+		middleware:         make(map[string][]gin.HandlerFunc),
+		groups:             nil,
+		recoveryHandler:    middleware.DefaultRecoveryHandler("json", nil, 500),
+		noTraceEndpoints:   make([]string, 0),
+		metricsRoute:       "",
+		metrics:            nil,
+		openApiRoute:       "",
+		openApiConfig:      openapi.Config{},
+		clientCAFile:       "",
+		clientAuth:         tls.NoClientCert,
+		clientCertMapper:   nil,
+		certReloadInterval: certs.DefaultReloadInterval,
+		stopCertReload:     nil,
+		maxBodySize:        0,
+		compression:        nil,
+		decompress:         false,
 	}
 
 	server.middleware["main"] = make([]gin.HandlerFunc, 0)
@@ -84,29 +132,247 @@ func (s *Server) WithNoTraceEndpoints(endpoints ...string) *Server {
 	return s
 }
 
+// WithMetricsRoute enables request, connection and background task metrics and serves them in the Prometheus
+// text format at route. metrics are recorded in metrics.DefaultRegistry unless a registry is provided
+func (s *Server) WithMetricsRoute(route string, reg ...*metrics.Registry) *Server {
+	s.metricsRoute = route
+	s.metrics = metrics.DefaultRegistry
+
+	if len(reg) > 0 && reg[0] != nil {
+		s.metrics = reg[0]
+	}
+
+	return s
+}
+
+// WithOpenApiRoute serves an OpenAPI document generated from the registered controllers at route + "/openapi.json"
+// and a docs ui for it at route
+func (s *Server) WithOpenApiRoute(route string, cfg openapi.Config) *Server {
+	s.openApiRoute = route
+	s.openApiConfig = cfg
+	return s
+}
+
+// WithLivenessRoute sets the route of the liveness probe. the probe fails when a liveness check fails, which
+// should only happen when the process needs to be restarted
+func (s *Server) WithLivenessRoute(route string) *Server {
+	s.livenessRoute = route
+	return s
+}
+
+// WithReadinessRoute sets the route of the readiness probe. the probe fails while the server is draining or when
+// a readiness check fails
+func (s *Server) WithReadinessRoute(route string) *Server {
+	s.readinessRoute = route
+	return s
+}
+
+// WithLivenessCheck adds a check to the liveness probe and the health check route
+func (s *Server) WithLivenessCheck(name string, checker HealthChecker) *Server {
+	s.livenessChecks[name] = checker
+	return s
+}
+
+// WithReadinessCheck adds a check to the readiness probe, e.g. WithReadinessCheck("dgraph", HealthCheckFunc(dgraph.Ping))
+func (s *Server) WithReadinessCheck(name string, checker HealthChecker) *Server {
+	s.readinessChecks[name] = checker
+	return s
+}
+
+// WithHealthCheckTimeout sets the time after which a health check is cancelled and fails
+func (s *Server) WithHealthCheckTimeout(timeout time.Duration) *Server {
+	s.healthCheckTimeout = timeout
+	return s
+}
+
+// WithDrainTimeout sets how long Stop keeps serving requests with a failing readiness probe before shutting down,
+// so load balancers stop sending new requests first
+func (s *Server) WithDrainTimeout(timeout time.Duration) *Server {
+	s.drainTimeout = timeout
+	return s
+}
+
+// WithShutdownTimeout sets how long Stop waits for in-flight requests and background tasks after draining
+func (s *Server) WithShutdownTimeout(timeout time.Duration) *Server {
+	s.shutdownTimeout = timeout
+	return s
+}
+
+// WithControllerGroups only registers the controllers of groups (see controllers.Register). all groups are
+// registered by default
+func (s *Server) WithControllerGroups(groups ...string) *Server {
+	s.groups = append(s.groups, groups...)
+	return s
+}
+
+// WithClientCerts verifies client certificates against the CAs in caFile when serving TLS and authenticates
+// requests with the certificate's claims (see middleware.ClientCertAuth). mapper defaults to
+// middleware.DefaultClientCertMapper(nil). when required is false, requests without a client certificate continue
+// unauthenticated
+func (s *Server) WithClientCerts(caFile string, required bool, mapper middleware.ClientCertMapper) *Server {
+	s.clientCAFile = caFile
+	s.clientCertMapper = mapper
+	s.clientAuth = tls.VerifyClientCertIfGiven
+
+	if required {
+		s.clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return s
+}
+
+// WithCertReloadInterval sets how often the certificate files are checked for changes when serving TLS
+func (s *Server) WithCertReloadInterval(interval time.Duration) *Server {
+	s.certReloadInterval = interval
+	return s
+}
+
+// WithMaxBodySize limits request bodies to size bytes. actions can override the limit with
+// middleware.WithActionMaxBodySize. request bodies are not limited by default
+func (s *Server) WithMaxBodySize(size int64) *Server {
+	s.maxBodySize = size
+	return s
+}
+
+// WithCompression compresses responses with gzip or zstd when the client accepts it
+func (s *Server) WithCompression(cfg middleware.CompressionConfig) *Server {
+	s.compression = &cfg
+	return s
+}
+
+// WithRequestDecompression accepts gzip and zstd compressed request bodies
+func (s *Server) WithRequestDecompression() *Server {
+	s.decompress = true
+	return s
+}
+
+// Drain marks the server as draining so the readiness probe fails while requests are still served. Stop drains
+// the server automatically
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *Server) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// Handler returns the http handler of the server, building it if necessary. useful for testing the server without
+// listening on an address
+func (s *Server) Handler() http.Handler {
+	if s.srv == nil {
+		s.build()
+	}
+
+	return s.srv
+}
+
 // ListenAndServe non-blocking ListenAndServe function
 func (s *Server) ListenAndServe(address string) error {
-	if s.srv != nil {
+	return s.serve(address, nil)
+}
+
+// ListenAndServeTLS non-blocking ListenAndServeTLS function. the certificate, key and client CA files (see
+// WithClientCerts) are reloaded when they change or the process receives a SIGHUP
+func (s *Server) ListenAndServeTLS(address string, certFile string, keyFile string) error {
+	if s.httpSrv != nil {
 		return nil
 	}
 
+	reloader, rErr := certs.NewReloader(certFile, keyFile, s.clientCAFile)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	return s.serve(address, reloader)
+}
+
+func (s *Server) serve(address string, reloader *certs.Reloader) error {
+	if s.httpSrv != nil {
+		return nil
+	}
+
+	if s.srv == nil {
+		s.build()
+	}
+
+	// setup the http server
+	s.httpSrv = &http.Server{
+		Addr:    address,
+		Handler: s.srv,
+	}
+
+	if reloader != nil {
+		s.httpSrv.TLSConfig = reloader.TLSConfig(s.clientAuth)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		s.stopCertReload = cancel
+		reloader.Watch(ctx, s.certReloadInterval)
+	}
+
+	httpSrv := s.httpSrv
+
+	go func() {
+		var err error
+
+		// blocks until server is stopped
+		if reloader != nil {
+			err = httpSrv.ListenAndServeTLS("", "")
+		} else {
+			err = httpSrv.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println(err)
+		}
+	}()
+
+	fmt.Printf("listening on %s\n", address)
+
+	return nil
+}
+
+func (s *Server) build() {
 	s.backgroundWg = &sync.WaitGroup{}
+	s.draining.Store(false)
 
 	s.srv = gin.New()
 
 	// register middleware that is always used
 	s.srv.Use(gin.Logger())
 
+	if s.metrics != nil {
+		// Metrics middleware needs to be BEFORE MaxConns so that time spent waiting for a connection slot is included
+		s.srv.Use(middleware.Metrics(s.metrics))
+	}
+
 	if s.connLimit > 0 {
 		// MaxConns middleware needs to be BEFORE the recovery handler so that crashes do not mess up the conn count
-		s.srv.Use(middleware.MaxConns(s.connLimit))
+		s.srv.Use(middleware.MaxConns(s.connLimit, s.metrics))
 	}
 
 	s.srv.Use(middleware.Recovery(s.recoveryHandler))
-	s.srv.Use(middleware.BackgroundTasks(s.backgroundWg))
+	s.srv.Use(middleware.BackgroundTasks(s.backgroundWg, s.metrics))
+
+	if s.clientCAFile != "" {
+		s.srv.Use(middleware.ClientCertAuth(s.clientCertMapper, s.clientAuth == tls.RequireAndVerifyClientCert))
+	}
+
+	if s.compression != nil {
+		s.srv.Use(middleware.Compress(*s.compression))
+	}
+
+	if s.decompress {
+		// Decompress needs to be BEFORE BodyLimit so that the limit applies to the decompressed body
+		s.srv.Use(middleware.Decompress())
+	}
+
+	// always registered so that actions can set a limit
+	s.srv.Use(middleware.BodyLimit(s.maxBodySize))
 
 	if s.telemId != "" {
-		s.srv.Use(otelgin.Middleware(s.telemId, otelgin.WithFilter(middleware.FilterTraces(s.noTraceEndpoints...))))
+		s.srv.Use(otelgin.Middleware(s.telemId, otelgin.WithFilter(middleware.FilterTraces(s.noTrace()...))))
 	}
 
 	// register middleware
@@ -114,14 +380,31 @@ func (s *Server) ListenAndServe(address string) error {
 		s.srv.Use(m)
 	}
 
-	// register the health check route
-	s.srv.GET(s.healthCheckRoute, HealthCheck())
-	// TODO: add /metrics handler
+	// register the health check routes. the checks are copied so they cannot change while being run
+	livenessChecks := maps.Clone(s.livenessChecks)
+
+	s.srv.GET(s.healthCheckRoute, LivenessCheck(livenessChecks, s.healthCheckTimeout))
+	s.srv.GET(s.livenessRoute, LivenessCheck(livenessChecks, s.healthCheckTimeout))
+	s.srv.GET(s.readinessRoute, ReadinessCheck(s.IsDraining, maps.Clone(s.readinessChecks), s.healthCheckTimeout))
+
+	if s.metrics != nil && s.metricsRoute != "" {
+		// register the metrics route
+		s.srv.GET(s.metricsRoute, middleware.MetricsHandler(s.metrics))
+	}
+
+	if s.openApiRoute != "" {
+		// register the api docs routes
+		openapi.RegisterRoutes(s.srv, s.openApiRoute, s.openApiConfig)
+	}
 
 	// register controllers
 	allCtrls := controllers.GetAllControllers()
 
 	for path, ctrls := range allCtrls {
+		if len(s.groups) > 0 && !slices.Contains(s.groups, path) {
+			continue
+		}
+
 		path = sanitizePath(path)
 
 		group := s.srv.Group(path)
@@ -136,59 +419,68 @@ func (s *Server) ListenAndServe(address string) error {
 		// register handlers for the group
 		registerHandlers(group, ctrls)
 	}
+}
 
-	// setup the http server
-	s.httpSrv = &http.Server{
-		Addr:    address,
-		Handler: s.srv,
-	}
-
-	go func() {
-		// blocks until server is stopped
-		if err := s.httpSrv.ListenAndServe(); err != nil {
-			fmt.Println(err)
-		}
-	}()
+// noTrace endpoints that are not traced. probes and metrics scrapes are never traced
+func (s *Server) noTrace() []string {
+	endpoints := append(slices.Clone(s.noTraceEndpoints), s.healthCheckRoute, s.livenessRoute, s.readinessRoute)
 
-	fmt.Printf("listening on %s\n", address)
+	if s.metricsRoute != "" {
+		endpoints = append(endpoints, s.metricsRoute)
+	}
 
-	return nil
+	return endpoints
 }
 
+// Stop gracefully stops the server. the server is drained for the drain timeout, then in-flight requests and
+// background tasks are given the shutdown timeout to finish
 func (s *Server) Stop() error {
 	if s.srv == nil {
 		return nil
 	}
 
-	done := make(chan error, 1)
-	tmr := time.NewTimer(20 * time.Second)
+	s.Drain()
 
-	go func() {
-		defer func() {
-			close(done)
-		}()
+	if s.drainTimeout > 0 && s.httpSrv != nil {
+		time.Sleep(s.drainTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	var sErr error
 
-		sErr := s.httpSrv.Shutdown(context.Background())
+	if s.httpSrv != nil {
+		sErr = s.httpSrv.Shutdown(ctx)
+	}
+
+	if s.stopCertReload != nil {
+		s.stopCertReload()
+		s.stopCertReload = nil
+	}
 
+	done := make(chan struct{})
+
+	go func() {
 		// wait for any background tasks to finish (like short link metrics)
 		s.backgroundWg.Wait()
-
-		done <- sErr
+		close(done)
 	}()
 
-	var err error
-
 	select {
-	case err = <-done:
-		// graceful stop returned (succeeded if err == nil)
+	case <-done:
 		// intentionally blank
-	case <-tmr.C:
-		// graceful stop timed out
+	case <-ctx.Done():
+		// graceful stop timed out. close any remaining connections
+		if s.httpSrv != nil {
+			_ = s.httpSrv.Close()
+		}
+
 		return fmt.Errorf("rest server shutdown timed out")
 	}
 
 	s.srv = nil
 	s.httpSrv = nil
 
-	return err
+	return sErr
 }
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CsrfHeader    = "X-CSRF-Token"
+	CsrfFormField = "csrf_token"
+)
+
+// CsrfToken returns the session's CSRF token, creating it if necessary. the token should be rendered into forms
+// (CsrfFormField) or read by javascript clients and sent back in the CsrfHeader header
+func CsrfToken(c *gin.Context) string {
+	s := Get(c)
+
+	if s == nil {
+		return ""
+	}
+
+	if s.CsrfToken == "" {
+		token, err := newId()
+
+		if err != nil {
+			return ""
+		}
+
+		s.CsrfToken = token
+		s.modified = true
+	}
+
+	return s.CsrfToken
+}
+
+// Csrf middleware that rejects unsafe requests (POST, PUT, PATCH, DELETE...) that do not include the session's
+// CSRF token. MUST be registered after the Sessions middleware
+func Csrf() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+
+		s := Get(c)
+
+		if s == nil || s.CsrfToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing csrf token"})
+			return
+		}
+
+		token := c.GetHeader(CsrfHeader)
+
+		if token == "" {
+			token = c.PostForm(CsrfFormField)
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.CsrfToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid csrf token"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,328 @@
+package session
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+)
+
+const (
+	ContextSessionKey = "session"
+
+	DefaultCookieName      = "sid"
+	DefaultIdleTimeout     = 30 * time.Minute
+	DefaultAbsoluteTimeout = 12 * time.Hour
+)
+
+type Config struct {
+	CookieName string
+	// Key encrypts the session id stored in the cookie. it MUST be the same for all instances of a service that
+	// share a Store
+	Key             *gcm256.Key
+	Store           Store
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	Path            string
+	Domain          string
+	// Insecure allows the cookie to be sent over plain http. only use this for local development
+	Insecure bool
+	SameSite http.SameSite
+}
+
+// Manager manages cookie based sessions for browser clients
+type Manager struct {
+	cfg Config
+}
+
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Key == nil || !cfg.Key.IsValid() {
+		return nil, fmt.Errorf("a valid session cookie encryption key is required")
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultCookieName
+	}
+
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+
+	if cfg.AbsoluteTimeout <= 0 {
+		cfg.AbsoluteTimeout = DefaultAbsoluteTimeout
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	return &Manager{cfg: cfg}, nil
+}
+
+// Sessions middleware that loads the request's session and saves it before the response is written
+//
+// when the session belongs to a logged-in user, the user's claims and roles are added to the context so that
+// controller actions can authorize the request
+func (m *Manager) Sessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, err := m.load(c)
+
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Set(ContextSessionKey, s)
+		setAuthContext(c, s)
+
+		sw := &sessionWriter{ResponseWriter: c.Writer}
+		sw.beforeWrite = func() {
+			if err := m.save(c, s); err != nil {
+				_ = c.Error(err)
+			}
+		}
+
+		c.Writer = sw
+
+		c.Next()
+
+		// save sessions for responses that have no body
+		sw.commit()
+	}
+}
+
+// Login starts an authenticated session for claims. the session id is regenerated to prevent session fixation
+// and a new CSRF token is issued
+func (m *Manager) Login(c *gin.Context, claims *auth.UserClaims) error {
+	s := Get(c)
+
+	if s == nil {
+		return fmt.Errorf("no session in context. is the Sessions middleware registered?")
+	}
+
+	if err := s.regenerate(); err != nil {
+		return err
+	}
+
+	csrf, err := newId()
+
+	if err != nil {
+		return err
+	}
+
+	s.Claims = claims
+	s.CsrfToken = csrf
+	s.modified = true
+
+	setAuthContext(c, s)
+
+	return nil
+}
+
+// Logout destroys the session
+func (m *Manager) Logout(c *gin.Context) {
+	if s := Get(c); s != nil {
+		s.Destroy()
+	}
+}
+
+// Regenerate gives the session a new id while keeping its data
+func (m *Manager) Regenerate(c *gin.Context) error {
+	s := Get(c)
+
+	if s == nil {
+		return fmt.Errorf("no session in context. is the Sessions middleware registered?")
+	}
+
+	return s.regenerate()
+}
+
+func (m *Manager) load(c *gin.Context) (*Session, error) {
+	cookie, cErr := c.Cookie(m.cfg.CookieName)
+
+	if cErr != nil || cookie == "" {
+		return newSession()
+	}
+
+	id, idErr := m.decodeId(cookie)
+
+	if idErr != nil {
+		// tampered with or encrypted with an old key
+		return newSession()
+	}
+
+	s, sErr := m.cfg.Store.Get(c, id)
+
+	if sErr != nil {
+		return newSession()
+	}
+
+	now := time.Now()
+
+	if s.expired(now, m.cfg.IdleTimeout, m.cfg.AbsoluteTimeout) {
+		_ = m.cfg.Store.Delete(c, id)
+
+		return newSession()
+	}
+
+	if now.Sub(s.LastAccess) > m.cfg.IdleTimeout/10 {
+		// avoid writing to the store on every request
+		s.LastAccess = now
+		s.modified = true
+	}
+
+	return s, nil
+}
+
+func (m *Manager) save(c *gin.Context, s *Session) error {
+	for _, id := range s.oldIds {
+		if err := m.cfg.Store.Delete(c, id); err != nil {
+			return err
+		}
+	}
+
+	s.oldIds = nil
+
+	if s.destroyed {
+		if !s.isNew {
+			if err := m.cfg.Store.Delete(c, s.Id); err != nil {
+				return err
+			}
+		}
+
+		m.setCookie(c, "", -1)
+
+		return nil
+	}
+
+	if !s.modified {
+		return nil
+	}
+
+	remaining := m.cfg.AbsoluteTimeout - time.Since(s.Created)
+	ttl := min(m.cfg.IdleTimeout, remaining)
+
+	if err := m.cfg.Store.Save(c, s, ttl); err != nil {
+		return err
+	}
+
+	value, vErr := m.encodeId(s.Id)
+
+	if vErr != nil {
+		return vErr
+	}
+
+	m.setCookie(c, value, int(remaining/time.Second))
+
+	s.isNew = false
+	s.modified = false
+
+	return nil
+}
+
+func (m *Manager) setCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    value,
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   !m.cfg.Insecure,
+		HttpOnly: true,
+		SameSite: m.cfg.SameSite,
+	})
+}
+
+func (m *Manager) encodeId(id string) (string, error) {
+	enc, err := m.cfg.Key.Encrypt([]byte(id))
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(enc), nil
+}
+
+func (m *Manager) decodeId(value string) (string, error) {
+	enc, eErr := base64.RawURLEncoding.DecodeString(value)
+
+	if eErr != nil {
+		return "", eErr
+	}
+
+	id, err := m.cfg.Key.Decrypt(enc)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(id), nil
+}
+
+// Get returns the request's session or nil if the Sessions middleware is not registered
+func Get(c *gin.Context) *Session {
+	s, ok := c.Value(ContextSessionKey).(*Session)
+
+	if !ok {
+		return nil
+	}
+
+	return s
+}
+
+func setAuthContext(c *gin.Context, s *Session) {
+	if s.Claims == nil || s.Claims.Expired() {
+		return
+	}
+
+	c.Set(auth.JwtContextKey, s.Claims)
+	c.Set(auth.RoleContextKey, auth.NewRoleFromString(s.Claims.Roles...))
+}
+
+// sessionWriter saves the session before the response headers are written so the session cookie can be set
+type sessionWriter struct {
+	gin.ResponseWriter
+	beforeWrite func()
+	committed   bool
+}
+
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+
+	w.committed = true
+	w.beforeWrite()
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
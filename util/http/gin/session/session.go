@@ -0,0 +1,124 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/auth"
+)
+
+const (
+	idSize = 32
+)
+
+// Session server-side session data. only the encrypted session id is stored in the cookie
+type Session struct {
+	Id         string           `json:"id"`
+	Values     map[string]any   `json:"values,omitempty"`
+	Claims     *auth.UserClaims `json:"claims,omitempty"`
+	CsrfToken  string           `json:"csrfToken,omitempty"`
+	Created    time.Time        `json:"created"`
+	LastAccess time.Time        `json:"lastAccess"`
+
+	isNew     bool
+	modified  bool
+	destroyed bool
+	oldIds    []string
+}
+
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.Values[key]
+
+	return v, ok
+}
+
+func (s *Session) Set(key string, value any) {
+	if s.Values == nil {
+		s.Values = map[string]any{}
+	}
+
+	s.Values[key] = value
+	s.modified = true
+}
+
+func (s *Session) Delete(key string) {
+	if _, ok := s.Values[key]; !ok {
+		return
+	}
+
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// IsAuthenticated returns true if a user has logged in with this session
+func (s *Session) IsAuthenticated() bool {
+	return s.Claims != nil
+}
+
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Destroy removes the session from the store and clears the session cookie when the response is written
+func (s *Session) Destroy() {
+	s.destroyed = true
+}
+
+// regenerate gives the session a new id. the old id is removed from the store when the response is written
+func (s *Session) regenerate() error {
+	id, err := newId()
+
+	if err != nil {
+		return err
+	}
+
+	if !s.isNew {
+		s.oldIds = append(s.oldIds, s.Id)
+	}
+
+	s.Id = id
+	s.modified = true
+
+	return nil
+}
+
+func (s *Session) expired(now time.Time, idle time.Duration, absolute time.Duration) bool {
+	if idle > 0 && now.Sub(s.LastAccess) > idle {
+		return true
+	}
+
+	if absolute > 0 && now.Sub(s.Created) > absolute {
+		return true
+	}
+
+	return false
+}
+
+func newSession() (*Session, error) {
+	id, err := newId()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &Session{
+		Id:         id,
+		Values:     map[string]any{},
+		Created:    now,
+		LastAccess: now,
+		isNew:      true,
+	}, nil
+}
+
+func newId() (string, error) {
+	b := make([]byte, idSize)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
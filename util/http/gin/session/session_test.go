@@ -0,0 +1,196 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smoxy-io/goSDK/util/auth"
+	"github.com/smoxy-io/goSDK/util/crypto/aes/gcm256"
+	"github.com/smoxy-io/goSDK/util/errors"
+)
+
+func newTestRouter(t *testing.T, store Store) (*gin.Engine, *Manager) {
+	gin.SetMode(gin.TestMode)
+
+	m, err := NewManager(Config{Key: gcm256.NewKey(nil), Store: store, IdleTimeout: time.Minute})
+
+	if err != nil {
+		t.Fatalf("error creating session manager: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(m.Sessions(), Csrf())
+
+	r.GET("/csrf", func(c *gin.Context) {
+		c.String(http.StatusOK, CsrfToken(c))
+	})
+
+	r.POST("/login", func(c *gin.Context) {
+		if err := m.Login(c, &auth.UserClaims{UserId: "user-1", Roles: []string{"admin"}, Expires: time.Now().Add(time.Hour).Unix()}); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.String(http.StatusOK, CsrfToken(c))
+	})
+
+	r.POST("/logout", func(c *gin.Context) {
+		m.Logout(c)
+		c.Status(http.StatusNoContent)
+	})
+
+	r.GET("/me", func(c *gin.Context) {
+		if !auth.GetRolesFromCtx(c).Has(auth.RoleAdmin) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.String(http.StatusOK, auth.GetUserIdFromCtx(c))
+	})
+
+	return r, m
+}
+
+func doRequest(r *gin.Engine, method string, path string, cookie *http.Cookie, csrf string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	if csrf != "" {
+		req.Header.Set(CsrfHeader, csrf)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == DefaultCookieName {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func Test_Manager_LoginLogout(t *testing.T) {
+	store := NewMemoryStore()
+	r, m := newTestRouter(t, store)
+
+	w := doRequest(r, http.MethodGet, "/csrf", nil, "")
+	anonCookie := sessionCookie(w)
+
+	if anonCookie == nil || w.Body.String() == "" {
+		t.Fatalf("expected a session cookie and csrf token")
+	}
+
+	if !anonCookie.HttpOnly || !anonCookie.Secure {
+		t.Errorf("session cookie must be HttpOnly and Secure")
+	}
+
+	// unsafe requests require the csrf token
+	if w2 := doRequest(r, http.MethodPost, "/login", anonCookie, ""); w2.Code != http.StatusForbidden {
+		t.Errorf("login without csrf token returned %d, expected %d", w2.Code, http.StatusForbidden)
+	}
+
+	w = doRequest(r, http.MethodPost, "/login", anonCookie, w.Body.String())
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("login returned %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	userCookie := sessionCookie(w)
+	csrf := w.Body.String()
+
+	anonId, _ := m.decodeId(anonCookie.Value)
+	userId, _ := m.decodeId(userCookie.Value)
+
+	if anonId == "" || anonId == userId {
+		t.Errorf("session id was not regenerated on login")
+	}
+
+	if _, err := store.Get(context.Background(), anonId); err != errors.ErrNotFound {
+		t.Errorf("pre-login session was not removed from the store")
+	}
+
+	if w = doRequest(r, http.MethodGet, "/me", userCookie, ""); w.Code != http.StatusOK || w.Body.String() != "user-1" {
+		t.Errorf("authenticated request returned %d: %s", w.Code, w.Body.String())
+	}
+
+	// the pre-login session cannot be used to access the logged-in session
+	if w = doRequest(r, http.MethodGet, "/me", anonCookie, ""); w.Code != http.StatusForbidden {
+		t.Errorf("request with pre-login session returned %d, expected %d", w.Code, http.StatusForbidden)
+	}
+
+	if w = doRequest(r, http.MethodPost, "/logout", userCookie, csrf); w.Code != http.StatusNoContent {
+		t.Errorf("logout returned %d, expected %d", w.Code, http.StatusNoContent)
+	}
+
+	if _, err := store.Get(context.Background(), userId); err != errors.ErrNotFound {
+		t.Errorf("session was not removed from the store on logout")
+	}
+
+	if w = doRequest(r, http.MethodGet, "/me", userCookie, ""); w.Code != http.StatusForbidden {
+		t.Errorf("request after logout returned %d, expected %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func Test_Manager_IdleTimeout(t *testing.T) {
+	store := NewMemoryStore()
+	r, m := newTestRouter(t, store)
+
+	w := doRequest(r, http.MethodGet, "/csrf", nil, "")
+	w = doRequest(r, http.MethodPost, "/login", sessionCookie(w), w.Body.String())
+	cookie := sessionCookie(w)
+	id, _ := m.decodeId(cookie.Value)
+
+	s, _ := store.Get(context.Background(), id)
+	s.LastAccess = time.Now().Add(-2 * time.Minute)
+	_ = store.Save(context.Background(), s, time.Minute)
+
+	if w = doRequest(r, http.MethodGet, "/me", cookie, ""); w.Code != http.StatusForbidden {
+		t.Errorf("request with idle session returned %d, expected %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func Test_FileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("error creating file store: %v", err)
+	}
+
+	s, _ := newSession()
+	s.Set("foo", "bar")
+
+	if err := store.Save(context.Background(), s, time.Minute); err != nil {
+		t.Fatalf("error saving session: %v", err)
+	}
+
+	loaded, lErr := store.Get(context.Background(), s.Id)
+
+	if lErr != nil {
+		t.Fatalf("error loading session: %v", lErr)
+	}
+
+	if v, _ := loaded.Get("foo"); v != "bar" {
+		t.Errorf("loaded session value = %v, expected bar", v)
+	}
+
+	if err := store.Delete(context.Background(), s.Id); err != nil {
+		t.Errorf("error deleting session: %v", err)
+	}
+
+	if _, err := store.Get(context.Background(), s.Id); err != errors.ErrNotFound {
+		t.Errorf("expected %v after delete, got %v", errors.ErrNotFound, err)
+	}
+}
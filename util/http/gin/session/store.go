@@ -0,0 +1,226 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/smoxy-io/goSDK/util/errors"
+	"github.com/smoxy-io/goSDK/util/files"
+)
+
+// Store persists sessions on the server
+//
+// Get MUST return errors.ErrNotFound when the session does not exist or has expired. ttl is the time after which
+// the store may discard the session
+type Store interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	Save(ctx context.Context, s *Session, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+type storedSession struct {
+	Session *Session  `json:"session"`
+	Expires time.Time `json:"expires"`
+}
+
+// MemoryStore an in-memory Store. only suitable for single instance deployments
+type MemoryStore struct {
+	sessions map[string]storedSession
+	lock     *sync.RWMutex
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	m.lock.RLock()
+	ss, ok := m.sessions[id]
+	m.lock.RUnlock()
+
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	if time.Now().After(ss.Expires) {
+		_ = m.Delete(ctx, id)
+		return nil, errors.ErrNotFound
+	}
+
+	// copy the session so changes are only visible after it is saved
+	return cloneSession(ss.Session)
+}
+
+func (m *MemoryStore) Save(ctx context.Context, s *Session, ttl time.Duration) error {
+	c, err := cloneSession(s)
+
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.sessions[s.Id] = storedSession{Session: c, Expires: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.sessions, id)
+
+	return nil
+}
+
+// Cleanup removes expired sessions
+func (m *MemoryStore) Cleanup() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+
+	for id, ss := range m.sessions {
+		if now.After(ss.Expires) {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]storedSession{},
+		lock:     &sync.RWMutex{},
+	}
+}
+
+// FileStore a Store that keeps each session in a json file in a directory. the file name is a hash of the
+// session id so session ids are never written to disk
+type FileStore struct {
+	dir  string
+	lock *sync.RWMutex
+}
+
+func (f *FileStore) Get(ctx context.Context, id string) (*Session, error) {
+	f.lock.RLock()
+	content, rErr := files.Read(f.path(id))
+	f.lock.RUnlock()
+
+	if rErr != nil {
+		if os.IsNotExist(rErr) {
+			return nil, errors.ErrNotFound
+		}
+
+		return nil, rErr
+	}
+
+	var ss storedSession
+
+	if err := json.Unmarshal([]byte(content), &ss); err != nil {
+		return nil, err
+	}
+
+	if ss.Session == nil || ss.Session.Id != id || time.Now().After(ss.Expires) {
+		_ = f.Delete(ctx, id)
+		return nil, errors.ErrNotFound
+	}
+
+	return ss.Session, nil
+}
+
+func (f *FileStore) Save(ctx context.Context, s *Session, ttl time.Duration) error {
+	b, err := json.Marshal(storedSession{Session: s, Expires: time.Now().Add(ttl)})
+
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return files.Write(f.path(s.Id), string(b))
+}
+
+func (f *FileStore) Delete(ctx context.Context, id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := os.Remove(f.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Cleanup removes expired session files
+func (f *FileStore) Cleanup() error {
+	entries, err := os.ReadDir(f.dir)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+
+		p := filepath.Join(f.dir, e.Name())
+
+		content, rErr := files.Read(p)
+
+		if rErr != nil {
+			continue
+		}
+
+		var ss storedSession
+
+		if json.Unmarshal([]byte(content), &ss) != nil || now.After(ss.Expires) {
+			_ = os.Remove(p)
+		}
+	}
+
+	return nil
+}
+
+func (f *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := files.MakeDir(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:  dir,
+		lock: &sync.RWMutex{},
+	}, nil
+}
+
+func cloneSession(s *Session) (*Session, error) {
+	b, err := json.Marshal(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c Session
+
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
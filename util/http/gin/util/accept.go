@@ -0,0 +1,85 @@
+package util
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type acceptValue struct {
+	value string
+	q     float64
+}
+
+// ParseAccept parses an Accept or Accept-Encoding header into its values, most preferred first. values with
+// q=0 are not acceptable and are left out (see ParseAcceptRejected). parameters other than q are dropped
+func ParseAccept(header string) []string {
+	values := slices.DeleteFunc(parseAccept(header), func(v acceptValue) bool {
+		return v.q <= 0
+	})
+
+	// stable so that values with the same q keep the client's order
+	slices.SortStableFunc(values, func(a, b acceptValue) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+
+		return 0
+	})
+
+	out := make([]string, len(values))
+
+	for i, v := range values {
+		out[i] = v.value
+	}
+
+	return out
+}
+
+// ParseAcceptRejected returns the values of an Accept or Accept-Encoding header with q=0, e.g. the encodings a
+// wildcard must not expand to
+func ParseAcceptRejected(header string) []string {
+	out := make([]string, 0)
+
+	for _, v := range parseAccept(header) {
+		if v.q <= 0 {
+			out = append(out, v.value)
+		}
+	}
+
+	return out
+}
+
+func parseAccept(header string) []acceptValue {
+	values := make([]acceptValue, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+
+		for _, p := range params[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+
+			if strings.TrimSpace(k) != "q" {
+				continue
+			}
+
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+
+		values = append(values, acceptValue{value: value, q: q})
+	}
+
+	return values
+}
@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+var (
+	// DefaultBuckets histogram buckets (in seconds) suited to http request durations
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	DefaultRegistry = NewRegistry()
+)
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+//
+// metrics are created on first use: asking for a metric that already exists returns the existing metric, so
+// middleware can safely be registered more than once. asking for an existing metric with a different type or
+// labels panics
+type Registry struct {
+	metrics map[string]*metric
+	lock    *sync.RWMutex
+}
+
+func (r *Registry) Counter(name string, help string, labels ...string) *Counter {
+	return &Counter{m: r.getOrCreate(name, help, TypeCounter, labels, nil)}
+}
+
+func (r *Registry) Gauge(name string, help string, labels ...string) *Gauge {
+	return &Gauge{m: r.getOrCreate(name, help, TypeGauge, labels, nil)}
+}
+
+// GaugeFunc registers an unlabelled gauge whose value is read from fn when the metrics are written
+func (r *Registry) GaugeFunc(name string, help string, fn func() float64) {
+	m := r.getOrCreate(name, help, TypeGauge, nil, nil)
+
+	m.lock.Lock()
+	m.fn = fn
+	m.lock.Unlock()
+}
+
+// Histogram buckets are upper bounds and are sorted. DefaultBuckets is used when buckets is empty
+func (r *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Histogram{m: r.getOrCreate(name, help, TypeHistogram, labels, buckets)}
+}
+
+// Unregister removes a metric from the registry
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.metrics, name)
+}
+
+func (r *Registry) getOrCreate(name string, help string, typ string, labels []string, buckets []float64) *metric {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		if m.typ != typ || !slices.Equal(m.labels, labels) {
+			panic(fmt.Sprintf("metric %s already registered as %s with labels %v", name, m.typ, m.labels))
+		}
+
+		return m
+	}
+
+	m := &metric{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  slices.Clone(labels),
+		buckets: buckets,
+		series:  map[string]*series{},
+		lock:    &sync.Mutex{},
+	}
+
+	r.metrics[name] = m
+
+	return m
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: map[string]*metric{},
+		lock:    &sync.RWMutex{},
+	}
+}
+
+type Counter struct {
+	m *metric
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter. counters can only increase, negative values are ignored
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+
+	c.m.update(labelValues, func(s *series) { s.value += v })
+}
+
+type Gauge struct {
+	m *metric
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.m.update(labelValues, func(s *series) { s.value = v })
+}
+
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.m.update(labelValues, func(s *series) { s.value += v })
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+type Histogram struct {
+	m *metric
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.m.update(labelValues, func(s *series) {
+		s.sum += v
+		s.count++
+
+		for i, b := range h.m.buckets {
+			if v <= b {
+				s.counts[i]++
+			}
+		}
+	})
+}
+
+type metric struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+	fn      func() float64
+	series  map[string]*series
+	lock    *sync.Mutex
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// histograms only. counts are cumulative
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (m *metric) update(labelValues []string, fn func(s *series)) {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", m.name, len(m.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.series[key]
+
+	if !ok {
+		s = &series{labelValues: slices.Clone(labelValues)}
+
+		if m.typ == TypeHistogram {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+
+		m.series[key] = s
+	}
+
+	fn(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
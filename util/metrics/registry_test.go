@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Registry_WriteText(t *testing.T) {
+	reg := NewRegistry()
+
+	c := reg.Counter("requests_total", "Total requests.", "method", "code")
+	c.Inc("GET", "200")
+	c.Add(2, "GET", "200")
+	c.Inc("POST", "500")
+
+	g := reg.Gauge("in_flight", "In flight\nrequests.")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+
+	h := reg.Histogram("duration_seconds", "", []float64{1, 0.1}, "path")
+	h.Observe(0.05, `/a"b`)
+	h.Observe(0.5, `/a"b`)
+	h.Observe(5, `/a"b`)
+
+	reg.GaugeFunc("answer", "The answer.", func() float64 { return 42 })
+
+	// asking for an existing metric returns it
+	reg.Counter("requests_total", "Total requests.", "method", "code").Inc("GET", "200")
+
+	buf := bytes.Buffer{}
+
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("error writing metrics: %v", err)
+	}
+
+	expected := `# HELP answer The answer.
+# TYPE answer gauge
+answer 42
+# TYPE duration_seconds histogram
+duration_seconds_bucket{path="/a\"b",le="0.1"} 1
+duration_seconds_bucket{path="/a\"b",le="1"} 2
+duration_seconds_bucket{path="/a\"b",le="+Inf"} 3
+duration_seconds_sum{path="/a\"b"} 5.55
+duration_seconds_count{path="/a\"b"} 3
+# HELP in_flight In flight\nrequests.
+# TYPE in_flight gauge
+in_flight 1
+# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{method="GET",code="200"} 4
+requests_total{method="POST",code="500"} 1
+`
+
+	if buf.String() != expected {
+		t.Errorf("unexpected metrics output\nexpected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func Test_Registry_Conflicts(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("foo", "", "a")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic registering an existing metric with a different type")
+		}
+	}()
+
+	reg.Gauge("foo", "", "a")
+}
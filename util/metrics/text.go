@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ContentType of the Prometheus text exposition format written by WriteText
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes all metrics in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lock.RLock()
+
+	names := make([]string, 0, len(r.metrics))
+
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+
+	ms := make([]*metric, 0, len(names))
+	slices.Sort(names)
+
+	for _, name := range names {
+		ms = append(ms, r.metrics[name])
+	}
+
+	r.lock.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, m := range ms {
+		m.writeText(bw)
+	}
+
+	return bw.Flush()
+}
+
+func (m *metric) writeText(w *bufio.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.help != "" {
+		w.WriteString("# HELP " + m.name + " " + escapeHelp(m.help) + "\n")
+	}
+
+	w.WriteString("# TYPE " + m.name + " " + m.typ + "\n")
+
+	if m.fn != nil {
+		w.WriteString(m.name + " " + formatValue(m.fn()) + "\n")
+		return
+	}
+
+	keys := make([]string, 0, len(m.series))
+
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		s := m.series[k]
+
+		if m.typ != TypeHistogram {
+			w.WriteString(m.name + formatLabels(m.labels, s.labelValues, "", "") + " " + formatValue(s.value) + "\n")
+			continue
+		}
+
+		for i, b := range m.buckets {
+			w.WriteString(m.name + "_bucket" + formatLabels(m.labels, s.labelValues, "le", formatValue(b)) + " " + strconv.FormatUint(s.counts[i], 10) + "\n")
+		}
+
+		w.WriteString(m.name + "_bucket" + formatLabels(m.labels, s.labelValues, "le", "+Inf") + " " + strconv.FormatUint(s.count, 10) + "\n")
+		w.WriteString(m.name + "_sum" + formatLabels(m.labels, s.labelValues, "", "") + " " + formatValue(s.sum) + "\n")
+		w.WriteString(m.name + "_count" + formatLabels(m.labels, s.labelValues, "", "") + " " + strconv.FormatUint(s.count, 10) + "\n")
+	}
+}
+
+func formatLabels(labels []string, values []string, extraLabel string, extraValue string) string {
+	if len(labels) == 0 && extraLabel == "" {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)+1)
+
+	for i, l := range labels {
+		pairs = append(pairs, l+`="`+escapeLabelValue(values[i])+`"`)
+	}
+
+	if extraLabel != "" {
+		pairs = append(pairs, extraLabel+`="`+escapeLabelValue(extraValue)+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
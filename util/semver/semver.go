@@ -0,0 +1,223 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version a semantic version (https://semver.org). the leading v is optional
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+	Build      string
+}
+
+// String returns the version with a leading v, e.g. v1.2.3-rc.1
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+
+	return s
+}
+
+// Compare returns -1 when v is lower than o, 1 when v is higher than o and 0 when they have the same precedence. build
+// metadata is ignored
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0
+}
+
+// Parse parses a semantic version, e.g. v1.2.3, 1.2.3-rc.1 or v1.2.3+build.5
+func Parse(s string) (Version, error) {
+	v := Version{}
+	rest := strings.TrimPrefix(s, "v")
+
+	if i := strings.Index(rest, "+"); i >= 0 {
+		v.Build = rest[i+1:]
+		rest = rest[:i]
+
+		if !validIdentifiers(v.Build, false) {
+			return Version{}, fmt.Errorf("invalid semantic version %q: invalid build metadata", s)
+		}
+	}
+
+	if i := strings.Index(rest, "-"); i >= 0 {
+		v.Prerelease = rest[i+1:]
+		rest = rest[:i]
+
+		if !validIdentifiers(v.Prerelease, true) {
+			return Version{}, fmt.Errorf("invalid semantic version %q: invalid pre-release", s)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid semantic version %q: expected major.minor.patch", s)
+	}
+
+	nums := make([]uint64, 3)
+
+	for i, p := range parts {
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return Version{}, fmt.Errorf("invalid semantic version %q: invalid number %q", s, p)
+		}
+
+		n, err := strconv.ParseUint(p, 10, 64)
+
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semantic version %q: %w", s, err)
+		}
+
+		nums[i] = n
+	}
+
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// MustParse is like Parse but panics when s is not a semantic version
+func MustParse(s string) Version {
+	v, err := Parse(s)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// Compare compares two version strings (see Version.Compare)
+func Compare(a string, b string) (int, error) {
+	va, aErr := Parse(a)
+
+	if aErr != nil {
+		return 0, aErr
+	}
+
+	vb, bErr := Parse(b)
+
+	if bErr != nil {
+		return 0, bErr
+	}
+
+	return va.Compare(vb), nil
+}
+
+func compareUint(a uint64, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// comparePrerelease a version without a pre-release has a higher precedence than one with a pre-release
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	ap := strings.Split(a, ".")
+	bp := strings.Split(b, ".")
+
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		if c := compareIdentifier(ap[i], bp[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareUint(uint64(len(ap)), uint64(len(bp)))
+}
+
+// compareIdentifier numeric identifiers are compared numerically and have a lower precedence than alphanumeric ones
+func compareIdentifier(a string, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+
+	switch {
+	case an && bn:
+		if len(a) != len(b) {
+			return compareUint(uint64(len(a)), uint64(len(b)))
+		}
+
+		return strings.Compare(a, b)
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func validIdentifiers(s string, noLeadingZeros bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return false
+			}
+		}
+
+		if noLeadingZeros && isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		expect  string
+		invalid bool
+	}{
+		{in: "v1.2.3", expect: "v1.2.3"},
+		{in: "1.2.3", expect: "v1.2.3"},
+		{in: "v0.0.0", expect: "v0.0.0"},
+		{in: "v1.2.3-rc.1", expect: "v1.2.3-rc.1"},
+		{in: "v1.2.3-rc.1+build.5", expect: "v1.2.3-rc.1+build.5"},
+		{in: "v1.2.3+20240101", expect: "v1.2.3+20240101"},
+		{in: "v1.2", invalid: true},
+		{in: "v1.2.3.4", invalid: true},
+		{in: "v01.2.3", invalid: true},
+		{in: "v1.x.3", invalid: true},
+		{in: "v1.2.3-", invalid: true},
+		{in: "v1.2.3-rc..1", invalid: true},
+		{in: "v1.2.3-01", invalid: true},
+		{in: "latest", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := Parse(tt.in)
+
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("Parse(%q) = %s, expected an error", tt.in, v)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q) returned an error: %v", tt.in, err)
+			}
+
+			if v.String() != tt.expect {
+				t.Errorf("Parse(%q) = %s, expected %s", tt.in, v, tt.expect)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// in order of precedence (semver.org example)
+	ordered := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.1.0",
+		"v1.10.0",
+		"v2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			expect := compareUint(uint64(i), uint64(j))
+
+			if c, err := Compare(ordered[i], ordered[j]); err != nil || c != expect {
+				t.Errorf("Compare(%s, %s) = %d (%v), expected %d", ordered[i], ordered[j], c, err, expect)
+			}
+		}
+	}
+
+	if !MustParse("v1.0.0+a").Equal(MustParse("1.0.0+b")) {
+		t.Errorf("build metadata should be ignored")
+	}
+}